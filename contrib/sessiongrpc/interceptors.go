@@ -0,0 +1,216 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package sessiongrpc verifies SuperTokens sessions in gRPC servers. The access token is read
+// from the "authorization" metadata of the call (as sent with the header transfer method), and
+// the verified session is attached to the context of the call:
+//
+//	server := grpc.NewServer(
+//		grpc.UnaryInterceptor(sessiongrpc.UnaryServerInterceptor(nil)),
+//		grpc.StreamInterceptor(sessiongrpc.StreamServerInterceptor(nil)),
+//	)
+//
+//	func (s *server) GetProfile(ctx context.Context, req *pb.GetProfileRequest) (*pb.Profile, error) {
+//		sessionContainer := sessiongrpc.GetSessionFromContext(ctx)
+//		...
+//	}
+//
+// It lives in its own module so that the SDK itself does not depend on gRPC.
+package sessiongrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the errdetails.ErrorInfo attached to the status of failed calls.
+// Its reason is one of "UNAUTHORISED", "TRY_REFRESH_TOKEN" or "INVALID_CLAIMS".
+const ErrorDomain = "supertokens.com"
+
+const (
+	clearTokensMetadataKey      = "clearTokens"
+	logoutReasonMetadataKey     = "logoutReason"
+	invalidClaimsMetadataKey    = "invalidClaims"
+	defaultInternalErrorMessage = "could not verify the session"
+)
+
+type Config struct {
+	// VerifySessionOptions are used to verify the session of every call
+	VerifySessionOptions *sessmodels.VerifySessionOptions
+	// SkipMethod returns true for the full method names (like "/package.Service/Method") that do not need a session
+	SkipMethod func(fullMethod string) bool
+}
+
+func UnaryServerInterceptor(config *Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if shouldSkipMethod(config, info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, responseHeaders, err := verifySession(ctx, config, info.FullMethod)
+		if len(responseHeaders) > 0 {
+			if headerErr := grpc.SetHeader(ctx, responseHeaders); headerErr != nil {
+				supertokens.LogDebugMessage("sessiongrpc: could not set response headers: " + headerErr.Error())
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(config *Config) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if shouldSkipMethod(config, info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, responseHeaders, err := verifySession(ss.Context(), config, info.FullMethod)
+		if len(responseHeaders) > 0 {
+			if headerErr := ss.SetHeader(responseHeaders); headerErr != nil {
+				supertokens.LogDebugMessage("sessiongrpc: could not set response headers: " + headerErr.Error())
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStreamWithContext{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// GetSessionFromContext returns the session verified by the interceptors, or nil if there is none
+func GetSessionFromContext(ctx context.Context) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(ctx)
+}
+
+type serverStreamWithContext struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStreamWithContext) Context() context.Context {
+	return s.ctx
+}
+
+func shouldSkipMethod(config *Config, fullMethod string) bool {
+	return config != nil && config.SkipMethod != nil && config.SkipMethod(fullMethod)
+}
+
+// verifySession returns the context with the session attached to it, and the headers
+// set by the SDK while verifying the session, which are sent back as metadata.
+func verifySession(ctx context.Context, config *Config, fullMethod string) (context.Context, metadata.MD, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullMethod, nil)
+	if err != nil {
+		return ctx, nil, status.Error(codes.Internal, defaultInternalErrorMessage)
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if strings.HasPrefix(key, ":") {
+			continue
+		}
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	res := &headerRecorder{header: http.Header{}}
+
+	var verifySessionOptions *sessmodels.VerifySessionOptions = nil
+	if config != nil {
+		verifySessionOptions = config.VerifySessionOptions
+	}
+	sessionContainer, err := session.GetSessionWithContext(req, res, verifySessionOptions, supertokens.MakeDefaultUserContextFromAPI(req))
+	responseHeaders := metadata.MD{}
+	for key, values := range res.header {
+		responseHeaders.Append(key, values...)
+	}
+	if err != nil {
+		return ctx, responseHeaders, getStatusFromError(err)
+	}
+	if sessionContainer != nil {
+		ctx = context.WithValue(ctx, sessmodels.SessionContext, sessionContainer)
+	}
+	return ctx, responseHeaders, nil
+}
+
+func getStatusFromError(err error) error {
+	var result *status.Status
+	var tryRefreshTokenError sessionErrors.TryRefreshTokenError
+	var unauthorizedError sessionErrors.UnauthorizedError
+	var invalidClaimError sessionErrors.InvalidClaimError
+	if errors.As(err, &tryRefreshTokenError) {
+		result = withErrorInfo(status.New(codes.Unauthenticated, tryRefreshTokenError.Msg), sessionErrors.TryRefreshTokenErrorStr, nil)
+	} else if errors.As(err, &unauthorizedError) {
+		clearTokens := unauthorizedError.ClearTokens == nil || *unauthorizedError.ClearTokens
+		errorMetadata := map[string]string{
+			clearTokensMetadataKey: strconv.FormatBool(clearTokens),
+		}
+		if unauthorizedError.Reason != nil {
+			errorMetadata[logoutReasonMetadataKey] = *unauthorizedError.Reason
+		}
+		result = withErrorInfo(status.New(codes.Unauthenticated, unauthorizedError.Msg), sessionErrors.UnauthorizedErrorStr, errorMetadata)
+	} else if errors.As(err, &invalidClaimError) {
+		invalidClaims, jsonErr := json.Marshal(invalidClaimError.InvalidClaims)
+		if jsonErr != nil {
+			return status.Error(codes.Internal, defaultInternalErrorMessage)
+		}
+		result = withErrorInfo(status.New(codes.PermissionDenied, invalidClaimError.Msg), sessionErrors.InvalidClaimsErrorStr, map[string]string{
+			invalidClaimsMetadataKey: string(invalidClaims),
+		})
+	} else {
+		supertokens.LogErrorMessage("sessiongrpc: could not verify the session", supertokens.RecipeIDLogField(session.RECIPE_ID), supertokens.ErrorLogField(err))
+		return status.Error(codes.Internal, defaultInternalErrorMessage)
+	}
+	return result.Err()
+}
+
+func withErrorInfo(s *status.Status, reason string, errorMetadata map[string]string) *status.Status {
+	withDetails, err := s.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: errorMetadata,
+	})
+	if err != nil {
+		return s
+	}
+	return withDetails
+}
+
+// headerRecorder collects the headers the SDK sets while verifying the session
+type headerRecorder struct {
+	header http.Header
+}
+
+func (r *headerRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *headerRecorder) Write(body []byte) (int, error) {
+	return len(body), nil
+}
+
+func (r *headerRecorder) WriteHeader(statusCode int) {}
@@ -0,0 +1,176 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package sessiongrpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func initForTest(t *testing.T, core *fakecore.Server) {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{ConnectionURI: core.URL},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(&sessmodels.TypeInput{
+				GetTokenTransferMethod: func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+					return sessmodels.HeaderTransferMethod
+				},
+			}),
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func resetForTest() {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+}
+
+func createAccessTokenForTest(t *testing.T, userID string) string {
+	res := httptest.NewRecorder()
+	_, err := session.CreateNewSession(httptest.NewRequest(http.MethodPost, "/", nil), res, userID, nil, nil)
+	assert.NoError(t, err)
+	return res.Header().Get("st-access-token")
+}
+
+func callUnaryInterceptorForTest(config *Config, md metadata.MD) (sessmodels.SessionContainer, error) {
+	var sessionContainer sessmodels.SessionContainer
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	_, err := UnaryServerInterceptor(config)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		sessionContainer = GetSessionFromContext(ctx)
+		return nil, nil
+	})
+	return sessionContainer, err
+}
+
+func getErrorInfoForTest(t *testing.T, err error) *errdetails.ErrorInfo {
+	for _, detail := range status.Convert(err).Details() {
+		if errorInfo, ok := detail.(*errdetails.ErrorInfo); ok {
+			return errorInfo
+		}
+	}
+	t.Fatal("no ErrorInfo in status")
+	return nil
+}
+
+func TestUnaryInterceptorAttachesSession(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core)
+	defer resetForTest()
+
+	accessToken := createAccessTokenForTest(t, "user1")
+	sessionContainer, err := callUnaryInterceptorForTest(nil, metadata.Pairs("authorization", "Bearer "+accessToken))
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", sessionContainer.GetUserID())
+}
+
+func TestUnaryInterceptorMapsSessionErrorsToStatusCodes(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core)
+	defer resetForTest()
+
+	_, err := callUnaryInterceptorForTest(nil, metadata.MD{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	errorInfo := getErrorInfoForTest(t, err)
+	assert.Equal(t, "UNAUTHORISED", errorInfo.Reason)
+	assert.Equal(t, ErrorDomain, errorInfo.Domain)
+
+	accessToken := createAccessTokenForTest(t, "user1")
+	_, err = callUnaryInterceptorForTest(nil, metadata.Pairs("authorization", "Bearer "+accessToken+"invalid"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "TRY_REFRESH_TOKEN", getErrorInfoForTest(t, err).Reason)
+
+	_, err = callUnaryInterceptorForTest(&Config{
+		VerifySessionOptions: &sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				return []claims.SessionClaimValidator{{
+					ID: "test-claim",
+					Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) claims.ClaimValidationResult {
+						return claims.ClaimValidationResult{IsValid: false, Reason: "wrong value"}
+					},
+				}}, nil
+			},
+		},
+	}, metadata.Pairs("authorization", "Bearer "+accessToken))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	errorInfo = getErrorInfoForTest(t, err)
+	assert.Equal(t, "INVALID_CLAIMS", errorInfo.Reason)
+	assert.JSONEq(t, `[{"id":"test-claim","reason":"wrong value"}]`, errorInfo.Metadata["invalidClaims"])
+}
+
+func TestUnaryInterceptorSkipsMethods(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core)
+	defer resetForTest()
+
+	sessionContainer, err := callUnaryInterceptorForTest(&Config{
+		SkipMethod: func(fullMethod string) bool {
+			return fullMethod == "/test.Service/Method"
+		},
+	}, metadata.MD{})
+	assert.NoError(t, err)
+	assert.Nil(t, sessionContainer)
+}
+
+type serverStreamForTest struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStreamForTest) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamInterceptorAttachesSession(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core)
+	defer resetForTest()
+
+	accessToken := createAccessTokenForTest(t, "user1")
+	stream := &serverStreamForTest{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+accessToken))}
+	var sessionContainer sessmodels.SessionContainer
+	err := StreamServerInterceptor(nil)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream"}, func(srv interface{}, stream grpc.ServerStream) error {
+		sessionContainer = GetSessionFromContext(stream.Context())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", sessionContainer.GetUserID())
+}
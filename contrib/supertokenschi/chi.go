@@ -0,0 +1,57 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package supertokenschi integrates SuperTokens with chi. Middleware serves the SuperTokens APIs,
+// VerifySession checks the session of the routes it is added to, and GetSession returns it:
+//
+//	r := chi.NewRouter()
+//	r.Use(supertokenschi.Middleware)
+//	r.With(supertokenschi.VerifySession(nil)).Get("/profile", func(w http.ResponseWriter, r *http.Request) {
+//		sessionContainer := supertokenschi.GetSession(r)
+//		...
+//	})
+//
+// Since chi handlers do not return errors, the errors that SuperTokens cannot turn into a response
+// themselves are passed to supertokens.TypeInput.OnSuperTokensAPIError, as with plain net/http.
+//
+// It lives in its own module so that the SDK itself does not depend on chi.
+package supertokenschi
+
+import (
+	"net/http"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Middleware serves the APIs exposed by the SuperTokens recipes, and passes the other requests to next
+func Middleware(next http.Handler) http.Handler {
+	return supertokens.Middleware(next)
+}
+
+// VerifySession returns a middleware that sends an error response if the request does not have a valid
+// session (unless options.SessionRequired is false), and otherwise attaches the session to the request
+// so that GetSession can return it
+func VerifySession(options *sessmodels.VerifySessionOptions) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return session.VerifySession(options, next.ServeHTTP)
+	}
+}
+
+// GetSession returns the session verified by VerifySession, or nil if there is none
+func GetSession(r *http.Request) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(r.Context())
+}
@@ -0,0 +1,126 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokenschi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func initForTest(t *testing.T, core *fakecore.Server, getSessionErr error) {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{ConnectionURI: core.URL},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(&sessmodels.TypeInput{
+				GetTokenTransferMethod: func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+					return sessmodels.HeaderTransferMethod
+				},
+				Override: &sessmodels.OverrideStruct{
+					Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+						originalGetSession := *originalImplementation.GetSession
+						*originalImplementation.GetSession = func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+							if getSessionErr != nil {
+								return nil, getSessionErr
+							}
+							return originalGetSession(req, res, options, userContext)
+						}
+						return originalImplementation
+					},
+				},
+			}),
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func resetForTest() {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+}
+
+func createAccessTokenForTest(t *testing.T, userID string) string {
+	res := httptest.NewRecorder()
+	_, err := session.CreateNewSession(httptest.NewRequest(http.MethodPost, "/", nil), res, userID, nil, nil)
+	assert.NoError(t, err)
+	return res.Header().Get("st-access-token")
+}
+
+func makeRouterForTest() *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(Middleware)
+	r.With(VerifySession(nil)).Get("/profile", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(GetSession(r).GetUserID()))
+	})
+	return r
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+
+	res := httptest.NewRecorder()
+	makeRouterForTest().ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil))
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestVerifySessionAttachesSessionToRequest(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+	r := makeRouterForTest()
+
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+createAccessTokenForTest(t, "userId"))
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "userId", res.Body.String())
+
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/profile", nil))
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestVerifySessionPassesUnhandledErrorsToOnSuperTokensAPIError(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, errors.New("test error"))
+	defer resetForTest()
+
+	res := httptest.NewRecorder()
+	makeRouterForTest().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/profile", nil))
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, "test error\n", res.Body.String())
+}
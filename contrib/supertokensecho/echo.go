@@ -0,0 +1,93 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package supertokensecho integrates SuperTokens with echo. Middleware serves the SuperTokens APIs,
+// VerifySession checks the session of the routes it is added to, and GetSession returns it:
+//
+//	e := echo.New()
+//	e.Use(supertokensecho.Middleware())
+//	e.GET("/profile", func(c echo.Context) error {
+//		sessionContainer := supertokensecho.GetSession(c)
+//		...
+//	}, supertokensecho.VerifySession(nil))
+//
+// Errors that SuperTokens cannot turn into a response themselves are returned by the middlewares,
+// so they are handled by echo's HTTPErrorHandler instead of supertokens.TypeInput.OnSuperTokensAPIError.
+//
+// It lives in its own module so that the SDK itself does not depend on echo.
+package supertokensecho
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Middleware serves the APIs exposed by the SuperTokens recipes, and passes the other requests to the next handler
+func Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var nextErr error
+			handler := supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				nextErr = next(c)
+			}))
+			if err := serveHTTP(c, handler); err != nil {
+				return err
+			}
+			return nextErr
+		}
+	}
+}
+
+// VerifySession returns an error response if the request does not have a valid session (unless
+// options.SessionRequired is false), and otherwise attaches the session to the request so that
+// GetSession can return it
+func VerifySession(options *sessmodels.VerifySessionOptions) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var nextErr error
+			handler := session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
+				sessionContainer := session.GetSessionFromRequestContext(r.Context())
+				if sessionContainer != nil {
+					c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), sessmodels.SessionContext, sessionContainer)))
+				}
+				nextErr = next(c)
+			})
+			if err := serveHTTP(c, handler); err != nil {
+				return err
+			}
+			return nextErr
+		}
+	}
+}
+
+// GetSession returns the session verified by VerifySession, or nil if there is none
+func GetSession(c echo.Context) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(c.Request().Context())
+}
+
+// serveHTTP runs handler, and returns the error it could not handle, if any
+func serveHTTP(c echo.Context, handler http.Handler) error {
+	var apiErr error
+	ctx := supertokens.WithAPIErrorHandler(c.Request().Context(), func(err error, req *http.Request, res http.ResponseWriter) {
+		apiErr = err
+	})
+	handler.ServeHTTP(c.Response(), c.Request().WithContext(ctx))
+	return apiErr
+}
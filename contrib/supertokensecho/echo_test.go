@@ -0,0 +1,135 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokensecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func initForTest(t *testing.T, core *fakecore.Server, getSessionErr error) {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{ConnectionURI: core.URL},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(&sessmodels.TypeInput{
+				GetTokenTransferMethod: func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+					return sessmodels.HeaderTransferMethod
+				},
+				Override: &sessmodels.OverrideStruct{
+					Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+						originalGetSession := *originalImplementation.GetSession
+						*originalImplementation.GetSession = func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+							if getSessionErr != nil {
+								return nil, getSessionErr
+							}
+							return originalGetSession(req, res, options, userContext)
+						}
+						return originalImplementation
+					},
+				},
+			}),
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func resetForTest() {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+}
+
+func createAccessTokenForTest(t *testing.T, userID string) string {
+	res := httptest.NewRecorder()
+	_, err := session.CreateNewSession(httptest.NewRequest(http.MethodPost, "/", nil), res, userID, nil, nil)
+	assert.NoError(t, err)
+	return res.Header().Get("st-access-token")
+}
+
+func makeServerForTest() (*echo.Echo, *[]error) {
+	errs := []error{}
+	e := echo.New()
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		errs = append(errs, err)
+		c.NoContent(http.StatusInternalServerError)
+	}
+	e.Use(Middleware())
+	e.GET("/profile", func(c echo.Context) error {
+		return c.String(http.StatusOK, GetSession(c).GetUserID())
+	}, VerifySession(nil))
+	return e, &errs
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+	e, _ := makeServerForTest()
+
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil))
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestVerifySessionAttachesSessionToContext(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+	e, errs := makeServerForTest()
+
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+createAccessTokenForTest(t, "userId"))
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, req)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "userId", res.Body.String())
+
+	res = httptest.NewRecorder()
+	e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/profile", nil))
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+	assert.Equal(t, 0, len(*errs))
+}
+
+func TestVerifySessionReturnsUnhandledErrorsToEcho(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, errors.New("test error"))
+	defer resetForTest()
+	e, errs := makeServerForTest()
+
+	res := httptest.NewRecorder()
+	e.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/profile", nil))
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, 1, len(*errs))
+	assert.EqualError(t, (*errs)[0], "test error")
+}
@@ -0,0 +1,135 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package supertokensfiber integrates SuperTokens with fiber. Middleware serves the SuperTokens APIs,
+// VerifySession checks the session of the routes it is added to, and GetSession returns it:
+//
+//	app := fiber.New()
+//	app.Use(supertokensfiber.Middleware())
+//	app.Get("/profile", supertokensfiber.VerifySession(nil), func(c *fiber.Ctx) error {
+//		sessionContainer := supertokensfiber.GetSession(c)
+//		...
+//	})
+//
+// The session is attached to c.UserContext(), so it can also be read with session.GetSessionFromRequestContext.
+// Errors that SuperTokens cannot turn into a response themselves are returned by the handlers, so
+// they are handled by fiber's ErrorHandler instead of supertokens.TypeInput.OnSuperTokensAPIError.
+//
+// It lives in its own module so that the SDK itself does not depend on fiber.
+package supertokensfiber
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// Middleware serves the APIs exposed by the SuperTokens recipes, and passes the other requests to the next handlers
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		calledNext := false
+		handler := supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+		}))
+		if _, err := serveHTTP(c, handler); err != nil {
+			return err
+		}
+		if calledNext {
+			return c.Next()
+		}
+		return nil
+	}
+}
+
+// VerifySession returns an error response if the request does not have a valid session (unless
+// options.SessionRequired is false), and otherwise attaches the session to c.UserContext() so that
+// GetSession can return it
+func VerifySession(options *sessmodels.VerifySessionOptions) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		calledNext := false
+		handler := session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			sessionContainer := session.GetSessionFromRequestContext(r.Context())
+			if sessionContainer != nil {
+				c.SetUserContext(context.WithValue(c.UserContext(), sessmodels.SessionContext, sessionContainer))
+			}
+		})
+		rw, err := serveHTTP(c, handler)
+		if err != nil || !calledNext {
+			return err
+		}
+		err = c.Next()
+		// the session writes the headers of the tokens it updates to rw, even after VerifySession is done
+		rw.copyHeaders()
+		return err
+	}
+}
+
+// GetSession returns the session verified by VerifySession, or nil if there is none
+func GetSession(c *fiber.Ctx) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(c.UserContext())
+}
+
+// serveHTTP runs handler on the request converted to net/http, and returns the error it could not handle, if any
+func serveHTTP(c *fiber.Ctx, handler http.Handler) (*responseWriter, error) {
+	var apiErr error
+	rw := &responseWriter{c: c, header: http.Header{}}
+	var req http.Request
+	err := fasthttpadaptor.ConvertRequest(c.Context(), &req, true)
+	if err != nil {
+		return rw, err
+	}
+	ctx := supertokens.WithAPIErrorHandler(c.UserContext(), func(err error, req *http.Request, res http.ResponseWriter) {
+		apiErr = err
+	})
+	handler.ServeHTTP(rw, req.WithContext(ctx))
+	rw.copyHeaders()
+	return rw, apiErr
+}
+
+// responseWriter writes to the fiber response. Its headers are copied to the fiber response when
+// copyHeaders is called, or when the body is written.
+type responseWriter struct {
+	c      *fiber.Ctx
+	header http.Header
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.copyHeaders()
+	w.c.Status(statusCode)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.copyHeaders()
+	return w.c.Write(p)
+}
+
+func (w *responseWriter) copyHeaders() {
+	for key, values := range w.header {
+		w.c.Response().Header.Del(key)
+		for _, value := range values {
+			w.c.Response().Header.Add(key, value)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokensfiber
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func initForTest(t *testing.T, core *fakecore.Server, getSessionErr error) {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+	err := supertokens.Init(supertokens.TypeInput{
+		Supertokens: &supertokens.ConnectionInfo{ConnectionURI: core.URL},
+		AppInfo: supertokens.AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []supertokens.Recipe{
+			session.Init(&sessmodels.TypeInput{
+				GetTokenTransferMethod: func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+					return sessmodels.HeaderTransferMethod
+				},
+				Override: &sessmodels.OverrideStruct{
+					Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
+						originalGetSession := *originalImplementation.GetSession
+						*originalImplementation.GetSession = func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+							if getSessionErr != nil {
+								return nil, getSessionErr
+							}
+							return originalGetSession(req, res, options, userContext)
+						}
+						return originalImplementation
+					},
+				},
+			}),
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func resetForTest() {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+}
+
+func createAccessTokenForTest(t *testing.T, userID string) string {
+	res := httptest.NewRecorder()
+	_, err := session.CreateNewSession(httptest.NewRequest(http.MethodPost, "/", nil), res, userID, nil, nil)
+	assert.NoError(t, err)
+	return res.Header().Get("st-access-token")
+}
+
+func makeAppForTest() (*fiber.App, *[]error) {
+	errs := []error{}
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			errs = append(errs, err)
+			return c.SendStatus(http.StatusInternalServerError)
+		},
+	})
+	app.Use(Middleware())
+	app.Get("/profile", VerifySession(nil), func(c *fiber.Ctx) error {
+		return c.SendString(GetSession(c).GetUserID())
+	})
+	app.Post("/profile", VerifySession(nil), func(c *fiber.Ctx) error {
+		err := GetSession(c).MergeIntoAccessTokenPayload(map[string]interface{}{"name": "test"})
+		if err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+	return app, &errs
+}
+
+func TestMiddlewareServesSuperTokensAPIs(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+	app, _ := makeAppForTest()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
+
+func TestVerifySessionAttachesSessionToUserContext(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+	app, errs := makeAppForTest()
+
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+createAccessTokenForTest(t, "userId"))
+	res, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", string(body))
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/profile", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, 0, len(*errs))
+}
+
+func TestVerifySessionReturnsUnhandledErrorsToFiber(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, errors.New("test error"))
+	defer resetForTest()
+	app, errs := makeAppForTest()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/profile", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.Equal(t, 1, len(*errs))
+	assert.EqualError(t, (*errs)[0], "test error")
+}
+
+func TestSessionUpdatesAfterVerifySessionSetResponseHeaders(t *testing.T) {
+	core := fakecore.NewServer()
+	defer core.Close()
+	initForTest(t, core, nil)
+	defer resetForTest()
+	app, _ := makeAppForTest()
+
+	accessToken := createAccessTokenForTest(t, "userId")
+	req := httptest.NewRequest(http.MethodPost, "/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	res, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	newAccessToken := res.Header.Get("st-access-token")
+	assert.NotEmpty(t, newAccessToken)
+	assert.NotEqual(t, accessToken, newAccessToken)
+}
@@ -0,0 +1,89 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package supertokensgin integrates SuperTokens with gin. Middleware serves the SuperTokens APIs,
+// VerifySession checks the session of the routes it is added to, and GetSession returns it:
+//
+//	router := gin.New()
+//	router.Use(supertokensgin.Middleware())
+//	router.GET("/profile", supertokensgin.VerifySession(nil), func(c *gin.Context) {
+//		sessionContainer := supertokensgin.GetSession(c)
+//		...
+//	})
+//
+// Errors that SuperTokens cannot turn into a response themselves are added to the context with
+// c.Error, and the request is aborted with a 500 status, so they can be handled by the app's error
+// middleware instead of supertokens.TypeInput.OnSuperTokensAPIError.
+//
+// It lives in its own module so that the SDK itself does not depend on gin.
+package supertokensgin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Middleware serves the APIs exposed by the SuperTokens recipes, and passes the other requests to the next handlers
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		calledNext := false
+		handler := supertokens.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			c.Next()
+		}))
+		serveHTTP(c, handler)
+		if !calledNext {
+			c.Abort()
+		}
+	}
+}
+
+// VerifySession aborts the request if it does not have a valid session (unless options.SessionRequired
+// is false), and otherwise attaches the session to the request so that GetSession can return it
+func VerifySession(options *sessmodels.VerifySessionOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		calledNext := false
+		handler := session.VerifySession(options, func(rw http.ResponseWriter, r *http.Request) {
+			calledNext = true
+			sessionContainer := session.GetSessionFromRequestContext(r.Context())
+			if sessionContainer != nil {
+				c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), sessmodels.SessionContext, sessionContainer))
+			}
+			c.Next()
+		})
+		serveHTTP(c, handler)
+		if !calledNext {
+			c.Abort()
+		}
+	}
+}
+
+// GetSession returns the session verified by VerifySession, or nil if there is none
+func GetSession(c *gin.Context) sessmodels.SessionContainer {
+	return session.GetSessionFromRequestContext(c.Request.Context())
+}
+
+func serveHTTP(c *gin.Context, handler http.Handler) {
+	ctx := supertokens.WithAPIErrorHandler(c.Request.Context(), func(err error, req *http.Request, res http.ResponseWriter) {
+		c.Status(http.StatusInternalServerError)
+		_ = c.Error(err)
+	})
+	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+}
@@ -0,0 +1,159 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package supertokensotel exports the spans and metrics of the SuperTokens SDK
+// to OpenTelemetry. It lives in its own module so that the SDK itself does not
+// depend on OpenTelemetry:
+//
+//	instrumentation := supertokensotel.NewInstrumentation(nil)
+//	supertokens.Init(supertokens.TypeInput{
+//		...
+//		Instrumentation: instrumentation,
+//	})
+package supertokensotel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/supertokens/supertokens-golang"
+
+type Config struct {
+	// TracerProvider creates the tracer for the SDK's spans. Defaults to otel.GetTracerProvider().
+	TracerProvider trace.TracerProvider
+	// MeterProvider creates the meter for the SDK's metrics. Defaults to otel.GetMeterProvider().
+	MeterProvider metric.MeterProvider
+}
+
+type instrumentation struct {
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	lock       sync.Mutex
+	counters   map[string]metric.Int64Counter
+	histograms map[string]metric.Float64Histogram
+}
+
+// NewInstrumentation returns a supertokens.Instrumentation that records every span of the SDK
+// as an OpenTelemetry span, along with a "<span name>.duration" histogram (in seconds), and
+// every counter of the SDK as an OpenTelemetry counter.
+func NewInstrumentation(config *Config) supertokens.Instrumentation {
+	tracerProvider := otel.GetTracerProvider()
+	meterProvider := otel.GetMeterProvider()
+	if config != nil {
+		if config.TracerProvider != nil {
+			tracerProvider = config.TracerProvider
+		}
+		if config.MeterProvider != nil {
+			meterProvider = config.MeterProvider
+		}
+	}
+	return &instrumentation{
+		tracer:     tracerProvider.Tracer(instrumentationName, trace.WithInstrumentationVersion(supertokens.VERSION)),
+		meter:      meterProvider.Meter(instrumentationName, metric.WithInstrumentationVersion(supertokens.VERSION)),
+		counters:   map[string]metric.Int64Counter{},
+		histograms: map[string]metric.Float64Histogram{},
+	}
+}
+
+func (i *instrumentation) StartSpan(ctx context.Context, name string, fields []supertokens.LogField) (context.Context, supertokens.EndSpanFunc) {
+	attributes := getAttributes(fields)
+	startTime := time.Now()
+	ctx, span := i.tracer.Start(ctx, name, trace.WithAttributes(attributes...))
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		histogram, histogramErr := i.getHistogram(name + ".duration")
+		if histogramErr != nil {
+			supertokens.LogDebugMessage("supertokensotel: could not create histogram: " + histogramErr.Error())
+			return
+		}
+		histogram.Record(ctx, time.Since(startTime).Seconds(), metric.WithAttributes(attributes...))
+	}
+}
+
+func (i *instrumentation) IncrementCounter(ctx context.Context, name string, fields []supertokens.LogField) {
+	counter, err := i.getCounter(name)
+	if err != nil {
+		supertokens.LogDebugMessage("supertokensotel: could not create counter: " + err.Error())
+		return
+	}
+	counter.Add(ctx, 1, metric.WithAttributes(getAttributes(fields)...))
+}
+
+func (i *instrumentation) getCounter(name string) (metric.Int64Counter, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if counter, ok := i.counters[name]; ok {
+		return counter, nil
+	}
+	counter, err := i.meter.Int64Counter(name)
+	if err != nil {
+		return nil, err
+	}
+	i.counters[name] = counter
+	return counter, nil
+}
+
+func (i *instrumentation) getHistogram(name string) (metric.Float64Histogram, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if histogram, ok := i.histograms[name]; ok {
+		return histogram, nil
+	}
+	histogram, err := i.meter.Float64Histogram(name, metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	i.histograms[name] = histogram
+	return histogram, nil
+}
+
+func getAttributes(fields []supertokens.LogField) []attribute.KeyValue {
+	attributes := make([]attribute.KeyValue, 0, len(fields))
+	for _, field := range fields {
+		key := "supertokens." + field.Key
+		switch value := field.Value.(type) {
+		case string:
+			attributes = append(attributes, attribute.String(key, value))
+		case bool:
+			attributes = append(attributes, attribute.Bool(key, value))
+		case int:
+			attributes = append(attributes, attribute.Int(key, value))
+		case int64:
+			attributes = append(attributes, attribute.Int64(key, value))
+		case float64:
+			attributes = append(attributes, attribute.Float64(key, value))
+		case error:
+			attributes = append(attributes, attribute.String(key, value.Error()))
+		default:
+			attributes = append(attributes, attribute.String(key, fmt.Sprint(value)))
+		}
+	}
+	return attributes
+}
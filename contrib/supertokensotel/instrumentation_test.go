@@ -0,0 +1,63 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokensotel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpansAndCountersAreExported(t *testing.T) {
+	spanRecorder := tracetest.NewSpanRecorder()
+	metricReader := sdkmetric.NewManualReader()
+	instrumentation := NewInstrumentation(&Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(metricReader)),
+	})
+
+	ctx, endSpan := instrumentation.StartSpan(context.Background(), supertokens.SpanNameCoreRequest, []supertokens.LogField{supertokens.RecipeIDLogField("session")})
+	instrumentation.IncrementCounter(ctx, supertokens.CounterNameCoreErrors, []supertokens.LogField{supertokens.RecipeIDLogField("session")})
+	endSpan(errors.New("core error"))
+
+	spans := spanRecorder.Ended()
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, supertokens.SpanNameCoreRequest, spans[0].Name())
+	assert.Equal(t, []attribute.KeyValue{attribute.String("supertokens.recipeId", "session")}, spans[0].Attributes())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	var data metricdata.ResourceMetrics
+	assert.NoError(t, metricReader.Collect(context.Background(), &data))
+	names := []string{}
+	for _, scopeMetrics := range data.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			names = append(names, m.Name)
+			if m.Name == supertokens.CounterNameCoreErrors {
+				assert.Equal(t, int64(1), m.Data.(metricdata.Sum[int64]).DataPoints[0].Value)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{supertokens.CounterNameCoreErrors, supertokens.SpanNameCoreRequest + ".duration"}, names)
+}
@@ -75,7 +75,7 @@ func AnalyticsPost(apiInterface dashboardmodels.APIInterface, options dashboardm
 		return analyticsPostResponse{}, err
 	}
 
-	response, err := querier.SendGetRequest("/telemetry", nil)
+	response, err := querier.SendGetRequest("/telemetry", nil, supertokens.MakeDefaultUserContextFromAPI(options.Req))
 	if err != nil {
 		// We don't send telemetry events if this fails
 		return analyticsPostResponse{
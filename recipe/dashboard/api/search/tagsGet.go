@@ -32,7 +32,7 @@ func SearchTagsGet(apiImplementation dashboardmodels.APIInterface, options dashb
 		return searchTagsResponse{}, querierErr
 	}
 
-	apiResponse, apiErr := querier.SendGetRequest("/user/search/tags", nil)
+	apiResponse, apiErr := querier.SendGetRequest("/user/search/tags", nil, supertokens.MakeDefaultUserContextFromAPI(options.Req))
 	if apiErr != nil {
 		return searchTagsResponse{}, apiErr
 	}
@@ -51,7 +51,7 @@ func SignInPost(apiInterface dashboardmodels.APIInterface, options dashboardmode
 	apiResponse, apiErr := querier.SendPostRequest("/recipe/dashboard/signin", map[string]interface{}{
 		"email":    *readBody.Email,
 		"password": *readBody.Password,
-	})
+	}, supertokens.MakeDefaultUserContextFromAPI(options.Req))
 
 	if apiErr != nil {
 		return apiErr
@@ -31,7 +31,7 @@ func SignOutPost(apiInterface dashboardmodels.APIInterface, options dashboardmod
 
 	_, apiError := querier.SendDeleteRequest("/recipe/dashboard/session", map[string]interface{}{}, map[string]string{
 		"sessionId": sessionIdFromHeader,
-	})
+	}, supertokens.MakeDefaultUserContextFromAPI(options.Req))
 
 	if apiError != nil {
 		return signOutPostResponse{}, apiError
@@ -16,11 +16,14 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
 	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	sessErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -147,6 +150,11 @@ func MakeAPIImplementation() epmodels.APIInterface {
 		user := response.OK.User
 		session, err := session.CreateNewSessionWithContext(options.Req, options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
+			if errors.As(err, &sessErrors.SessionLimitReachedError{}) {
+				return epmodels.SignInPOSTResponse{
+					SessionLimitReachedError: &struct{}{},
+				}, nil
+			}
 			return epmodels.SignInPOSTResponse{}, err
 		}
 
@@ -199,11 +207,55 @@ func MakeAPIImplementation() epmodels.APIInterface {
 			},
 		}, nil
 	}
+
+	reauthenticatePOST := func(formFields []epmodels.TypeFormField, sessionContainer sessmodels.SessionContainer, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.ReauthenticatePOSTResponse, error) {
+		var email string
+		var password string
+		for _, formField := range formFields {
+			if formField.ID == "email" {
+				email = formField.Value
+			} else if formField.ID == "password" {
+				password = formField.Value
+			}
+		}
+
+		response, err := (*options.RecipeImplementation.SignIn)(email, password, userContext)
+		if err != nil {
+			return epmodels.ReauthenticatePOSTResponse{}, err
+		}
+		if response.WrongCredentialsError != nil {
+			return epmodels.ReauthenticatePOSTResponse{
+				WrongCredentialsError: &struct{}{},
+			}, nil
+		}
+		if response.OK.User.ID != sessionContainer.GetUserIDWithContext(userContext) {
+			return epmodels.ReauthenticatePOSTResponse{
+				UserMismatchError: &struct{}{},
+			}, nil
+		}
+
+		err = sessionContainer.FetchAndSetClaimWithContext(claims.AuthFreshnessClaim, userContext)
+		if err != nil {
+			return epmodels.ReauthenticatePOSTResponse{}, err
+		}
+
+		return epmodels.ReauthenticatePOSTResponse{
+			OK: &struct {
+				User    epmodels.User
+				Session sessmodels.SessionContainer
+			}{
+				User:    response.OK.User,
+				Session: sessionContainer,
+			},
+		}, nil
+	}
+
 	return epmodels.APIInterface{
 		EmailExistsGET:                 &emailExistsGET,
 		GeneratePasswordResetTokenPOST: &generatePasswordResetTokenPOST,
 		PasswordResetPOST:              &passwordResetPOST,
 		SignInPOST:                     &signInPOST,
 		SignUpPOST:                     &signUpPOST,
+		ReauthenticatePOST:             &reauthenticatePOST,
 	}
 }
@@ -0,0 +1,86 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword/epmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func ReauthenticateAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOptions) error {
+	if apiImplementation.ReauthenticatePOST == nil || (*apiImplementation.ReauthenticatePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	// the global claim validators are not checked, since the user may be re-authenticating to satisfy one of them
+	sessionContainer, err := session.GetSessionWithContext(
+		options.Req, options.Res,
+		&sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				return []claims.SessionClaimValidator{}, nil
+			},
+		},
+		userContext,
+	)
+	if err != nil {
+		return err
+	}
+
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return err
+	}
+	var formFieldsRaw map[string]interface{}
+	err = json.Unmarshal(body, &formFieldsRaw)
+	if err != nil {
+		return err
+	}
+
+	formFields, err := validateFormFieldsOrThrowError(options.Config.SignInFeature.FormFields, formFieldsRaw["formFields"])
+	if err != nil {
+		return err
+	}
+
+	result, err := (*apiImplementation.ReauthenticatePOST)(formFields, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+	if result.WrongCredentialsError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "WRONG_CREDENTIALS_ERROR",
+		})
+	} else if result.UserMismatchError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "USER_MISMATCH_ERROR",
+		})
+	} else if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+			"user":   result.OK.User,
+		})
+	} else if result.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*result.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
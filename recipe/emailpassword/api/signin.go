@@ -56,6 +56,10 @@ func SignInAPI(apiImplementation epmodels.APIInterface, options epmodels.APIOpti
 			"status": "OK",
 			"user":   result.OK.User,
 		})
+	} else if result.SessionLimitReachedError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "SESSION_LIMIT_REACHED_ERROR",
+		})
 	} else if result.GeneralError != nil {
 		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*result.GeneralError))
 	}
@@ -21,4 +21,5 @@ const (
 	GeneratePasswordResetTokenAPI = "/user/password/reset/token"
 	PasswordResetAPI              = "/user/password/reset"
 	SignupEmailExistsAPI          = "/signup/email/exists"
+	ReauthenticateAPI             = "/signin/reauthenticate"
 )
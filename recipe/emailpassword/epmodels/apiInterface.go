@@ -40,6 +40,9 @@ type APIInterface struct {
 	PasswordResetPOST              *func(formFields []TypeFormField, token string, options APIOptions, userContext supertokens.UserContext) (ResetPasswordPOSTResponse, error)
 	SignInPOST                     *func(formFields []TypeFormField, options APIOptions, userContext supertokens.UserContext) (SignInPOSTResponse, error)
 	SignUpPOST                     *func(formFields []TypeFormField, options APIOptions, userContext supertokens.UserContext) (SignUpPOSTResponse, error)
+	// ReauthenticatePOST checks the credentials of the user of the current session, and updates
+	// the time of their last authentication in the session instead of creating a new one
+	ReauthenticatePOST *func(formFields []TypeFormField, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (ReauthenticatePOSTResponse, error)
 }
 
 type ResetPasswordPOSTResponse struct {
@@ -65,7 +68,20 @@ type SignInPOSTResponse struct {
 		Session sessmodels.SessionContainer
 	}
 	WrongCredentialsError *struct{}
-	GeneralError          *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
+}
+
+type ReauthenticatePOSTResponse struct {
+	OK *struct {
+		User    User
+		Session sessmodels.SessionContainer
+	}
+	WrongCredentialsError *struct{}
+	// UserMismatchError is returned if the credentials are the ones of another user than the user of the session
+	UserMismatchError *struct{}
+	GeneralError      *supertokens.GeneralErrorResponse
 }
 
 type EmailExistsGETResponse struct {
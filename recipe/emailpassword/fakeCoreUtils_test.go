@@ -0,0 +1,41 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+// startFakeCoreForTest starts a fake core for the test, initialises the SDK with this recipe and the session recipe,
+// pointing it to the fake core, and returns a server handling the APIs of the SDK
+func startFakeCoreForTest(t *testing.T, sessionConfig *sessmodels.TypeInput) *httptest.Server {
+	core := fakecore.StartForTest(t, resetAll)
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{
+		RecipeList: []supertokens.Recipe{Init(nil), session.Init(sessionConfig)},
+	})
+
+	testServer := httptest.NewServer(supertokens.Middleware(http.NewServeMux()))
+	t.Cleanup(testServer.Close)
+	return testServer
+}
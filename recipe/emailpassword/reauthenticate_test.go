@@ -0,0 +1,103 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestReauthenticationAgainstFakeCore(t *testing.T) {
+	testServer := startFakeCoreForTest(t, nil)
+
+	_, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	_, err = unittesting.SignupRequest("other@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	user, err := GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+
+	maxAuthAge := &sessmodels.VerifySessionOptions{
+		OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+			return append(globalClaimValidators, claims.AuthFreshnessClaimValidators.MaxAuthAge(60, nil)), nil
+		},
+	}
+
+	newSession, err := session.CreateNewSessionWithoutRequestResponse(user.ID, nil, nil, true)
+	assert.NoError(t, err)
+	_, err = session.GetSessionWithoutRequestResponse(newSession.GetAccessToken(), nil, maxAuthAge)
+	assert.NoError(t, err)
+
+	err = newSession.SetClaimValue(claims.AuthFreshnessClaim, time.Now().Add(-time.Hour).UnixNano()/1000000)
+	assert.NoError(t, err)
+	accessToken := newSession.GetAccessToken()
+	_, err = session.GetSessionWithoutRequestResponse(accessToken, nil, maxAuthAge)
+	assert.True(t, errors.As(err, &sessionErrors.InvalidClaimError{}))
+
+	reauthenticate := func(email string, password string) (string, string) {
+		body, err := json.Marshal(map[string]interface{}{
+			"formFields": []map[string]string{{"id": "email", "value": email}, {"id": "password", "value": password}},
+		})
+		assert.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, testServer.URL+"/auth/signin/reauthenticate", strings.NewReader(string(body)))
+		assert.NoError(t, err)
+		req.Header.Add("Cookie", "sAccessToken="+url.QueryEscape(accessToken))
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		result := map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		for _, cookie := range res.Cookies() {
+			if cookie.Name == "sAccessToken" {
+				newAccessToken, err := url.QueryUnescape(cookie.Value)
+				assert.NoError(t, err)
+				return result["status"].(string), newAccessToken
+			}
+		}
+		return result["status"].(string), ""
+	}
+
+	status, newAccessToken := reauthenticate("test@example.com", "wrongpass123")
+	assert.Equal(t, "WRONG_CREDENTIALS_ERROR", status)
+	assert.Empty(t, newAccessToken)
+
+	status, newAccessToken = reauthenticate("other@example.com", "validpass123")
+	assert.Equal(t, "USER_MISMATCH_ERROR", status)
+	assert.Empty(t, newAccessToken)
+
+	status, newAccessToken = reauthenticate("test@example.com", "validpass123")
+	assert.Equal(t, "OK", status)
+	reauthenticatedSession, err := session.GetSessionWithoutRequestResponse(newAccessToken, nil, maxAuthAge)
+	assert.NoError(t, err)
+	assert.Equal(t, newSession.GetHandle(), reauthenticatedSession.GetHandle())
+	// the user only has the sessions created by the sign up and by the test
+	sessionHandles, err := session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(sessionHandles))
+}
@@ -120,6 +120,10 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	reauthenticateAPI, err := supertokens.NewNormalisedURLPath(constants.ReauthenticateAPI)
+	if err != nil {
+		return nil, err
+	}
 	return []supertokens.APIHandled{{
 		Method:                 http.MethodPost,
 		PathWithoutAPIBasePath: signUpAPI,
@@ -145,6 +149,11 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		PathWithoutAPIBasePath: signupEmailExistsAPI,
 		ID:                     constants.SignupEmailExistsAPI,
 		Disabled:               r.APIImpl.EmailExistsGET == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: reauthenticateAPI,
+		ID:                     constants.ReauthenticateAPI,
+		Disabled:               r.APIImpl.ReauthenticatePOST == nil,
 	}}, nil
 }
 
@@ -169,6 +178,8 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		return api.PasswordReset(r.APIImpl, options)
 	} else if id == constants.SignupEmailExistsAPI {
 		return api.EmailExists(r.APIImpl, options)
+	} else if id == constants.ReauthenticateAPI {
+		return api.ReauthenticateAPI(r.APIImpl, options)
 	}
 	return defaultErrors.New("should never come here")
 }
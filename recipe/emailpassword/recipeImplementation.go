@@ -25,7 +25,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 		response, err := querier.SendPostRequest("/recipe/signup", map[string]interface{}{
 			"email":    email,
 			"password": password,
-		})
+		}, userContext)
 		if err != nil {
 			return epmodels.SignUpResponse{}, err
 		}
@@ -35,6 +35,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 			if err != nil {
 				return epmodels.SignUpResponse{}, err
 			}
+			supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameSignUps, supertokens.RecipeIDLogField(RECIPE_ID))
 			return epmodels.SignUpResponse{
 				OK: &struct{ User epmodels.User }{User: *user},
 			}, nil
@@ -48,7 +49,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 		response, err := querier.SendPostRequest("/recipe/signin", map[string]interface{}{
 			"email":    email,
 			"password": password,
-		})
+		}, userContext)
 		if err != nil {
 			return epmodels.SignInResponse{}, err
 		}
@@ -58,6 +59,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 			if err != nil {
 				return epmodels.SignInResponse{}, err
 			}
+			supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameSignIns, supertokens.RecipeIDLogField(RECIPE_ID))
 			return epmodels.SignInResponse{
 				OK: &struct{ User epmodels.User }{User: *user},
 			}, nil
@@ -70,7 +72,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	getUserByID := func(userID string, userContext supertokens.UserContext) (*epmodels.User, error) {
 		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
 			"userId": userID,
-		})
+		}, userContext)
 		if err != nil {
 			return nil, err
 		}
@@ -88,7 +90,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	getUserByEmail := func(email string, userContext supertokens.UserContext) (*epmodels.User, error) {
 		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
 			"email": email,
-		})
+		}, userContext)
 		if err != nil {
 			return nil, err
 		}
@@ -106,7 +108,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 	createResetPasswordToken := func(userID string, userContext supertokens.UserContext) (epmodels.CreateResetPasswordTokenResponse, error) {
 		response, err := querier.SendPostRequest("/recipe/user/password/reset/token", map[string]interface{}{
 			"userId": userID,
-		})
+		}, userContext)
 		if err != nil {
 			return epmodels.CreateResetPasswordTokenResponse{}, err
 		}
@@ -126,7 +128,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 			"method":      "token",
 			"token":       token,
 			"newPassword": newPassword,
-		})
+		}, userContext)
 		if err != nil {
 			return epmodels.ResetPasswordUsingTokenResponse{}, nil
 		}
@@ -168,7 +170,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) epmodels.RecipeInterf
 		if password != nil {
 			requestBody["password"] = password
 		}
-		response, err := querier.SendPutRequest("/recipe/user", requestBody)
+		response, err := querier.SendPutRequest("/recipe/user", requestBody, userContext)
 		if err != nil {
 			return epmodels.UpdateEmailOrPasswordResponse{}, nil
 		}
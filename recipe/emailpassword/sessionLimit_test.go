@@ -0,0 +1,60 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailpassword
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	sessionErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func TestSessionLimitAgainstFakeCore(t *testing.T) {
+	testServer := startFakeCoreForTest(t, &sessmodels.TypeInput{
+		SessionLimit: &sessmodels.SessionLimitConfig{MaxSessions: 2},
+	})
+
+	res, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = unittesting.SignInRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body := map[string]interface{}{}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, "OK", body["status"])
+
+	res, err = unittesting.SignInRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body = map[string]interface{}{}
+	assert.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+	assert.Equal(t, "SESSION_LIMIT_REACHED_ERROR", body["status"])
+
+	user, err := GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+	_, err = session.CreateNewSessionWithoutRequestResponse(user.ID, nil, nil, true)
+	assert.True(t, errors.As(err, &sessionErrors.SessionLimitReachedError{}))
+	sessionHandles, err := session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(sessionHandles))
+}
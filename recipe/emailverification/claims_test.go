@@ -0,0 +1,62 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package emailverification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evclaims"
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func TestRefreshSessionClaimsOnChangeAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{
+		RecipeList: []supertokens.Recipe{
+			Init(evmodels.TypeInput{
+				Mode:                         evmodels.ModeOptional,
+				RefreshSessionClaimsOnChange: true,
+				GetEmailForUserID: func(userID string, userContext supertokens.UserContext) (evmodels.TypeEmailInfo, error) {
+					return evmodels.TypeEmailInfo{OK: &struct{ Email string }{Email: "test@example.com"}}, nil
+				},
+			}),
+			session.Init(nil),
+		},
+	})
+
+	newSession, err := session.CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	isVerified := func() interface{} {
+		sessionInformation, err := session.GetSessionInformation(newSession.GetHandle())
+		assert.NoError(t, err)
+		return evclaims.EmailVerificationClaim.GetValueFromPayload(sessionInformation.AccessTokenPayload, &map[string]interface{}{})
+	}
+	assert.Equal(t, false, isVerified())
+
+	tokenResponse, err := CreateEmailVerificationToken("userId", nil)
+	assert.NoError(t, err)
+	_, err = VerifyEmailUsingToken(tokenResponse.OK.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, true, isVerified())
+	_, err = UnverifyEmail("userId", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, false, isVerified())
+}
@@ -43,13 +43,18 @@ type TypeInput struct {
 	CreateAndSendCustomEmail func(user User, emailVerificationURLWithToken string, userContext supertokens.UserContext) // Deprecated: Use EmailDelivery instead.
 	Override                 *OverrideStruct
 	EmailDelivery            *emaildelivery.TypeInput
+	// RefreshSessionClaimsOnChange makes VerifyEmailUsingToken and UnverifyEmail refresh the email verification
+	// claim of all the sessions of the user, instead of only the session of the request verifying the email.
+	// The claim is refreshed after the change is saved, so the failures to refresh it are only logged.
+	RefreshSessionClaimsOnChange bool
 }
 
 type TypeNormalisedInput struct {
-	Mode                   TypeMode
-	GetEmailForUserID      TypeGetEmailForUserID
-	Override               OverrideStruct
-	GetEmailDeliveryConfig func() emaildelivery.TypeInputWithService
+	Mode                         TypeMode
+	GetEmailForUserID            TypeGetEmailForUserID
+	Override                     OverrideStruct
+	GetEmailDeliveryConfig       func() emaildelivery.TypeInputWithService
+	RefreshSessionClaimsOnChange bool
 }
 
 type OverrideStruct struct {
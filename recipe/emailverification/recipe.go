@@ -58,6 +58,9 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config e
 		return Recipe{}, err
 	}
 	recipeImplementation := makeRecipeImplementation(*querierInstance)
+	if verifiedConfig.RefreshSessionClaimsOnChange {
+		recipeImplementation = makeRecipeImplementationWithClaimRefresh(recipeImplementation)
+	}
 	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 
 	recipeModuleInstance := supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, nil, r.handleError, onSuperTokensAPIError)
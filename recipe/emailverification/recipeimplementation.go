@@ -16,7 +16,9 @@
 package emailverification
 
 import (
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evclaims"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
@@ -25,7 +27,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 		response, err := querier.SendPostRequest("/recipe/user/email/verify/token", map[string]interface{}{
 			"userId": userID,
 			"email":  email,
-		})
+		}, userContext)
 		if err != nil {
 			return evmodels.CreateEmailVerificationTokenResponse{}, err
 		}
@@ -45,7 +47,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 		response, err := querier.SendPostRequest("/recipe/user/email/verify", map[string]interface{}{
 			"method": "token",
 			"token":  token,
-		})
+		}, userContext)
 		if err != nil {
 			return evmodels.VerifyEmailUsingTokenResponse{}, err
 		}
@@ -67,7 +69,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 		response, err := querier.SendGetRequest("/recipe/user/email/verify", map[string]string{
 			"userId": userID,
 			"email":  email,
-		})
+		}, userContext)
 		if err != nil {
 			return false, err
 		}
@@ -78,7 +80,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 		_, err := querier.SendPostRequest("/recipe/user/email/verify/token/remove", map[string]interface{}{
 			"userId": userId,
 			"email":  email,
-		})
+		}, userContext)
 		if err != nil {
 			return evmodels.RevokeEmailVerificationTokensResponse{}, err
 		}
@@ -91,7 +93,7 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 		_, err := querier.SendPostRequest("/recipe/user/email/verify/remove", map[string]interface{}{
 			"userId": userId,
 			"email":  email,
-		})
+		}, userContext)
 		if err != nil {
 			return evmodels.UnverifyEmailResponse{}, err
 		}
@@ -107,3 +109,44 @@ func makeRecipeImplementation(querier supertokens.Querier) evmodels.RecipeInterf
 		UnverifyEmail:                 &unverifyEmail,
 	}
 }
+
+// makeRecipeImplementationWithClaimRefresh changes the functions verifying and unverifying emails of
+// originalImplementation so that the email verification claim of all the sessions of the user is refreshed. The claim
+// is refreshed once the email is verified or unverified in the core, so a failed refresh is only logged, and the
+// sessions get the new value when the validators of the claim refetch it.
+func makeRecipeImplementationWithClaimRefresh(originalImplementation evmodels.RecipeInterface) evmodels.RecipeInterface {
+	refreshClaimOfUser := func(userID string, userContext supertokens.UserContext) {
+		_, err := session.RefreshClaimForAllSessionsOfUserWithContext(userID, evclaims.EmailVerificationClaim, userContext)
+		if err != nil {
+			supertokens.LogWarnMessage("emailverification: could not refresh the email verification claim of the sessions of the user", supertokens.RecipeIDLogField(RECIPE_ID), supertokens.UserIDLogField(userID), supertokens.ErrorLogField(err))
+		}
+	}
+
+	{
+		originalVerifyEmailUsingToken := *originalImplementation.VerifyEmailUsingToken
+
+		(*originalImplementation.VerifyEmailUsingToken) = func(token string, userContext supertokens.UserContext) (evmodels.VerifyEmailUsingTokenResponse, error) {
+			response, err := originalVerifyEmailUsingToken(token, userContext)
+			if err != nil || response.OK == nil {
+				return response, err
+			}
+			refreshClaimOfUser(response.OK.User.ID, userContext)
+			return response, nil
+		}
+	}
+
+	{
+		originalUnverifyEmail := *originalImplementation.UnverifyEmail
+
+		(*originalImplementation.UnverifyEmail) = func(userId string, email string, userContext supertokens.UserContext) (evmodels.UnverifyEmailResponse, error) {
+			response, err := originalUnverifyEmail(userId, email, userContext)
+			if err != nil || response.OK == nil {
+				return response, err
+			}
+			refreshClaimOfUser(userId, userContext)
+			return response, nil
+		}
+	}
+
+	return originalImplementation
+}
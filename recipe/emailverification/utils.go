@@ -32,6 +32,7 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	typeNormalisedInput.Mode = config.Mode
 	typeNormalisedInput.GetEmailForUserID = config.GetEmailForUserID
+	typeNormalisedInput.RefreshSessionClaimsOnChange = config.RefreshSessionClaimsOnChange
 
 	typeNormalisedInput.GetEmailDeliveryConfig = func() emaildelivery.TypeInputWithService {
 		createAndSendCustomEmail := DefaultCreateAndSendCustomEmail(appInfo)
@@ -35,7 +35,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config jwtmodels.Type
 			"validity":   validitySeconds,
 			"algorithm":  "RS256",
 			"jwksDomain": appInfo.APIDomain.GetAsStringDangerous(),
-		})
+		}, userContext)
 		if err != nil {
 			return jwtmodels.CreateJWTResponse{}, err
 		}
@@ -54,7 +54,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config jwtmodels.Type
 		}
 	}
 	getJWKS := func(userContext supertokens.UserContext) (jwtmodels.GetJWKSResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/jwt/jwks", map[string]string{})
+		response, err := querier.SendGetRequest("/recipe/jwt/jwks", map[string]string{}, userContext)
 		if err != nil {
 			return jwtmodels.GetJWKSResponse{}, err
 		}
@@ -30,14 +30,64 @@ func ConsumeCode(apiImplementation plessmodels.APIInterface, options plessmodels
 		return nil
 	}
 
-	body, err := supertokens.ReadFromRequest(options.Req)
+	userInput, linkCode, preAuthSessionID, err := getConsumeCodeInput(options)
+	if err != nil {
+		return err
+	}
+
+	response, err := (*apiImplementation.ConsumeCodePOST)(userInput, linkCode, preAuthSessionID, options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
 	if err != nil {
 		return err
 	}
+
+	var result map[string]interface{}
+
+	if response.OK != nil {
+		result = map[string]interface{}{
+			"status":         "OK",
+			"createdNewUser": response.OK.CreatedNewUser,
+			"user":           response.OK.User,
+		}
+	} else if response.ExpiredUserInputCodeError != nil {
+		result = map[string]interface{}{
+			"status":                      "EXPIRED_USER_INPUT_CODE_ERROR",
+			"failedCodeInputAttemptCount": response.ExpiredUserInputCodeError.FailedCodeInputAttemptCount,
+			"maximumCodeInputAttempts":    response.ExpiredUserInputCodeError.MaximumCodeInputAttempts,
+		}
+	} else if response.IncorrectUserInputCodeError != nil {
+		result = map[string]interface{}{
+			"status":                      "INCORRECT_USER_INPUT_CODE_ERROR",
+			"failedCodeInputAttemptCount": response.IncorrectUserInputCodeError.FailedCodeInputAttemptCount,
+			"maximumCodeInputAttempts":    response.IncorrectUserInputCodeError.MaximumCodeInputAttempts,
+		}
+	} else if response.RestartFlowError != nil {
+		result = map[string]interface{}{
+			"status": "RESTART_FLOW_ERROR",
+		}
+	} else if response.SessionLimitReachedError != nil {
+		result = map[string]interface{}{
+			"status": "SESSION_LIMIT_REACHED_ERROR",
+		}
+	} else if response.GeneralError != nil {
+		result = supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError)
+	} else {
+		return supertokens.ErrorIfNoResponse(options.Res)
+	}
+
+	return supertokens.Send200Response(options.Res, result)
+}
+
+// getConsumeCodeInput reads the code to consume from the body of the request, which is
+// either a linkCode or a deviceId and userInputCode, and its preAuthSessionId
+func getConsumeCodeInput(options plessmodels.APIOptions) (*plessmodels.UserInputCodeWithDeviceID, *string, string, error) {
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return nil, nil, "", err
+	}
 	var readBody map[string]interface{}
 	err = json.Unmarshal(body, &readBody)
 	if err != nil {
-		return err
+		return nil, nil, "", err
 	}
 
 	preAuthSessionID, okPreAuthSessionID := readBody["preAuthSessionId"]
@@ -46,32 +96,32 @@ func ConsumeCode(apiImplementation plessmodels.APIInterface, options plessmodels
 	userInputCode, okUserInputCode := readBody["userInputCode"]
 
 	if !okPreAuthSessionID || reflect.ValueOf(preAuthSessionID).Kind() != reflect.String {
-		return supertokens.BadInputError{Msg: "Please provide preAuthSessionId"}
+		return nil, nil, "", supertokens.BadInputError{Msg: "Please provide preAuthSessionId"}
 	}
 
 	if okUserInputCode || okDeviceID {
 		// if either userInputCode or deviceId exists in the input
 		if okLinkCode {
-			return supertokens.BadInputError{Msg: "Please provide one of (linkCode) or (deviceId+userInputCode) and not both"}
+			return nil, nil, "", supertokens.BadInputError{Msg: "Please provide one of (linkCode) or (deviceId+userInputCode) and not both"}
 		}
 
 		if !okUserInputCode || !okDeviceID {
-			return supertokens.BadInputError{Msg: "Please provide both deviceId and userInputCode"}
+			return nil, nil, "", supertokens.BadInputError{Msg: "Please provide both deviceId and userInputCode"}
 		}
 
 		if reflect.ValueOf(userInputCode).Kind() != reflect.String {
-			return supertokens.BadInputError{Msg: "Please make sure that userInputCode is a string"}
+			return nil, nil, "", supertokens.BadInputError{Msg: "Please make sure that userInputCode is a string"}
 		}
 
 		if reflect.ValueOf(deviceID).Kind() != reflect.String {
-			return supertokens.BadInputError{Msg: "Please make sure that deviceId is a string"}
+			return nil, nil, "", supertokens.BadInputError{Msg: "Please make sure that deviceId is a string"}
 		}
 	} else if !okLinkCode {
-		return supertokens.BadInputError{Msg: "Please provide one of (linkCode) or (deviceId+userInputCode) and not both"}
+		return nil, nil, "", supertokens.BadInputError{Msg: "Please provide one of (linkCode) or (deviceId+userInputCode) and not both"}
 	}
 
 	if okLinkCode && reflect.ValueOf(linkCode).Kind() != reflect.String {
-		return supertokens.BadInputError{Msg: "Please make sure that linkCode is a string"}
+		return nil, nil, "", supertokens.BadInputError{Msg: "Please make sure that linkCode is a string"}
 	}
 
 	var userInput *plessmodels.UserInputCodeWithDeviceID
@@ -89,40 +139,5 @@ func ConsumeCode(apiImplementation plessmodels.APIInterface, options plessmodels
 		linkCodePointer = &t
 	}
 
-	response, err := (*apiImplementation.ConsumeCodePOST)(userInput, linkCodePointer, preAuthSessionID.(string), options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
-	if err != nil {
-		return err
-	}
-
-	var result map[string]interface{}
-
-	if response.OK != nil {
-		result = map[string]interface{}{
-			"status":         "OK",
-			"createdNewUser": response.OK.CreatedNewUser,
-			"user":           response.OK.User,
-		}
-	} else if response.ExpiredUserInputCodeError != nil {
-		result = map[string]interface{}{
-			"status":                      "EXPIRED_USER_INPUT_CODE_ERROR",
-			"failedCodeInputAttemptCount": response.ExpiredUserInputCodeError.FailedCodeInputAttemptCount,
-			"maximumCodeInputAttempts":    response.ExpiredUserInputCodeError.MaximumCodeInputAttempts,
-		}
-	} else if response.IncorrectUserInputCodeError != nil {
-		result = map[string]interface{}{
-			"status":                      "INCORRECT_USER_INPUT_CODE_ERROR",
-			"failedCodeInputAttemptCount": response.IncorrectUserInputCodeError.FailedCodeInputAttemptCount,
-			"maximumCodeInputAttempts":    response.IncorrectUserInputCodeError.MaximumCodeInputAttempts,
-		}
-	} else if response.RestartFlowError != nil {
-		result = map[string]interface{}{
-			"status": "RESTART_FLOW_ERROR",
-		}
-	} else if response.GeneralError != nil {
-		result = supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError)
-	} else {
-		return supertokens.ErrorIfNoResponse(options.Res)
-	}
-
-	return supertokens.Send200Response(options.Res, result)
+	return userInput, linkCodePointer, preAuthSessionID.(string), nil
 }
@@ -16,6 +16,7 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/supertokens/supertokens-golang/ingredients/emaildelivery"
@@ -23,6 +24,8 @@ import (
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
 	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	sessErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -63,6 +66,11 @@ func MakeAPIImplementation() plessmodels.APIInterface {
 
 		session, err := session.CreateNewSessionWithContext(options.Req, options.Res, user.ID, map[string]interface{}{}, map[string]interface{}{}, userContext)
 		if err != nil {
+			if errors.As(err, &sessErrors.SessionLimitReachedError{}) {
+				return plessmodels.ConsumeCodePOSTResponse{
+					SessionLimitReachedError: &struct{}{},
+				}, nil
+			}
 			return plessmodels.ConsumeCodePOSTResponse{}, err
 		}
 
@@ -376,11 +384,76 @@ func MakeAPIImplementation() plessmodels.APIInterface {
 		}, nil
 	}
 
+	reauthenticatePOST := func(userInput *plessmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, sessionContainer sessmodels.SessionContainer, options plessmodels.APIOptions, userContext supertokens.UserContext) (plessmodels.ReauthenticatePOSTResponse, error) {
+		userID := sessionContainer.GetUserIDWithContext(userContext)
+		user, err := (*options.RecipeImplementation.GetUserByID)(userID, userContext)
+		if err != nil {
+			return plessmodels.ReauthenticatePOSTResponse{}, err
+		}
+		if user == nil {
+			return plessmodels.ReauthenticatePOSTResponse{
+				UserMismatchError: &struct{}{},
+			}, nil
+		}
+
+		// the code is checked before being consumed, since consuming a code sent to another email
+		// or phone number would sign up a new user
+		device, err := (*options.RecipeImplementation.ListCodesByPreAuthSessionID)(preAuthSessionID, userContext)
+		if err != nil {
+			return plessmodels.ReauthenticatePOSTResponse{}, err
+		}
+		if device == nil {
+			return plessmodels.ReauthenticatePOSTResponse{
+				RestartFlowError: &struct{}{},
+			}, nil
+		}
+		isSameEmail := device.Email != nil && user.Email != nil && *device.Email == *user.Email
+		isSamePhoneNumber := device.PhoneNumber != nil && user.PhoneNumber != nil && *device.PhoneNumber == *user.PhoneNumber
+		if !isSameEmail && !isSamePhoneNumber {
+			return plessmodels.ReauthenticatePOSTResponse{
+				UserMismatchError: &struct{}{},
+			}, nil
+		}
+
+		response, err := (*options.RecipeImplementation.ConsumeCode)(userInput, linkCode, preAuthSessionID, userContext)
+		if err != nil {
+			return plessmodels.ReauthenticatePOSTResponse{}, err
+		}
+		if response.OK == nil {
+			return plessmodels.ReauthenticatePOSTResponse{
+				IncorrectUserInputCodeError: response.IncorrectUserInputCodeError,
+				ExpiredUserInputCodeError:   response.ExpiredUserInputCodeError,
+				RestartFlowError:            response.RestartFlowError,
+			}, nil
+		}
+		if response.OK.User.ID != userID {
+			return plessmodels.ReauthenticatePOSTResponse{
+				UserMismatchError: &struct{}{},
+			}, nil
+		}
+
+		err = sessionContainer.FetchAndSetClaimWithContext(claims.AuthFreshnessClaim, userContext)
+		if err != nil {
+			return plessmodels.ReauthenticatePOSTResponse{}, err
+		}
+
+		return plessmodels.ReauthenticatePOSTResponse{
+			OK: &struct {
+				User    plessmodels.User
+				Session sessmodels.SessionContainer
+			}{
+				User:    response.OK.User,
+				Session: sessionContainer,
+			},
+		}, nil
+	}
+
 	return plessmodels.APIInterface{
 		ConsumeCodePOST:      &consumeCodePOST,
 		CreateCodePOST:       &createCodePOST,
 		EmailExistsGET:       &emailExistsGET,
 		PhoneNumberExistsGET: &phoneNumberExistsGET,
 		ResendCodePOST:       &resendCodePOST,
+		ReauthenticatePOST:   &reauthenticatePOST,
 	}
 }
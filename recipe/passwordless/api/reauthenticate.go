@@ -0,0 +1,92 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func Reauthenticate(apiImplementation plessmodels.APIInterface, options plessmodels.APIOptions) error {
+	if apiImplementation.ReauthenticatePOST == nil || (*apiImplementation.ReauthenticatePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	// the global claim validators are not checked, since the user may be re-authenticating to satisfy one of them
+	sessionContainer, err := session.GetSessionWithContext(
+		options.Req, options.Res,
+		&sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				return []claims.SessionClaimValidator{}, nil
+			},
+		},
+		userContext,
+	)
+	if err != nil {
+		return err
+	}
+
+	userInput, linkCode, preAuthSessionID, err := getConsumeCodeInput(options)
+	if err != nil {
+		return err
+	}
+
+	response, err := (*apiImplementation.ReauthenticatePOST)(userInput, linkCode, preAuthSessionID, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]interface{}
+
+	if response.OK != nil {
+		result = map[string]interface{}{
+			"status": "OK",
+			"user":   response.OK.User,
+		}
+	} else if response.ExpiredUserInputCodeError != nil {
+		result = map[string]interface{}{
+			"status":                      "EXPIRED_USER_INPUT_CODE_ERROR",
+			"failedCodeInputAttemptCount": response.ExpiredUserInputCodeError.FailedCodeInputAttemptCount,
+			"maximumCodeInputAttempts":    response.ExpiredUserInputCodeError.MaximumCodeInputAttempts,
+		}
+	} else if response.IncorrectUserInputCodeError != nil {
+		result = map[string]interface{}{
+			"status":                      "INCORRECT_USER_INPUT_CODE_ERROR",
+			"failedCodeInputAttemptCount": response.IncorrectUserInputCodeError.FailedCodeInputAttemptCount,
+			"maximumCodeInputAttempts":    response.IncorrectUserInputCodeError.MaximumCodeInputAttempts,
+		}
+	} else if response.RestartFlowError != nil {
+		result = map[string]interface{}{
+			"status": "RESTART_FLOW_ERROR",
+		}
+	} else if response.UserMismatchError != nil {
+		result = map[string]interface{}{
+			"status": "USER_MISMATCH_ERROR",
+		}
+	} else if response.GeneralError != nil {
+		result = supertokens.ConvertGeneralErrorToJsonResponse(*response.GeneralError)
+	} else {
+		return supertokens.ErrorIfNoResponse(options.Res)
+	}
+
+	return supertokens.Send200Response(options.Res, result)
+}
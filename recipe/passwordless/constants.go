@@ -21,4 +21,5 @@ const (
 	consumeCodeAPI          = "/signinup/code/consume"
 	doesEmailExistAPI       = "/signup/email/exists"
 	doesPhoneNumberExistAPI = "/signup/phonenumber/exists"
+	reauthenticateAPI       = "/signinup/code/reauthenticate"
 )
@@ -42,6 +42,9 @@ type APIInterface struct {
 	ConsumeCodePOST      *func(userInput *UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, options APIOptions, userContext supertokens.UserContext) (ConsumeCodePOSTResponse, error)
 	EmailExistsGET       *func(email string, options APIOptions, userContext supertokens.UserContext) (EmailExistsGETResponse, error)
 	PhoneNumberExistsGET *func(phoneNumber string, options APIOptions, userContext supertokens.UserContext) (PhoneNumberExistsGETResponse, error)
+	// ReauthenticatePOST consumes a code sent to the user of the current session, and updates
+	// the time of their last authentication in the session instead of creating a new one
+	ReauthenticatePOST *func(userInput *UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (ReauthenticatePOSTResponse, error)
 }
 
 type ConsumeCodePOSTResponse struct {
@@ -59,7 +62,28 @@ type ConsumeCodePOSTResponse struct {
 		MaximumCodeInputAttempts    int
 	}
 	RestartFlowError *struct{}
-	GeneralError     *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
+}
+
+type ReauthenticatePOSTResponse struct {
+	OK *struct {
+		User    User
+		Session sessmodels.SessionContainer
+	}
+	IncorrectUserInputCodeError *struct {
+		FailedCodeInputAttemptCount int
+		MaximumCodeInputAttempts    int
+	}
+	ExpiredUserInputCodeError *struct {
+		FailedCodeInputAttemptCount int
+		MaximumCodeInputAttempts    int
+	}
+	RestartFlowError *struct{}
+	// UserMismatchError is returned if the code was not sent to the email or phone number of the user of the session
+	UserMismatchError *struct{}
+	GeneralError      *supertokens.GeneralErrorResponse
 }
 
 type ResendCodePOSTResponse struct {
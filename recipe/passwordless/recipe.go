@@ -130,6 +130,10 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	reauthenticateAPINormalised, err := supertokens.NewNormalisedURLPath(reauthenticateAPI)
+	if err != nil {
+		return nil, err
+	}
 
 	return []supertokens.APIHandled{{
 		Method:                 http.MethodPost,
@@ -156,6 +160,11 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		PathWithoutAPIBasePath: resendCodeAPINormalised,
 		ID:                     resendCodeAPI,
 		Disabled:               r.APIImpl.ResendCodePOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: reauthenticateAPINormalised,
+		ID:                     reauthenticateAPI,
+		Disabled:               r.APIImpl.ReauthenticatePOST == nil,
 	}}, nil
 }
 
@@ -179,6 +188,8 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		return api.DoesEmailExist(r.APIImpl, options)
 	} else if id == doesPhoneNumberExistAPI {
 		return api.DoesPhoneNumberExist(r.APIImpl, options)
+	} else if id == reauthenticateAPI {
+		return api.Reauthenticate(r.APIImpl, options)
 	} else {
 		return api.ResendCode(r.APIImpl, options)
 	}
@@ -218,3 +218,11 @@ func DefaultValidatePhoneNumber(value interface{}) *string {
 // func defaultCreateAndSendCustomTextMessage(phoneNumber string, userInputCode *string, urlWithLinkCode *string, codeLifetime uint64, preAuthSessionId string, userContext supertokens.UserContext) {
 // 	// TODO:
 // }
+
+func incrementSignInUpCounter(createdNewUser bool, userContext supertokens.UserContext) {
+	if createdNewUser {
+		supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameSignUps, supertokens.RecipeIDLogField(RECIPE_ID))
+	} else {
+		supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameSignIns, supertokens.RecipeIDLogField(RECIPE_ID))
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 )
 
 type accessTokenInfoStruct struct {
@@ -41,10 +42,23 @@ func getInfoFromAccessToken(jwtInfo ParsedJWTInfo, jwtSigningPublicKey string, d
 			Msg: err.Error(),
 		}
 	}
+	return getInfoFromVerifiedAccessToken(jwtInfo, doAntiCsrfCheck)
+}
+
+// getInfoFromAccessTokenUsingAnyKey returns nil if the access token is not signed by any of the keys
+func getInfoFromAccessTokenUsingAnyKey(jwtInfo ParsedJWTInfo, keys []sessmodels.KeyInfo, doAntiCsrfCheck bool) (*accessTokenInfoStruct, error) {
+	for _, key := range keys {
+		if verifyJWT(jwtInfo, key.PublicKey) == nil {
+			return getInfoFromVerifiedAccessToken(jwtInfo, doAntiCsrfCheck)
+		}
+	}
+	return nil, nil
+}
 
+func getInfoFromVerifiedAccessToken(jwtInfo ParsedJWTInfo, doAntiCsrfCheck bool) (*accessTokenInfoStruct, error) {
 	payload := jwtInfo.Payload
 	// This should be called before this function, but the check is very quick, so we can also do them here
-	err = validateAccessTokenStructure(payload)
+	err := validateAccessTokenStructure(payload)
 	if err != nil {
 		return nil, errors.TryRefreshTokenError{
 			Msg: err.Error(),
@@ -0,0 +1,98 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func ActiveSessionsAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.ActiveSessionsGET == nil || (*apiImplementation.ActiveSessionsGET) == nil {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	sessionContainer, err := (*options.RecipeImplementation.GetSession)(options.Req, options.Res, nil, userContext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (*apiImplementation.ActiveSessionsGET)(sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if resp.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":   "OK",
+			"sessions": resp.OK.Sessions,
+		})
+	} else if resp.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*resp.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+func RevokeActiveSessionAPI(apiImplementation sessmodels.APIInterface, options sessmodels.APIOptions) error {
+	if apiImplementation.RevokeActiveSessionPOST == nil || (*apiImplementation.RevokeActiveSessionPOST) == nil {
+		options.OtherHandler.ServeHTTP(options.Res, options.Req)
+		return nil
+	}
+
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return err
+	}
+	var readBody map[string]interface{}
+	err = json.Unmarshal(body, &readBody)
+	if err != nil {
+		return err
+	}
+	sessionHandle, ok := readBody["sessionHandle"].(string)
+	if !ok || sessionHandle == "" {
+		return supertokens.BadInputError{Msg: "Please provide the sessionHandle of the session to revoke"}
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	sessionContainer, err := (*options.RecipeImplementation.GetSession)(options.Req, options.Res, nil, userContext)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (*apiImplementation.RevokeActiveSessionPOST)(sessionHandle, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if resp.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+		})
+	} else if resp.UnknownSessionError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "UNKNOWN_SESSION_ERROR",
+		})
+	} else if resp.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*resp.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
@@ -94,9 +94,74 @@ func MakeAPIImplementation() sessmodels.APIInterface {
 		}, nil
 	}
 
+	activeSessionsGET := func(sessionContainer sessmodels.SessionContainer, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.ActiveSessionsGETResponse, error) {
+		sessionHandles, err := (*options.RecipeImplementation.GetAllSessionHandlesForUser)(sessionContainer.GetUserIDWithContext(userContext), userContext)
+		if err != nil {
+			return sessmodels.ActiveSessionsGETResponse{}, err
+		}
+
+		sessions := []sessmodels.ActiveSession{}
+		for _, sessionHandle := range sessionHandles {
+			sessionInformation, err := (*options.RecipeImplementation.GetSessionInformation)(sessionHandle, userContext)
+			if err != nil {
+				return sessmodels.ActiveSessionsGETResponse{}, err
+			}
+			if sessionInformation == nil {
+				// the session was revoked after the handles were fetched
+				continue
+			}
+			sessions = append(sessions, sessmodels.ActiveSession{
+				SessionHandle:    sessionHandle,
+				IsCurrentSession: sessionHandle == sessionContainer.GetHandleWithContext(userContext),
+				TimeCreated:      sessionInformation.TimeCreated,
+				Expiry:           sessionInformation.Expiry,
+				DeviceInfo:       sessionInformation.DeviceInfo,
+			})
+		}
+
+		return sessmodels.ActiveSessionsGETResponse{
+			OK: &struct{ Sessions []sessmodels.ActiveSession }{
+				Sessions: sessions,
+			},
+		}, nil
+	}
+
+	revokeActiveSessionPOST := func(sessionHandle string, sessionContainer sessmodels.SessionContainer, options sessmodels.APIOptions, userContext supertokens.UserContext) (sessmodels.RevokeActiveSessionPOSTResponse, error) {
+		if sessionHandle == sessionContainer.GetHandleWithContext(userContext) {
+			err := sessionContainer.RevokeSessionWithContext(userContext)
+			if err != nil {
+				return sessmodels.RevokeActiveSessionPOSTResponse{}, err
+			}
+			return sessmodels.RevokeActiveSessionPOSTResponse{
+				OK: &struct{}{},
+			}, nil
+		}
+
+		sessionInformation, err := (*options.RecipeImplementation.GetSessionInformation)(sessionHandle, userContext)
+		if err != nil {
+			return sessmodels.RevokeActiveSessionPOSTResponse{}, err
+		}
+		if sessionInformation == nil || sessionInformation.UserId != sessionContainer.GetUserIDWithContext(userContext) {
+			return sessmodels.RevokeActiveSessionPOSTResponse{
+				UnknownSessionError: &struct{}{},
+			}, nil
+		}
+
+		_, err = (*options.RecipeImplementation.RevokeSession)(sessionHandle, userContext)
+		if err != nil {
+			return sessmodels.RevokeActiveSessionPOSTResponse{}, err
+		}
+		return sessmodels.RevokeActiveSessionPOSTResponse{
+			OK: &struct{}{},
+		}, nil
+	}
+
 	return sessmodels.APIInterface{
 		RefreshPOST:   &refreshPOST,
 		VerifySession: &verifySession,
 		SignOutPOST:   &signOutPOST,
+
+		ActiveSessionsGET:       &activeSessionsGET,
+		RevokeActiveSessionPOST: &revokeActiveSessionPOST,
 	}
 }
@@ -1,12 +1,14 @@
 package session
 
 import (
+	defaultErrors "errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 	"github.com/supertokens/supertokens-golang/test/unittesting"
@@ -128,3 +130,41 @@ func TestAssertClaimsWithPayload(t *testing.T) {
 	assert.Equal(t, 1, validateCallCount)
 	assert.Equal(t, accessTokenPayload, validationPayload)
 }
+
+func TestClaimValidatorCombinatorsAgainstFakeCore(t *testing.T) {
+	startFakeCoreForTest(t, nil)
+
+	fetchCount := 0
+	_, numberValidators := claims.NumberClaim("num", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		fetchCount++
+		return 5, nil
+	}, nil)
+	_, booleanValidators := claims.BooleanClaim("bool", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return false, nil
+	}, nil)
+	withValidator := func(validator claims.SessionClaimValidator) *sessmodels.VerifySessionOptions {
+		return &sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				return append(globalClaimValidators, validator), nil
+			},
+		}
+	}
+
+	newSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+
+	// the number claim is fetched through the combinator, since it is not in the payload yet
+	verifiedSession, err := GetSessionWithoutRequestResponse(newSession.GetAccessToken(), nil, withValidator(claims.AllOf(numberValidators.IsAtLeast(5, nil, nil), claims.Not(booleanValidators.IsTrue(nil, nil)))))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetchCount)
+	assert.Equal(t, float64(5), verifiedSession.GetAccessTokenPayload()["num"].(map[string]interface{})["v"])
+
+	_, err = GetSessionWithoutRequestResponse(verifiedSession.GetAllSessionTokensDangerously().AccessToken, nil, withValidator(claims.AnyOf(numberValidators.IsLessThan(5, nil, nil), booleanValidators.IsTrue(nil, nil))))
+	invalidClaimError := errors.InvalidClaimError{}
+	assert.True(t, defaultErrors.As(err, &invalidClaimError))
+	assert.Equal(t, 1, fetchCount)
+	assert.Len(t, invalidClaimError.InvalidClaims, 1)
+	assert.Equal(t, "anyOf(num,bool)", invalidClaimError.InvalidClaims[0].ID)
+	failedValidators := invalidClaimError.InvalidClaims[0].Reason.(map[string]interface{})["failedValidators"].([]claims.ClaimValidationError)
+	assert.Len(t, failedValidators, 2)
+}
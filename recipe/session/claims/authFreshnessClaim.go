@@ -0,0 +1,86 @@
+package claims
+
+import (
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// NewAuthFreshnessClaim returns a claim holding the time of the last primary authentication of the user, in milliseconds.
+// Its value is set when the session is created, and should only be fetched again when the user authenticates again.
+func NewAuthFreshnessClaim() (*TypeSessionClaim, TypeAuthFreshnessClaimValidators) {
+	fetchValue := func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return time.Now().UnixNano() / 1000000, nil
+	}
+	authFreshnessClaim, _ := PrimitiveClaim("st-auth-time", fetchValue, nil)
+
+	getAuthTime := func(payload map[string]interface{}, userContext supertokens.UserContext) *int64 {
+		switch authTime := authFreshnessClaim.GetValueFromPayload(payload, userContext).(type) {
+		case int64:
+			return &authTime
+		case float64:
+			it := int64(authTime)
+			return &it
+		}
+		return nil
+	}
+
+	validators := TypeAuthFreshnessClaimValidators{
+		MaxAuthAge: func(maxAgeInSeconds int64, id *string) SessionClaimValidator {
+			validatorId := authFreshnessClaim.Key
+			if id != nil {
+				validatorId = *id
+			}
+			return SessionClaimValidator{
+				ID:    validatorId,
+				Claim: authFreshnessClaim,
+				// the value can only be updated by authenticating again, so it is never refetched
+				ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+					return false
+				},
+				Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+					authTime := getAuthTime(payload, userContext)
+					if authTime == nil {
+						return ClaimValidationResult{
+							IsValid: false,
+							Reason: map[string]interface{}{
+								"message":         "value does not exist",
+								"maxAgeInSeconds": maxAgeInSeconds,
+							},
+						}
+					}
+					ageInSeconds := (time.Now().UnixNano()/1000000 - *authTime) / 1000
+					if ageInSeconds > maxAgeInSeconds {
+						return ClaimValidationResult{
+							IsValid: false,
+							Reason: map[string]interface{}{
+								"message":         "authentication is too old",
+								"ageInSeconds":    ageInSeconds,
+								"maxAgeInSeconds": maxAgeInSeconds,
+							},
+						}
+					}
+					return ClaimValidationResult{
+						IsValid: true,
+					}
+				},
+			}
+		},
+	}
+
+	return authFreshnessClaim, validators
+}
+
+type TypeAuthFreshnessClaimValidators struct {
+	MaxAuthAge func(maxAgeInSeconds int64, id *string) SessionClaimValidator
+}
+
+// AuthFreshnessClaim is added to every session by the session recipe, and is updated by the re-authentication APIs of the
+// emailpassword, passwordless and thirdparty recipes
+var AuthFreshnessClaim *TypeSessionClaim
+
+var AuthFreshnessClaimValidators TypeAuthFreshnessClaimValidators
+
+func init() {
+	AuthFreshnessClaim, AuthFreshnessClaimValidators = NewAuthFreshnessClaim()
+}
@@ -0,0 +1,32 @@
+package claims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthFreshnessClaim(t *testing.T) {
+	authFreshnessClaim, validators := NewAuthFreshnessClaim()
+
+	payload, err := authFreshnessClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, validators.MaxAuthAge(60, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.MaxAuthAge(60, nil).ShouldRefetch(payload, nil))
+
+	// the value read from an access token is a float64
+	payload = authFreshnessClaim.AddToPayload_internal(map[string]interface{}{}, float64(time.Now().Add(-2*time.Minute).UnixNano()/1000000), nil)
+	result := validators.MaxAuthAge(60, nil).Validate(payload, nil)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, "authentication is too old", result.Reason.(map[string]interface{})["message"])
+	assert.Equal(t, int64(120), result.Reason.(map[string]interface{})["ageInSeconds"])
+	assert.True(t, validators.MaxAuthAge(300, nil).Validate(payload, nil).IsValid)
+
+	result = validators.MaxAuthAge(60, nil).Validate(map[string]interface{}{}, nil)
+	assert.False(t, result.IsValid)
+	assert.False(t, validators.MaxAuthAge(60, nil).ShouldRefetch(map[string]interface{}{}, nil))
+
+	id := "custom-id"
+	assert.Equal(t, "custom-id", validators.MaxAuthAge(60, &id).ID)
+}
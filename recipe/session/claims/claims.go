@@ -48,6 +48,9 @@ type SessionClaimValidator struct {
 	Claim         *TypeSessionClaim
 	ShouldRefetch func(payload map[string]interface{}, userContext supertokens.UserContext) bool
 	Validate      func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult
+	// Validators holds the validators combined by AnyOf, AllOf and Not, which have no Claim. The claims
+	// of these validators are refetched if both the combined validator and them should be refetched.
+	Validators []SessionClaimValidator
 }
 
 type ClaimValidationResult struct {
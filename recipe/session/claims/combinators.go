@@ -0,0 +1,127 @@
+package claims
+
+import (
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// AnyOf returns a validator that passes if at least one of validators passes. Its ID is made
+// of the IDs of validators (like "anyOf(st-role,st-perm)"), and can be changed on the returned value.
+// If none of them passes, the reason holds a ClaimValidationError for each of them.
+func AnyOf(validators ...SessionClaimValidator) SessionClaimValidator {
+	return SessionClaimValidator{
+		ID:         getCombinedValidatorID("anyOf", validators),
+		Validators: validators,
+		// the claims are only refetched if no validator already passes with an up to date value
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			for _, validator := range validators {
+				if !shouldRefetch(validator, payload, userContext) && validator.Validate(payload, userContext).IsValid {
+					return false
+				}
+			}
+			return true
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+			failedValidators := []ClaimValidationError{}
+			for _, validator := range validators {
+				result := validator.Validate(payload, userContext)
+				if result.IsValid {
+					return ClaimValidationResult{
+						IsValid: true,
+					}
+				}
+				failedValidators = append(failedValidators, ClaimValidationError{
+					ID:     validator.ID,
+					Reason: result.Reason,
+				})
+			}
+			return ClaimValidationResult{
+				IsValid: false,
+				Reason: map[string]interface{}{
+					"message":          "none of the validators passed",
+					"failedValidators": failedValidators,
+				},
+			}
+		},
+	}
+}
+
+// AllOf returns a validator that passes if all of validators pass. Its ID is made of
+// the IDs of validators (like "allOf(st-role,st-perm)"), and can be changed on the returned value.
+// If some of them fail, the reason holds a ClaimValidationError for each of them.
+func AllOf(validators ...SessionClaimValidator) SessionClaimValidator {
+	return SessionClaimValidator{
+		ID:         getCombinedValidatorID("allOf", validators),
+		Validators: validators,
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			for _, validator := range validators {
+				if shouldRefetch(validator, payload, userContext) {
+					return true
+				}
+			}
+			return false
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+			failedValidators := []ClaimValidationError{}
+			for _, validator := range validators {
+				result := validator.Validate(payload, userContext)
+				if !result.IsValid {
+					failedValidators = append(failedValidators, ClaimValidationError{
+						ID:     validator.ID,
+						Reason: result.Reason,
+					})
+				}
+			}
+			if len(failedValidators) > 0 {
+				return ClaimValidationResult{
+					IsValid: false,
+					Reason: map[string]interface{}{
+						"message":          "some of the validators did not pass",
+						"failedValidators": failedValidators,
+					},
+				}
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		},
+	}
+}
+
+// Not returns a validator that passes if validator fails. Its ID is "not(" followed by the ID of validator and ")".
+func Not(validator SessionClaimValidator) SessionClaimValidator {
+	return SessionClaimValidator{
+		ID:         getCombinedValidatorID("not", []SessionClaimValidator{validator}),
+		Validators: []SessionClaimValidator{validator},
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			return shouldRefetch(validator, payload, userContext)
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+			if validator.Validate(payload, userContext).IsValid {
+				return ClaimValidationResult{
+					IsValid: false,
+					Reason: map[string]interface{}{
+						"message":          "the validator passed",
+						"passedValidators": []ClaimValidationError{{ID: validator.ID}},
+					},
+				}
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		},
+	}
+}
+
+func shouldRefetch(validator SessionClaimValidator, payload map[string]interface{}, userContext supertokens.UserContext) bool {
+	return validator.ShouldRefetch != nil && validator.ShouldRefetch(payload, userContext)
+}
+
+func getCombinedValidatorID(combinator string, validators []SessionClaimValidator) string {
+	ids := []string{}
+	for _, validator := range validators {
+		ids = append(ids, validator.ID)
+	}
+	return combinator + "(" + strings.Join(ids, ",") + ")"
+}
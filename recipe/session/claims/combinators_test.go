@@ -0,0 +1,53 @@
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestCombinators(t *testing.T) {
+	booleanClaim, booleanValidators := BooleanClaim("bool", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return true, nil
+	}, nil)
+	_, numberValidators := NumberClaim("num", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return 5, nil
+	}, nil)
+
+	// only the boolean claim is in the payload
+	payload, err := booleanClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+
+	isTrue := booleanValidators.IsTrue(nil, nil)
+	isFalse := booleanValidators.IsFalse(nil, nil)
+	isPositive := numberValidators.IsGreaterThan(0, nil, nil)
+
+	anyOf := AnyOf(isFalse, isTrue)
+	assert.Equal(t, "anyOf(bool,bool)", anyOf.ID)
+	assert.True(t, anyOf.Validate(payload, nil).IsValid)
+	assert.False(t, AnyOf(isFalse, isPositive).Validate(payload, nil).IsValid)
+
+	allOf := AllOf(isTrue, isPositive)
+	result := allOf.Validate(payload, nil)
+	assert.False(t, result.IsValid)
+	reason := result.Reason.(map[string]interface{})
+	assert.Equal(t, "some of the validators did not pass", reason["message"])
+	failedValidators := reason["failedValidators"].([]ClaimValidationError)
+	assert.Len(t, failedValidators, 1)
+	assert.Equal(t, "num", failedValidators[0].ID)
+	assert.True(t, AllOf(isTrue, Not(isFalse)).Validate(payload, nil).IsValid)
+
+	not := Not(isTrue)
+	assert.Equal(t, "not(bool)", not.ID)
+	assert.False(t, not.Validate(payload, nil).IsValid)
+	assert.True(t, Not(isPositive).Validate(payload, nil).IsValid)
+
+	// the number claim is missing, so it should be refetched unless another validator passes with an up to date value
+	assert.False(t, AnyOf(isTrue, isPositive).ShouldRefetch(payload, nil))
+	assert.True(t, AnyOf(isFalse, isPositive).ShouldRefetch(payload, nil))
+	assert.True(t, AllOf(isTrue, isPositive).ShouldRefetch(payload, nil))
+	assert.False(t, AllOf(isTrue, isFalse).ShouldRefetch(payload, nil))
+	assert.True(t, Not(isPositive).ShouldRefetch(payload, nil))
+	assert.True(t, AllOf(AnyOf(isFalse, isPositive)).ShouldRefetch(payload, nil))
+}
@@ -0,0 +1,82 @@
+package claims
+
+import (
+	"fmt"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// EnumClaim returns a claim holding one of values. Fetching any other value fails, so that
+// the claim is never added to a payload with a value the validators do not expect.
+func EnumClaim(key string, values []string, fetchValue FetchValueFunc, defaultMaxAgeInSeconds *int64) (*TypeSessionClaim, EnumClaimValidators) {
+	isEnumValue := func(value string) bool {
+		for _, enumValue := range values {
+			if value == enumValue {
+				return true
+			}
+		}
+		return false
+	}
+
+	fetchEnumValue := func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		value, err := fetchValue(userId, userContext)
+		if err != nil || value == nil {
+			return value, err
+		}
+		if stringValue, ok := value.(string); !ok || !isEnumValue(stringValue) {
+			return nil, fmt.Errorf("the value of the %s claim must be one of %v, got %v", key, values, value)
+		}
+		return value, nil
+	}
+
+	sessionClaim, primitiveClaimValidators := PrimitiveClaim(key, fetchEnumValue, defaultMaxAgeInSeconds)
+
+	makeValidator := func(maxAgeInSeconds *int64, id *string, expectation map[string]interface{}, isValid func(value string) bool) SessionClaimValidator {
+		if maxAgeInSeconds == nil {
+			maxAgeInSeconds = defaultMaxAgeInSeconds
+		}
+		return makeClaimValidator(sessionClaim, maxAgeInSeconds, id, expectation, func(claimVal interface{}) ClaimValidationResult {
+			value, ok := claimVal.(string)
+			if !ok || !isValid(value) {
+				return wrongValue(claimVal, expectation)
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		})
+	}
+
+	validators := EnumClaimValidators{
+		PrimitiveClaimValidators: primitiveClaimValidators,
+
+		IsOneOf: func(vals []string, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeOneOf": vals}, func(value string) bool {
+				for _, val := range vals {
+					if value == val {
+						return true
+					}
+				}
+				return false
+			})
+		},
+
+		IsNotOneOf: func(vals []string, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedNotToBeOneOf": vals}, func(value string) bool {
+				for _, val := range vals {
+					if value == val {
+						return false
+					}
+				}
+				return true
+			})
+		},
+	}
+
+	return sessionClaim, validators
+}
+
+type EnumClaimValidators struct {
+	PrimitiveClaimValidators
+	IsOneOf    func(vals []string, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	IsNotOneOf func(vals []string, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+}
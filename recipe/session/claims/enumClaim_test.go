@@ -0,0 +1,33 @@
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestEnumClaimValidators(t *testing.T) {
+	value := "pro"
+	enumClaim, validators := EnumClaim(
+		"test",
+		[]string{"free", "pro", "enterprise"},
+		func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+			return value, nil
+		},
+		nil,
+	)
+
+	payload, err := enumClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, validators.IsOneOf([]string{"pro", "enterprise"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsOneOf([]string{"enterprise"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsNotOneOf([]string{"free"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsNotOneOf([]string{"free", "pro"}, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.HasValue("pro", nil, nil).Validate(payload, nil).IsValid)
+
+	value = "unknown"
+	_, err = enumClaim.Build("userId", nil, nil)
+	assert.Error(t, err)
+}
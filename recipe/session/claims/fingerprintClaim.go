@@ -0,0 +1,82 @@
+package claims
+
+import (
+	"sort"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// FingerprintClaim returns a claim binding a session to the client it was created by. getFingerprint returns the
+// fingerprint of the client of the current request, mapping the name of each characteristic it is made of (like its
+// IP subnet or user agent) to a hash of its value, or nil if there is no request. The fingerprint is taken when the
+// session is created and is never refetched, since refetching it would bind the session to the current client.
+func FingerprintClaim(key string, getFingerprint func(userContext supertokens.UserContext) map[string]interface{}) (*TypeSessionClaim, FingerprintClaimValidators) {
+	fetchValue := func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		fingerprint := getFingerprint(userContext)
+		if fingerprint == nil {
+			return nil, nil
+		}
+		return fingerprint, nil
+	}
+	sessionClaim, _ := PrimitiveClaim(key, fetchValue, nil)
+
+	validators := FingerprintClaimValidators{
+		MatchesRequest: func(id *string) SessionClaimValidator {
+			validatorId := sessionClaim.Key
+			if id != nil {
+				validatorId = *id
+			}
+			return SessionClaimValidator{
+				ID:    validatorId,
+				Claim: sessionClaim,
+				ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+					return false
+				},
+				Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+					mismatches := getFingerprintMismatches(sessionClaim, getFingerprint, payload, userContext)
+					if len(mismatches) > 0 {
+						return ClaimValidationResult{
+							IsValid: false,
+							Reason: map[string]interface{}{
+								"message":    "the client does not match the fingerprint of the session",
+								"mismatches": mismatches,
+							},
+						}
+					}
+					return ClaimValidationResult{
+						IsValid: true,
+					}
+				},
+			}
+		},
+	}
+
+	return sessionClaim, validators
+}
+
+type FingerprintClaimValidators struct {
+	// MatchesRequest passes if the fingerprint of the client of the current request matches the one of the session.
+	// It also passes for the sessions that have no fingerprint, and if there is no request to compare with.
+	MatchesRequest func(id *string) SessionClaimValidator
+}
+
+// getFingerprintMismatches returns the sorted names of the characteristics of the fingerprint in payload that do
+// not match the ones of the current request
+func getFingerprintMismatches(fingerprintClaim *TypeSessionClaim, getFingerprint func(userContext supertokens.UserContext) map[string]interface{}, payload map[string]interface{}, userContext supertokens.UserContext) []string {
+	mismatches := []string{}
+	sessionFingerprint, ok := fingerprintClaim.GetValueFromPayload(payload, userContext).(map[string]interface{})
+	if !ok {
+		return mismatches
+	}
+	requestFingerprint := getFingerprint(userContext)
+	if requestFingerprint == nil {
+		return mismatches
+	}
+	for name, value := range sessionFingerprint {
+		if requestFingerprint[name] != value {
+			mismatches = append(mismatches, name)
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
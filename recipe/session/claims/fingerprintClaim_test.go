@@ -0,0 +1,49 @@
+package claims
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestFingerprintClaim(t *testing.T) {
+	var requestFingerprint map[string]interface{}
+	fingerprintClaim, validators := FingerprintClaim("st-fp", func(userContext supertokens.UserContext) map[string]interface{} {
+		return requestFingerprint
+	})
+	validator := validators.MatchesRequest(nil)
+	assert.Equal(t, "st-fp", validator.ID)
+
+	// without a request, the session is not bound
+	payload, err := fingerprintClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, payload)
+	requestFingerprint = map[string]interface{}{"ip": "a", "ua": "b"}
+	assert.True(t, validator.Validate(payload, nil).IsValid)
+
+	payload, err = fingerprintClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	assert.True(t, validator.Validate(payload, nil).IsValid)
+	assert.False(t, validator.ShouldRefetch(payload, nil))
+
+	// the fingerprint read from an access token is compared the same way
+	payloadJSON, err := json.Marshal(payload)
+	assert.NoError(t, err)
+	var payloadFromAccessToken map[string]interface{}
+	assert.NoError(t, json.Unmarshal(payloadJSON, &payloadFromAccessToken))
+	assert.True(t, validator.Validate(payloadFromAccessToken, nil).IsValid)
+
+	requestFingerprint = map[string]interface{}{"ip": "c", "ua": "d"}
+	result := validator.Validate(payloadFromAccessToken, nil)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, "the client does not match the fingerprint of the session", result.Reason.(map[string]interface{})["message"])
+	assert.Equal(t, []string{"ip", "ua"}, result.Reason.(map[string]interface{})["mismatches"])
+
+	requestFingerprint = nil
+	assert.True(t, validator.Validate(payloadFromAccessToken, nil).IsValid)
+
+	id := "custom-id"
+	assert.Equal(t, "custom-id", validators.MatchesRequest(&id).ID)
+}
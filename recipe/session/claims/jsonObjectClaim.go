@@ -0,0 +1,118 @@
+package claims
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// JSONObjectClaim returns a claim holding a JSON object. Its validators take the path of a value in the object,
+// made of the keys leading to it separated by dots, with the indexes of arrays as keys (for example "org.teams.0.id").
+func JSONObjectClaim(key string, fetchValue FetchValueFunc, defaultMaxAgeInSeconds *int64) (*TypeSessionClaim, JSONObjectClaimValidators) {
+	sessionClaim, _ := PrimitiveClaim(key, fetchValue, defaultMaxAgeInSeconds)
+
+	addToPayload := sessionClaim.AddToPayload_internal
+	sessionClaim.AddToPayload_internal = func(payload map[string]interface{}, value interface{}, userContext supertokens.UserContext) map[string]interface{} {
+		// the value is stored as it would be read from an access token, so that the validators
+		// do not depend on the types used by fetchValue (like structs or typed maps)
+		if valueJSON, err := json.Marshal(value); err == nil {
+			var jsonValue interface{}
+			if err := json.Unmarshal(valueJSON, &jsonValue); err == nil {
+				value = jsonValue
+			}
+		}
+		return addToPayload(payload, value, userContext)
+	}
+
+	makeValidator := func(path string, maxAgeInSeconds *int64, id *string, expectation map[string]interface{}, isValid func(value interface{}, exists bool) bool) SessionClaimValidator {
+		if maxAgeInSeconds == nil {
+			maxAgeInSeconds = defaultMaxAgeInSeconds
+		}
+		expectation["path"] = path
+		return makeClaimValidator(sessionClaim, maxAgeInSeconds, id, expectation, func(claimVal interface{}) ClaimValidationResult {
+			value, exists := getValueAtPath(claimVal, path)
+			if !isValid(value, exists) {
+				return wrongValue(value, expectation)
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		})
+	}
+
+	validators := JSONObjectClaimValidators{
+		HasPath: func(path string, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(path, maxAgeInSeconds, id, map[string]interface{}{"expectedToExist": true}, func(value interface{}, exists bool) bool {
+				return exists
+			})
+		},
+
+		HasValueAtPath: func(path string, val interface{}, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(path, maxAgeInSeconds, id, map[string]interface{}{"expectedValue": val}, func(value interface{}, exists bool) bool {
+				return exists && isEqualJSONValue(value, val)
+			})
+		},
+
+		IncludesAtPath: func(path string, val interface{}, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(path, maxAgeInSeconds, id, map[string]interface{}{"expectedToInclude": val}, func(value interface{}, exists bool) bool {
+				array, ok := value.([]interface{})
+				if !exists || !ok {
+					return false
+				}
+				for _, item := range array {
+					if isEqualJSONValue(item, val) {
+						return true
+					}
+				}
+				return false
+			})
+		},
+	}
+
+	return sessionClaim, validators
+}
+
+type JSONObjectClaimValidators struct {
+	HasPath        func(path string, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	HasValueAtPath func(path string, val interface{}, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	// IncludesAtPath checks that the value at path is an array including val
+	IncludesAtPath func(path string, val interface{}, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+}
+
+func getValueAtPath(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch container := value.(type) {
+		case map[string]interface{}:
+			child, ok := container[key]
+			if !ok {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, false
+			}
+			value = container[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// isEqualJSONValue compares a value read from a JSON object with val, comparing numbers by their value
+func isEqualJSONValue(value interface{}, val interface{}) bool {
+	number, isNumber := toFloat64(value)
+	valNumber, isValNumber := toFloat64(val)
+	if isNumber && isValNumber {
+		return number == valNumber
+	}
+	return reflect.DeepEqual(value, val)
+}
@@ -0,0 +1,52 @@
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestJSONObjectClaimValidators(t *testing.T) {
+	type team struct {
+		ID    string   `json:"id"`
+		Size  int      `json:"size"`
+		Roles []string `json:"roles"`
+	}
+	jsonObjectClaim, validators := JSONObjectClaim(
+		"test",
+		func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+			return map[string]interface{}{
+				"org": map[string]interface{}{
+					"id":    "org1",
+					"teams": []team{{ID: "team1", Size: 3, Roles: []string{"admin"}}},
+				},
+			}, nil
+		},
+		nil,
+	)
+
+	payload, err := jsonObjectClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, validators.HasPath("org.id", nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.HasPath("org.teams.0.roles", nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.HasPath("org.teams.1", nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.HasPath("org.id.value", nil, nil).Validate(payload, nil).IsValid)
+
+	assert.True(t, validators.HasValueAtPath("org.id", "org1", nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.HasValueAtPath("org.teams.0.size", 3, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.HasValueAtPath("org.teams.0.size", 4, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.HasValueAtPath("org.name", "org1", nil, nil).Validate(payload, nil).IsValid)
+
+	assert.True(t, validators.IncludesAtPath("org.teams.0.roles", "admin", nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IncludesAtPath("org.teams.0.roles", "owner", nil, nil).Validate(payload, nil).IsValid)
+
+	result := validators.HasValueAtPath("org.id", "org2", nil, nil).Validate(payload, nil)
+	assert.Equal(t, map[string]interface{}{
+		"message":       "wrong value",
+		"actualValue":   "org1",
+		"expectedValue": "org2",
+		"path":          "org.id",
+	}, result.Reason)
+}
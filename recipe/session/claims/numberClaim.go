@@ -0,0 +1,68 @@
+package claims
+
+// NumberClaim returns a claim holding a number. Numbers read from an access token are float64,
+// so the validators compare the value of the claim as a float64.
+func NumberClaim(key string, fetchValue FetchValueFunc, defaultMaxAgeInSeconds *int64) (*TypeSessionClaim, NumberClaimValidators) {
+	sessionClaim, primitiveClaimValidators := PrimitiveClaim(key, fetchValue, defaultMaxAgeInSeconds)
+
+	makeValidator := func(maxAgeInSeconds *int64, id *string, expectation map[string]interface{}, isValid func(number float64) bool) SessionClaimValidator {
+		if maxAgeInSeconds == nil {
+			maxAgeInSeconds = defaultMaxAgeInSeconds
+		}
+		return makeClaimValidator(sessionClaim, maxAgeInSeconds, id, expectation, func(claimVal interface{}) ClaimValidationResult {
+			number, ok := toFloat64(claimVal)
+			if !ok || !isValid(number) {
+				return wrongValue(claimVal, expectation)
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		})
+	}
+
+	validators := NumberClaimValidators{
+		PrimitiveClaimValidators: primitiveClaimValidators,
+
+		IsGreaterThan: func(min float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeGreaterThan": min}, func(number float64) bool {
+				return number > min
+			})
+		},
+
+		IsAtLeast: func(min float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeAtLeast": min}, func(number float64) bool {
+				return number >= min
+			})
+		},
+
+		IsLessThan: func(max float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeLessThan": max}, func(number float64) bool {
+				return number < max
+			})
+		},
+
+		IsAtMost: func(max float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeAtMost": max}, func(number float64) bool {
+				return number <= max
+			})
+		},
+
+		IsBetween: func(min float64, max float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeAtLeast": min, "expectedToBeAtMost": max}, func(number float64) bool {
+				return number >= min && number <= max
+			})
+		},
+	}
+
+	return sessionClaim, validators
+}
+
+type NumberClaimValidators struct {
+	PrimitiveClaimValidators
+	IsGreaterThan func(min float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	IsAtLeast     func(min float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	IsLessThan    func(max float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	IsAtMost      func(max float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	// IsBetween checks that the value is in the [min, max] range, bounds included
+	IsBetween func(min float64, max float64, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+}
@@ -0,0 +1,48 @@
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestNumberClaimValidators(t *testing.T) {
+	numberClaim, validators := NumberClaim(
+		"test",
+		func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+			return 10, nil
+		},
+		nil,
+	)
+
+	payload, err := numberClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, validators.IsGreaterThan(9, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsGreaterThan(10, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsAtLeast(10, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsAtLeast(10.5, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsLessThan(11, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsLessThan(10, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsAtMost(10, nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsAtMost(9, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsBetween(10, 20, nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsBetween(0, 10, nil, nil).Validate(payload, nil).IsValid)
+
+	result := validators.IsBetween(11, 20, nil, nil).Validate(payload, nil)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, map[string]interface{}{
+		"message":             "wrong value",
+		"actualValue":         10,
+		"expectedToBeAtLeast": float64(11),
+		"expectedToBeAtMost":  float64(20),
+	}, result.Reason)
+
+	// the value read from an access token is a float64
+	payload = numberClaim.AddToPayload_internal(map[string]interface{}{}, float64(10), nil)
+	assert.True(t, validators.IsBetween(10, 20, nil, nil).Validate(payload, nil).IsValid)
+
+	payload = numberClaim.AddToPayload_internal(map[string]interface{}{}, "10", nil)
+	assert.False(t, validators.IsAtLeast(0, nil, nil).Validate(payload, nil).IsValid)
+}
@@ -0,0 +1,71 @@
+package claims
+
+import (
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// TimestampClaim returns a claim holding a point in time, stored in the payload in milliseconds since the epoch.
+// fetchValue can return a time.Time or a number of milliseconds.
+func TimestampClaim(key string, fetchValue FetchValueFunc, defaultMaxAgeInSeconds *int64) (*TypeSessionClaim, TimestampClaimValidators) {
+	sessionClaim, primitiveClaimValidators := PrimitiveClaim(key, fetchValue, defaultMaxAgeInSeconds)
+
+	addToPayload := sessionClaim.AddToPayload_internal
+	sessionClaim.AddToPayload_internal = func(payload map[string]interface{}, value interface{}, userContext supertokens.UserContext) map[string]interface{} {
+		if timestamp, ok := value.(time.Time); ok {
+			value = timestamp.UnixNano() / 1000000
+		}
+		return addToPayload(payload, value, userContext)
+	}
+
+	makeValidator := func(maxAgeInSeconds *int64, id *string, expectation map[string]interface{}, isValid func(timestamp float64) bool) SessionClaimValidator {
+		if maxAgeInSeconds == nil {
+			maxAgeInSeconds = defaultMaxAgeInSeconds
+		}
+		return makeClaimValidator(sessionClaim, maxAgeInSeconds, id, expectation, func(claimVal interface{}) ClaimValidationResult {
+			timestamp, ok := toFloat64(claimVal)
+			if !ok || !isValid(timestamp) {
+				return wrongValue(claimVal, expectation)
+			}
+			return ClaimValidationResult{
+				IsValid: true,
+			}
+		})
+	}
+
+	validators := TimestampClaimValidators{
+		PrimitiveClaimValidators: primitiveClaimValidators,
+
+		IsAfter: func(after time.Time, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			afterInMS := after.UnixNano() / 1000000
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeAfter": afterInMS}, func(timestamp float64) bool {
+				return timestamp > float64(afterInMS)
+			})
+		},
+
+		IsBefore: func(before time.Time, maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			beforeInMS := before.UnixNano() / 1000000
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeBefore": beforeInMS}, func(timestamp float64) bool {
+				return timestamp < float64(beforeInMS)
+			})
+		},
+
+		NotExpired: func(maxAgeInSeconds *int64, id *string) SessionClaimValidator {
+			// the current time is only known when validating, so the reason only refers to it as "now"
+			return makeValidator(maxAgeInSeconds, id, map[string]interface{}{"expectedToBeAfter": "now"}, func(timestamp float64) bool {
+				return timestamp > float64(time.Now().UnixNano()/1000000)
+			})
+		},
+	}
+
+	return sessionClaim, validators
+}
+
+type TimestampClaimValidators struct {
+	PrimitiveClaimValidators
+	IsAfter  func(after time.Time, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	IsBefore func(before time.Time, maxAgeInSeconds *int64, id *string) SessionClaimValidator
+	// NotExpired checks that the value of the claim, which is an expiry time, is in the future
+	NotExpired func(maxAgeInSeconds *int64, id *string) SessionClaimValidator
+}
@@ -0,0 +1,60 @@
+package claims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestTimestampClaimValidators(t *testing.T) {
+	expiry := time.Now().Add(time.Hour)
+	timestampClaim, validators := TimestampClaim(
+		"test",
+		func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+			return expiry, nil
+		},
+		nil,
+	)
+
+	payload, err := timestampClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expiry.UnixNano()/1000000, timestampClaim.GetValueFromPayload(payload, nil))
+
+	assert.True(t, validators.IsAfter(time.Now(), nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsAfter(expiry.Add(time.Minute), nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.IsBefore(expiry.Add(time.Minute), nil, nil).Validate(payload, nil).IsValid)
+	assert.False(t, validators.IsBefore(time.Now(), nil, nil).Validate(payload, nil).IsValid)
+	assert.True(t, validators.NotExpired(nil, nil).Validate(payload, nil).IsValid)
+
+	// the value read from an access token is a float64
+	payload = timestampClaim.AddToPayload_internal(map[string]interface{}{}, float64(time.Now().Add(-time.Minute).UnixNano()/1000000), nil)
+	result := validators.NotExpired(nil, nil).Validate(payload, nil)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, "wrong value", result.Reason.(map[string]interface{})["message"])
+
+	assert.True(t, validators.NotExpired(nil, nil).ShouldRefetch(map[string]interface{}{}, nil))
+	assert.False(t, validators.NotExpired(nil, nil).Validate(map[string]interface{}{}, nil).IsValid)
+}
+
+func TestTimestampClaimMaxAge(t *testing.T) {
+	timestampClaim, validators := TimestampClaim(
+		"test",
+		func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+			return time.Now().Add(time.Hour), nil
+		},
+		nil,
+	)
+
+	payload, err := timestampClaim.Build("userId", nil, nil)
+	assert.NoError(t, err)
+	payload["test"].(map[string]interface{})["t"] = time.Now().Add(-time.Minute).UnixNano() / 1000000
+
+	maxAge := int64(30)
+	assert.True(t, validators.NotExpired(&maxAge, nil).ShouldRefetch(payload, nil))
+	result := validators.NotExpired(&maxAge, nil).Validate(payload, nil)
+	assert.False(t, result.IsValid)
+	assert.Equal(t, "expired", result.Reason.(map[string]interface{})["message"])
+	assert.False(t, validators.NotExpired(nil, nil).ShouldRefetch(payload, nil))
+}
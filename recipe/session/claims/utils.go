@@ -1,5 +1,11 @@
 package claims
 
+import (
+	"time"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
 func includes(s []interface{}, e interface{}) bool {
 	for _, a := range s {
 		if a == e {
@@ -34,3 +40,119 @@ func excludesAll(s []interface{}, e []interface{}) bool {
 	}
 	return true
 }
+
+func getLastRefetchTime(key string, payload map[string]interface{}) *int64 {
+	if value, ok := payload[key].(map[string]interface{}); ok {
+		switch t := value["t"].(type) {
+		case int64:
+			return &t
+		case float64:
+			it := int64(t)
+			return &it
+		}
+	}
+	return nil
+}
+
+// toFloat64 converts the numbers set by the app (of any numeric type) and the ones read from
+// an access token (float64) to float64, so that they can be compared
+func toFloat64(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case int8:
+		return float64(number), true
+	case int16:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case uint:
+		return float64(number), true
+	case uint8:
+		return float64(number), true
+	case uint16:
+		return float64(number), true
+	case uint32:
+		return float64(number), true
+	case uint64:
+		return float64(number), true
+	case float32:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+	return 0, false
+}
+
+// makeClaimValidator returns a validator of claim that refetches its value if it does not exist or is older than
+// maxAgeInSeconds, and checks it using validateValue otherwise. expectation is added to the reason if there is no value.
+func makeClaimValidator(claim *TypeSessionClaim, maxAgeInSeconds *int64, id *string, expectation map[string]interface{}, validateValue func(claimVal interface{}) ClaimValidationResult) SessionClaimValidator {
+	validatorId := claim.Key
+	if id != nil {
+		validatorId = *id
+	}
+	return SessionClaimValidator{
+		ID:    validatorId,
+		Claim: claim,
+		ShouldRefetch: func(payload map[string]interface{}, userContext supertokens.UserContext) bool {
+			if claim.GetValueFromPayload(payload, userContext) == nil {
+				return true
+			}
+			if maxAgeInSeconds == nil {
+				return false
+			}
+			lastRefetchTime := getLastRefetchTime(claim.Key, payload)
+			return lastRefetchTime == nil || *lastRefetchTime < time.Now().UnixNano()/1000000-*maxAgeInSeconds*1000
+		},
+		Validate: func(payload map[string]interface{}, userContext supertokens.UserContext) ClaimValidationResult {
+			claimVal := claim.GetValueFromPayload(payload, userContext)
+			if claimVal == nil {
+				reason := map[string]interface{}{
+					"message":     "value does not exist",
+					"actualValue": claimVal,
+				}
+				for key, value := range expectation {
+					reason[key] = value
+				}
+				return ClaimValidationResult{
+					IsValid: false,
+					Reason:  reason,
+				}
+			}
+			if maxAgeInSeconds != nil {
+				lastRefetchTime := getLastRefetchTime(claim.Key, payload)
+				if lastRefetchTime != nil {
+					ageInSeconds := (time.Now().UnixNano()/1000000 - *lastRefetchTime) / 1000
+					if ageInSeconds > *maxAgeInSeconds {
+						return ClaimValidationResult{
+							IsValid: false,
+							Reason: map[string]interface{}{
+								"message":         "expired",
+								"ageInSeconds":    ageInSeconds,
+								"maxAgeInSeconds": *maxAgeInSeconds,
+							},
+						}
+					}
+				}
+			}
+			return validateValue(claimVal)
+		},
+	}
+}
+
+// wrongValue returns the result of a validator for a claimVal that does not match expectation
+func wrongValue(claimVal interface{}, expectation map[string]interface{}) ClaimValidationResult {
+	reason := map[string]interface{}{
+		"message":     "wrong value",
+		"actualValue": claimVal,
+	}
+	for key, value := range expectation {
+		reason[key] = value
+	}
+	return ClaimValidationResult{
+		IsValid: false,
+		Reason:  reason,
+	}
+}
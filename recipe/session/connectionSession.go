@@ -0,0 +1,163 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// ConnectionSession is the session of a long-lived connection, like a websocket. Since the connection
+// outlives the access token it was opened with, the server should call Revalidate periodically (or use
+// StartRevalidation), and the client should send its refreshed access tokens to be passed to UpdateAccessToken.
+type ConnectionSession struct {
+	mutex   sync.Mutex
+	session sessmodels.SessionContainer
+	options *sessmodels.VerifySessionOptions
+}
+
+// GetConnectionSessionWithContext verifies the session of the request opening a long-lived connection, like a
+// websocket upgrade request, using the cookie or header transfer method. options are also used when the session is
+// revalidated or its access token is updated. The anti-csrf token is not checked for GET requests, so the Origin
+// header of upgrade requests using cookies should be checked by the server.
+func GetConnectionSessionWithContext(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (*ConnectionSession, error) {
+	sessionContainer, err := GetSessionWithContext(req, res, options, userContext)
+	if err != nil {
+		return nil, err
+	}
+	if sessionContainer == nil {
+		return nil, nil
+	}
+	return &ConnectionSession{
+		session: sessionContainer,
+		options: options,
+	}, nil
+}
+
+// GetSession returns the session of the connection, which changes when its access token is updated. It waits for
+// the ongoing revalidation of the connection, if any, since the claims of the session can be updated by it.
+func (connectionSession *ConnectionSession) GetSession() sessmodels.SessionContainer {
+	connectionSession.mutex.Lock()
+	defer connectionSession.mutex.Unlock()
+	return connectionSession.session
+}
+
+// GetAccessTokenExpiry returns the time the access token of the connection expires at. The client
+// should send a refreshed access token before then, or the connection will fail to be revalidated.
+func (connectionSession *ConnectionSession) GetAccessTokenExpiry() time.Time {
+	expiry := connectionSession.GetSession().GetAllSessionTokensDangerously().AccessTokenExpiry
+	return time.Unix(0, int64(expiry)*int64(time.Millisecond))
+}
+
+// RevalidateWithContext checks that the access token of the connection has not expired, that its session was not
+// revoked, and that its claims are still valid. It returns the same errors as GetSession, or an UnauthorizedError
+// whose reason is SessionRevokedReason if the session was revoked. Claims refetched while revalidating change the
+// access token of the session, which can be sent to the client using GetAllSessionTokensDangerously.
+func (connectionSession *ConnectionSession) RevalidateWithContext(userContext supertokens.UserContext) error {
+	// the lock is held until the claims are validated, so that the session is neither replaced nor used
+	// while they are refetched
+	connectionSession.mutex.Lock()
+	defer connectionSession.mutex.Unlock()
+	sessionContainer := connectionSession.session
+	if sessionContainer.GetAllSessionTokensDangerouslyWithContext(userContext).AccessTokenExpiry < getCurrTimeInMS() {
+		supertokens.LogDebugMessage("revalidateConnectionSession: Returning TRY_REFRESH_TOKEN because the access token expired")
+		return errors.TryRefreshTokenError{Msg: "Access token has expired. Please send a refreshed access token"}
+	}
+
+	sessionInformation, err := GetSessionInformationWithContext(sessionContainer.GetHandleWithContext(userContext), userContext)
+	if err != nil {
+		return err
+	}
+	if sessionInformation == nil {
+		supertokens.LogDebugMessage("revalidateConnectionSession: Returning UNAUTHORISED because the session was revoked", supertokens.SessionHandleLogField(sessionContainer.GetHandleWithContext(userContext)))
+		False := false
+		reason := errors.SessionRevokedReason
+		return errors.UnauthorizedError{Msg: "Session has been revoked", ClearTokens: &False, Reason: &reason}
+	}
+
+	return assertRequiredClaims(sessionContainer, connectionSession.options, userContext)
+}
+
+// UpdateAccessTokenWithContext replaces the session of the connection by the one of accessToken, which was
+// refreshed by the client. accessToken must belong to the same session as the one the connection was opened with.
+// The core issues a new access token the first time a refreshed one is used, so if AccessAndFrontTokenUpdated is
+// true in the GetAllSessionTokensDangerously of the new session, its access token should be sent to the client.
+func (connectionSession *ConnectionSession) UpdateAccessTokenWithContext(accessToken string, userContext supertokens.UserContext) error {
+	options := &sessmodels.VerifySessionOptions{}
+	if connectionSession.options != nil {
+		*options = *connectionSession.options
+	}
+	// without a request, the anti-csrf token is not checked unless asked to in options
+	True := true
+	options.SessionRequired = &True
+	sessionContainer, err := GetSessionWithoutRequestResponseWithContext(accessToken, nil, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	connectionSession.mutex.Lock()
+	defer connectionSession.mutex.Unlock()
+	if sessionContainer.GetHandleWithContext(userContext) != connectionSession.session.GetHandleWithContext(userContext) {
+		False := false
+		return errors.UnauthorizedError{Msg: "The access token belongs to another session", ClearTokens: &False}
+	}
+	connectionSession.session = sessionContainer
+	return nil
+}
+
+// StartRevalidation revalidates the connection every interval until the returned function is called,
+// and calls onInvalid with the error returned by RevalidateWithContext each time it fails
+func (connectionSession *ConnectionSession) StartRevalidation(interval time.Duration, onInvalid func(err error)) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				err := connectionSession.RevalidateWithContext(&map[string]interface{}{})
+				if err != nil {
+					onInvalid(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+func GetConnectionSession(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions) (*ConnectionSession, error) {
+	return GetConnectionSessionWithContext(req, res, options, &map[string]interface{}{})
+}
+
+func (connectionSession *ConnectionSession) Revalidate() error {
+	return connectionSession.RevalidateWithContext(&map[string]interface{}{})
+}
+
+func (connectionSession *ConnectionSession) UpdateAccessToken(accessToken string) error {
+	return connectionSession.UpdateAccessTokenWithContext(accessToken, &map[string]interface{}{})
+}
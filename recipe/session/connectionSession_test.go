@@ -0,0 +1,71 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+)
+
+func TestConnectionSessionAgainstFakeCore(t *testing.T) {
+	startFakeCoreForTest(t, nil)
+
+	newSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	otherSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Authorization", "Bearer "+newSession.GetAccessToken())
+	connectionSession, err := GetConnectionSession(req, httptest.NewRecorder(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, newSession.GetHandle(), connectionSession.GetSession().GetHandle())
+	assert.True(t, connectionSession.GetAccessTokenExpiry().After(time.Now()))
+	assert.NoError(t, connectionSession.Revalidate())
+
+	refreshedSession, err := RefreshSessionWithoutRequestResponse(*newSession.GetAllSessionTokensDangerously().RefreshToken, true, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, connectionSession.UpdateAccessToken(refreshedSession.GetAccessToken()))
+	// the first use of a refreshed access token makes the core issue a new one
+	assert.True(t, connectionSession.GetSession().GetAllSessionTokensDangerously().AccessAndFrontTokenUpdated)
+	assert.True(t, defaultErrors.As(connectionSession.UpdateAccessToken(otherSession.GetAccessToken()), &errors.UnauthorizedError{}))
+
+	invalidErrors := make(chan error, 10)
+	stop := connectionSession.StartRevalidation(10*time.Millisecond, func(err error) {
+		invalidErrors <- err
+	})
+	defer stop()
+	// the session can be used while it is revalidated in the background
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, newSession.GetHandle(), connectionSession.GetSession().GetHandle())
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.NoError(t, refreshedSession.RevokeSession())
+	select {
+	case err = <-invalidErrors:
+		unauthorizedError := errors.UnauthorizedError{}
+		assert.True(t, defaultErrors.As(err, &unauthorizedError))
+		assert.Equal(t, errors.SessionRevokedReason, *unauthorizedError.Reason)
+	case <-time.After(time.Second):
+		t.Fatal("the revoked session was not detected")
+	}
+}
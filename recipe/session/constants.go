@@ -15,12 +15,19 @@
 
 package session
 
-import "github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+import (
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
 
 const (
 	refreshAPIPath = "/session/refresh"
 	signoutAPIPath = "/signout"
 
+	activeSessionsAPIPath      = "/session/list"
+	revokeActiveSessionAPIPath = "/session/revoke"
+
 	antiCSRF_VIA_TOKEN         = "VIA_TOKEN"
 	antiCSRF_VIA_CUSTOM_HEADER = "VIA_CUSTOM_HEADER"
 	antiCSRF_NONE              = "NONE"
@@ -28,6 +35,39 @@ const (
 	cookieSameSite_NONE   = "none"
 	cookieSameSite_LAX    = "lax"
 	cookieSameSite_STRICT = "strict"
+
+	defaultSigningKeysRefreshInterval = time.Minute
+	defaultSigningKeysMaxStaleness    = 24 * time.Hour
+	// signing keys are not refreshed more often than this, even if an access token is signed
+	// with an unknown key, or if the previous refresh failed
+	signingKeysMinRefreshInterval = 5 * time.Second
+	jwksRequestTimeout            = 10 * time.Second
+
+	defaultIdleTimeoutActivityUpdateInterval = time.Minute
+
+	defaultRefreshGracePeriod = 5 * time.Second
+
+	fingerprintClaimKey                      = "st-fp"
+	defaultFingerprintIPv4SubnetPrefixLength = 24
+	defaultFingerprintIPv6SubnetPrefixLength = 64
+
+	dpopHeaderKey          = "dpop"
+	defaultDPoPMaxProofAge = time.Minute
+
+	// deviceInfoSessionDataKey is the session data key holding the sessmodels.DeviceInfo of the session
+	deviceInfoSessionDataKey = "st-device"
+)
+
+// Keys of the fields attached to log events by the session recipe, in addition to the ones of the supertokens package
+const (
+	LogKeyIPAddress             = "ipAddress"
+	LogKeyUserAgent             = "userAgent"
+	LogKeyRequestPath           = "requestPath"
+	LogKeyOrigin                = "origin"
+	LogKeyFingerprintMismatches = "fingerprintMismatches"
 )
 
+// supportedDPoPAlgorithms are the signing algorithms of the DPoP proofs that can be verified, and the default AllowedAlgorithms
+var supportedDPoPAlgorithms = []string{"ES256", "ES384", "RS256", "PS256", "EdDSA"}
+
 var availableTokenTransferMethods = []sessmodels.TokenTransferMethod{sessmodels.CookieTransferMethod, sessmodels.HeaderTransferMethod}
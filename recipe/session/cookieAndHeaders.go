@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
 const (
@@ -52,7 +53,7 @@ type TokenInfo struct {
 	Up  interface{} `json:"up"`
 }
 
-func clearSessionFromAllTokenTransferMethods(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter) error {
+func clearSessionFromAllTokenTransferMethods(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) error {
 	// We are clearing the session in all transfermethods to be sure to override cookies in case they have been already added to the response.
 	// This is done to handle the following use-case:
 	// If the app overrides signInPOST to check the ban status of the user after the original implementation and throwing an UNAUTHORISED error
@@ -60,7 +61,7 @@ func clearSessionFromAllTokenTransferMethods(config sessmodels.TypeNormalisedInp
 	// We can't know which to clear since we can't reliably query or remove the set-cookie header added to the response (causes issues in some frameworks, i.e.: hapi)
 	// The safe solution in this case is to overwrite all the response cookies/headers with an empty value, which is what we are doing here
 	for _, transferMethod := range availableTokenTransferMethods {
-		err := clearSession(config, res, transferMethod)
+		err := clearSession(config, req, res, transferMethod, userContext)
 		if err != nil {
 			return err
 		}
@@ -68,15 +69,18 @@ func clearSessionFromAllTokenTransferMethods(config sessmodels.TypeNormalisedInp
 	return nil
 }
 
-func clearSession(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, transferMethod sessmodels.TokenTransferMethod) error {
+func clearSession(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, transferMethod sessmodels.TokenTransferMethod, userContext supertokens.UserContext) error {
 	// If we can be specific about which transferMethod we want to clear, there is no reason to clear the other ones
 	tokenTypes := []sessmodels.TokenType{sessmodels.AccessToken, sessmodels.RefreshToken}
 	for _, tokenType := range tokenTypes {
-		err := setToken(config, res, tokenType, "", 0, transferMethod)
+		err := setToken(config, req, res, tokenType, "", 0, transferMethod, userContext)
 		if err != nil {
 			return err
 		}
 	}
+	if transferMethod == sessmodels.CookieTransferMethod {
+		clearCookiesOfOlderDomains(config, req, res, userContext)
+	}
 
 	res.Header().Del(antiCsrfHeaderKey)
 	// This can be added multiple times in some cases, but that should be OK
@@ -95,6 +99,11 @@ func setAntiCsrfTokenInHeaders(res http.ResponseWriter, antiCsrfToken string) {
 }
 
 func setFrontTokenInHeaders(res http.ResponseWriter, userId string, atExpiry uint64, jwtPayload interface{}) {
+	setHeader(res, frontTokenHeaderKey, buildFrontToken(userId, atExpiry, jwtPayload), false)
+	setHeader(res, "Access-Control-Expose-Headers", frontTokenHeaderKey, true)
+}
+
+func buildFrontToken(userId string, atExpiry uint64, jwtPayload interface{}) string {
 	tokenInfo := &TokenInfo{
 		Uid: userId,
 		Ate: atExpiry,
@@ -102,8 +111,7 @@ func setFrontTokenInHeaders(res http.ResponseWriter, userId string, atExpiry uin
 	}
 	parsed, _ := json.Marshal(tokenInfo)
 	data := []byte(parsed)
-	setHeader(res, frontTokenHeaderKey, base64.StdEncoding.EncodeToString(data), false)
-	setHeader(res, "Access-Control-Expose-Headers", frontTokenHeaderKey, true)
+	return base64.StdEncoding.EncodeToString(data)
 }
 
 func getCORSAllowedHeaders() []string {
@@ -112,16 +120,24 @@ func getCORSAllowedHeaders() []string {
 	}
 }
 
-func getCookieNameFromTokenType(tokenType sessmodels.TokenType) (string, error) {
+func getCookieNameFromTokenType(config sessmodels.TypeNormalisedInput, tokenType sessmodels.TokenType) (string, error) {
 	if tokenType == sessmodels.AccessToken {
-		return accessTokenCookieKey, nil
+		return config.Cookies.AccessTokenName, nil
 	}
 	if tokenType == sessmodels.RefreshToken {
-		return refreshTokenCookieKey, nil
+		return config.Cookies.RefreshTokenName, nil
 	}
 	return "", errors.New("Unknown token type, should never happen.")
 }
 
+// getOldCookieNamesFromTokenType returns the names the cookie of tokenType had before they were changed in the config
+func getOldCookieNamesFromTokenType(config sessmodels.TypeNormalisedInput, tokenType sessmodels.TokenType) []string {
+	if tokenType == sessmodels.AccessToken {
+		return config.Cookies.OldAccessTokenNames
+	}
+	return config.Cookies.OldRefreshTokenNames
+}
+
 func getResponseHeaderNameForTokenType(tokenType sessmodels.TokenType) (string, error) {
 	if tokenType == sessmodels.AccessToken {
 		return accessTokenHeaderKey, nil
@@ -132,28 +148,45 @@ func getResponseHeaderNameForTokenType(tokenType sessmodels.TokenType) (string,
 	return "", errors.New("Unknown token type, should never happen.")
 }
 
-func getToken(req *http.Request, tokenType sessmodels.TokenType, transferMethod sessmodels.TokenTransferMethod) (*string, error) {
+func getToken(config sessmodels.TypeNormalisedInput, req *http.Request, tokenType sessmodels.TokenType, transferMethod sessmodels.TokenTransferMethod) (*string, error) {
 	if transferMethod == sessmodels.CookieTransferMethod {
-		cookieName, err := getCookieNameFromTokenType(tokenType)
+		cookieName, err := getCookieNameFromTokenType(config, tokenType)
 		if err != nil {
 			return nil, err
 		}
-		return getCookieValue(req, cookieName), nil
+		token := getCookieValue(req, cookieName)
+		if token == nil {
+			for _, oldCookieName := range getOldCookieNamesFromTokenType(config, tokenType) {
+				token = getCookieValue(req, oldCookieName)
+				if token != nil {
+					supertokens.LogDebugMessage("getToken: using the token in the old cookie " + oldCookieName)
+					break
+				}
+			}
+		}
+		return token, nil
 	} else if transferMethod == sessmodels.HeaderTransferMethod {
 		headerValue := getHeader(req, authorizationHeaderKey)
-		if headerValue == nil || !strings.HasPrefix(*headerValue, "Bearer ") {
+		if headerValue == nil {
+			return nil, nil
+		}
+		// DPoP bound access tokens are sent using the DPoP scheme (RFC 9449)
+		var token string
+		if strings.HasPrefix(*headerValue, "Bearer ") {
+			token = strings.TrimSpace(strings.ReplaceAll(*headerValue, "Bearer ", ""))
+		} else if strings.HasPrefix(*headerValue, "DPoP ") {
+			token = strings.TrimSpace(strings.TrimPrefix(*headerValue, "DPoP "))
+		} else {
 			return nil, nil
 		}
-
-		token := strings.TrimSpace(strings.ReplaceAll(*headerValue, "Bearer ", ""))
 		return &token, nil
 	}
 	return nil, errors.New("Should never happen")
 }
 
-func setToken(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, tokenType sessmodels.TokenType, value string, expires uint64, transferMethod sessmodels.TokenTransferMethod) error {
+func setToken(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, tokenType sessmodels.TokenType, value string, expires uint64, transferMethod sessmodels.TokenTransferMethod, userContext supertokens.UserContext) error {
 	if transferMethod == sessmodels.CookieTransferMethod {
-		cookieName, err := getCookieNameFromTokenType(tokenType)
+		cookieName, err := getCookieNameFromTokenType(config, tokenType)
 		if err != nil {
 			return err
 		}
@@ -163,7 +196,15 @@ func setToken(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, to
 		} else if tokenType == sessmodels.RefreshToken {
 			pathType = "refreshTokenPath"
 		}
-		setCookie(config, res, cookieName, value, expires, pathType)
+		setCookie(config, req, res, cookieName, value, expires, pathType, userContext)
+		if config.Cookies.Partitioned {
+			// a partitioned cookie does not replace the unpartitioned one set before the cookies were partitioned
+			setCookieWithDomain(config, res, cookieName, "", 0, pathType, getCookieDomainOfRequest(config, req, userContext), config.GetCookieSameSite(req, userContext), false)
+		}
+		// the cookies with the old names are cleared, so that they stop being used once the new ones are set
+		for _, oldCookieName := range getOldCookieNamesFromTokenType(config, tokenType) {
+			clearCookie(config, req, res, oldCookieName, pathType, userContext)
+		}
 	} else if transferMethod == sessmodels.HeaderTransferMethod {
 		headerName, err := getResponseHeaderNameForTokenType(tokenType)
 		if err != nil {
@@ -187,13 +228,56 @@ func setHeader(res http.ResponseWriter, key, value string, allowDuplicateKey boo
 	}
 }
 
-func setCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, name string, value string, expires uint64, pathType string) {
-	var domain string
-	if config.CookieDomain != nil {
-		domain = *config.CookieDomain
+func setCookie(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, name string, value string, expires uint64, pathType string, userContext supertokens.UserContext) {
+	setCookieWithDomain(config, res, name, value, expires, pathType, getCookieDomainOfRequest(config, req, userContext), config.GetCookieSameSite(req, userContext), config.Cookies.Partitioned)
+}
+
+// clearCookie clears the cookie name of the domain chosen for req
+func clearCookie(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, name string, pathType string, userContext supertokens.UserContext) {
+	clearCookieWithDomain(config, res, name, pathType, getCookieDomainOfRequest(config, req, userContext), config.GetCookieSameSite(req, userContext))
+}
+
+// clearCookieWithDomain clears the unpartitioned cookie name of domain, since a partitioned cookie does not replace
+// it, and the partitioned one as well if the cookies are partitioned
+func clearCookieWithDomain(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, name string, pathType string, domain string, sameSite string) {
+	setCookieWithDomain(config, res, name, "", 0, pathType, domain, sameSite, false)
+	if config.Cookies.Partitioned {
+		setCookieWithDomain(config, res, name, "", 0, pathType, domain, sameSite, true)
+	}
+}
+
+func getCookieDomainOfRequest(config sessmodels.TypeNormalisedInput, req *http.Request, userContext supertokens.UserContext) string {
+	if cookieDomain := config.GetCookieDomain(req, userContext); cookieDomain != nil {
+		return *cookieDomain
+	}
+	return ""
+}
+
+// clearCookiesOfOlderDomains clears the cookies of the tokens that were set for the domains in OlderCookieDomains.
+// The domain chosen for req is skipped, since clearing it would replace the cookies that were just set for it.
+func clearCookiesOfOlderDomains(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) {
+	requestCookieDomain := getCookieDomainOfRequest(config, req, userContext)
+	sameSite := config.GetCookieSameSite(req, userContext)
+	for _, olderCookieDomain := range config.OlderCookieDomains {
+		// the cookies are keyed by their domain without its leading dot, like in getCookieKey
+		if strings.TrimPrefix(olderCookieDomain, ".") == strings.TrimPrefix(requestCookieDomain, ".") {
+			continue
+		}
+		for _, tokenType := range []sessmodels.TokenType{sessmodels.AccessToken, sessmodels.RefreshToken} {
+			pathType := "accessTokenPath"
+			if tokenType == sessmodels.RefreshToken {
+				pathType = "refreshTokenPath"
+			}
+			cookieName, _ := getCookieNameFromTokenType(config, tokenType)
+			for _, name := range append([]string{cookieName}, getOldCookieNamesFromTokenType(config, tokenType)...) {
+				clearCookieWithDomain(config, res, name, pathType, olderCookieDomain, sameSite)
+			}
+		}
 	}
+}
+
+func setCookieWithDomain(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, name string, value string, expires uint64, pathType string, domain string, sameSite string, partitioned bool) {
 	secure := config.CookieSecure
-	sameSite := config.CookieSameSite
 
 	path := ""
 	if pathType == "refreshTokenPath" {
@@ -221,7 +305,12 @@ func setCookie(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, n
 		Path:     path,
 		SameSite: sameSiteField,
 	}
-	setCookieValue(res, cookie)
+	cookieStr := cookie.String()
+	// http.Cookie does not support the Partitioned attribute before Go 1.23
+	if partitioned {
+		cookieStr += "; Partitioned"
+	}
+	setCookieValue(res, cookieStr)
 
 }
 
@@ -261,19 +350,19 @@ func getCookieValue(request *http.Request, key string) *string {
 }
 
 // setCookieValue replaces cookie.go SetCookie, it replaces the cookie values instead of appending them
-func setCookieValue(w http.ResponseWriter, cookie *http.Cookie) {
+func setCookieValue(w http.ResponseWriter, cookie string) {
 	cookieHeader := w.Header().Values("Set-Cookie")
 	if len(cookieHeader) == 0 {
-		w.Header().Set("Set-Cookie", cookie.String())
+		w.Header().Set("Set-Cookie", cookie)
 		return
 	}
 	existingCookies := make(map[string]string, len(cookieHeader))
-	// map existing cookies by cookie name
+	// map existing cookies by cookie name, domain and path, since cookies of older domains can have the same name
 	for _, ch := range cookieHeader {
-		existingCookies[getCookieName(ch)] = ch
+		existingCookies[getCookieKey(ch)] = ch
 	}
 	// replace if already existing
-	existingCookies[getCookieName(cookie.String())] = cookie.String()
+	existingCookies[getCookieKey(cookie)] = cookie
 	// clear previous cookies from the headers
 	w.Header().Del("Set-Cookie")
 	// and add them back
@@ -294,3 +383,27 @@ func getCookieName(cookie string) string {
 	}
 	return kv[0]
 }
+
+// getCookieKey returns the name, domain and path of cookie, which identify the cookie in the browser
+func getCookieKey(cookie string) string {
+	key := getCookieName(cookie)
+	domain := ""
+	path := ""
+	partitioned := false
+	for _, attribute := range strings.Split(cookie, ";")[1:] {
+		attribute = textproto.TrimString(attribute)
+		lowerAttribute := strings.ToLower(attribute)
+		if strings.HasPrefix(lowerAttribute, "domain=") {
+			domain = strings.TrimPrefix(lowerAttribute[len("domain="):], ".")
+		} else if strings.HasPrefix(lowerAttribute, "path=") {
+			path = attribute[len("path="):]
+		} else if lowerAttribute == "partitioned" {
+			partitioned = true
+		}
+	}
+	// the partitioned cookies are stored separately from the unpartitioned ones with the same name, domain and path
+	if partitioned {
+		return key + ";" + domain + ";" + path + ";partitioned"
+	}
+	return key + ";" + domain + ";" + path
+}
@@ -0,0 +1,216 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestCookiesConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "https://api.supertokens.io",
+		WebsiteDomain: "https://supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, "sAccessToken", config.Cookies.AccessTokenName)
+	assert.Equal(t, "sRefreshToken", config.Cookies.RefreshTokenName)
+
+	accessTokenName := "at"
+	config, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Cookies: &sessmodels.CookiesConfig{
+		AccessTokenName:        &accessTokenName,
+		AccessTokenNamePrefix:  sessmodels.HostCookieNamePrefix,
+		RefreshTokenNamePrefix: sessmodels.SecureCookieNamePrefix,
+		Partitioned:            true,
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, "__Host-at", config.Cookies.AccessTokenName)
+	assert.Equal(t, "__Secure-sRefreshToken", config.Cookies.RefreshTokenName)
+	assert.True(t, config.Cookies.Partitioned)
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Cookies: &sessmodels.CookiesConfig{RefreshTokenNamePrefix: sessmodels.HostCookieNamePrefix}})
+	assert.Error(t, err)
+
+	cookieDomain := ".supertokens.io"
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{CookieDomain: &cookieDomain, Cookies: &sessmodels.CookiesConfig{AccessTokenNamePrefix: sessmodels.HostCookieNamePrefix}})
+	assert.EqualError(t, err, "the __Host- cookie name prefix cannot be used with CookieDomain or GetCookieDomain")
+
+	False := false
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{CookieSecure: &False, Cookies: &sessmodels.CookiesConfig{AccessTokenNamePrefix: sessmodels.SecureCookieNamePrefix}})
+	assert.EqualError(t, err, "cookie name prefixes require CookieSecure to be true")
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{CookieSecure: &False, Cookies: &sessmodels.CookiesConfig{Partitioned: true}})
+	assert.EqualError(t, err, "partitioned cookies require CookieSecure to be true")
+
+	invalidName := "s;AccessToken"
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Cookies: &sessmodels.CookiesConfig{AccessTokenName: &invalidName}})
+	assert.EqualError(t, err, "invalid cookie name: s;AccessToken")
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Cookies: &sessmodels.CookiesConfig{OldAccessTokenNames: []string{"sAccessToken"}}})
+	assert.Error(t, err)
+}
+
+func TestCookieNameMigration(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "https://api.supertokens.io",
+		WebsiteDomain: "https://supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Cookies: &sessmodels.CookiesConfig{
+		AccessTokenNamePrefix: sessmodels.HostCookieNamePrefix,
+		Partitioned:           true,
+		OldAccessTokenNames:   []string{"sAccessToken"},
+	}})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add("Cookie", "sAccessToken=old")
+	token, err := getToken(config, req, sessmodels.AccessToken, sessmodels.CookieTransferMethod)
+	assert.NoError(t, err)
+	assert.Equal(t, "old", *token)
+	req.Header.Add("Cookie", "__Host-sAccessToken=new")
+	token, err = getToken(config, req, sessmodels.AccessToken, sessmodels.CookieTransferMethod)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", *token)
+
+	res := httptest.NewRecorder()
+	assert.NoError(t, setToken(config, req, res, sessmodels.AccessToken, "new", getCurrTimeInMS()+60000, sessmodels.CookieTransferMethod, &map[string]interface{}{}))
+	cookies := map[string]string{}
+	for _, cookie := range res.Header().Values("Set-Cookie") {
+		cookies[getCookieKey(cookie)] = cookie
+	}
+	assert.Len(t, cookies, 4)
+	assert.True(t, strings.HasPrefix(cookies["__Host-sAccessToken;;/;partitioned"], "__Host-sAccessToken=new; Path=/;"))
+	assert.True(t, strings.HasSuffix(cookies["__Host-sAccessToken;;/;partitioned"], "; Partitioned"))
+	// the unpartitioned cookies are cleared, since the partitioned ones do not replace them
+	assert.Contains(t, cookies["__Host-sAccessToken;;/"], "Expires=Thu, 01 Jan 1970 00:00:00 GMT")
+	assert.NotContains(t, cookies["__Host-sAccessToken;;/"], "Partitioned")
+	assert.Contains(t, cookies["sAccessToken;;/"], "Expires=Thu, 01 Jan 1970 00:00:00 GMT")
+	assert.NotContains(t, cookies["sAccessToken;;/"], "Partitioned")
+	assert.Contains(t, cookies["sAccessToken;;/;partitioned"], "Expires=Thu, 01 Jan 1970 00:00:00 GMT")
+}
+
+func TestCookieDomainPerRequestAndOlderCookieDomains(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "https://api.supertokens.io",
+		WebsiteDomain: "https://supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	cookieDomain := ".supertokens.io"
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{CookieDomain: &cookieDomain, OlderCookieDomains: []string{"supertokens.io"}})
+	assert.EqualError(t, err, "OlderCookieDomains cannot include CookieDomain")
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{
+		CookieDomain:       &cookieDomain,
+		OlderCookieDomains: []string{"", "api.supertokens.io"},
+		GetCookieDomain: func(req *http.Request, userContext supertokens.UserContext) *string {
+			if strings.HasSuffix(req.Header.Get("Origin"), ".example.com") {
+				domain := ".example.com"
+				return &domain
+			}
+			return &cookieDomain
+		},
+		GetCookieSameSite: func(req *http.Request, userContext supertokens.UserContext) string {
+			if strings.HasSuffix(req.Header.Get("Origin"), ".example.com") {
+				return "None"
+			}
+			return "lax"
+		},
+	})
+	assert.NoError(t, err)
+	// the anti-csrf protection is needed since SameSite can be none
+	assert.Equal(t, antiCSRF_VIA_CUSTOM_HEADER, config.AntiCsrf)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	res := httptest.NewRecorder()
+	assert.NoError(t, clearSession(config, req, res, sessmodels.CookieTransferMethod, &map[string]interface{}{}))
+	cookies := res.Header().Values("Set-Cookie")
+	// the cookies of the request's domain and of the two older domains
+	assert.Len(t, cookies, 6)
+	// the cookies of the older domains are cleared with the SameSite of the request
+	for _, cookie := range cookies {
+		assert.Contains(t, cookie, "SameSite=None")
+	}
+	cookieKeys := map[string]bool{}
+	for _, cookie := range cookies {
+		cookieKeys[getCookieKey(cookie)] = true
+	}
+	assert.True(t, cookieKeys["sAccessToken;example.com;/"])
+	assert.True(t, cookieKeys["sAccessToken;;/"])
+	assert.True(t, cookieKeys["sRefreshToken;api.supertokens.io;/auth/session/refresh"])
+}
+
+func TestClearCookiesOfOlderDomainsSkipsTheDomainOfTheRequest(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "https://api.supertokens.io",
+		WebsiteDomain: "https://supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	cookieDomain := ".supertokens.io"
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{
+		CookieDomain:       &cookieDomain,
+		OlderCookieDomains: []string{"", "api.supertokens.io"},
+		GetCookieDomain: func(req *http.Request, userContext supertokens.UserContext) *string {
+			if req.Header.Get("Origin") == "https://legacy.supertokens.io" {
+				domain := "api.supertokens.io"
+				return &domain
+			}
+			return nil
+		},
+	})
+	assert.NoError(t, err)
+
+	for _, origin := range []string{"https://legacy.supertokens.io", "https://other.io"} {
+		req := httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil)
+		req.Header.Set("Origin", origin)
+		res := httptest.NewRecorder()
+		userContext := &map[string]interface{}{}
+		assert.NoError(t, setToken(config, req, res, sessmodels.AccessToken, "new", uint64(time.Now().Add(time.Hour).UnixNano()/1000000), sessmodels.CookieTransferMethod, userContext))
+		clearCookiesOfOlderDomains(config, req, res, userContext)
+
+		// the cookie that was just set is not replaced by the one clearing an older domain
+		cookies := map[string]string{}
+		for _, cookie := range res.Header().Values("Set-Cookie") {
+			cookies[getCookieKey(cookie)] = cookie
+		}
+		var accessTokenCookie string
+		if origin == "https://legacy.supertokens.io" {
+			accessTokenCookie = cookies["sAccessToken;api.supertokens.io;/"]
+		} else {
+			accessTokenCookie = cookies["sAccessToken;;/"]
+		}
+		assert.True(t, strings.HasPrefix(accessTokenCookie, "sAccessToken=new;"), accessTokenCookie)
+	}
+}
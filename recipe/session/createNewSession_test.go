@@ -75,8 +75,9 @@ func TestCreateAccessTokenPayloadWithSessionClaims(t *testing.T) {
 	assert.Equal(t, 200, res.StatusCode)
 
 	accessTokenPayload = sessionContainer.GetAccessTokenPayload()
-	assert.Equal(t, 1, len(accessTokenPayload))
+	assert.Equal(t, 2, len(accessTokenPayload))
 	assert.NotNil(t, accessTokenPayload["st-true"])
+	assert.NotNil(t, accessTokenPayload["st-auth-time"])
 	assert.Equal(t, true, accessTokenPayload["st-true"].(map[string]interface{})["v"])
 	assert.Greater(t, accessTokenPayload["st-true"].(map[string]interface{})["t"], float64(time.Now().UnixNano()/1000000-1000))
 }
@@ -144,7 +145,7 @@ func TestNotCreateAccessTokenPayloadWithNilClaim(t *testing.T) {
 	assert.Equal(t, 200, res.StatusCode)
 
 	accessTokenPayload = sessionContainer.GetAccessTokenPayload()
-	assert.Equal(t, 0, len(accessTokenPayload))
+	assert.Equal(t, 1, len(accessTokenPayload))
 }
 
 func TestMergeClaimsAndPassedAccessTokenPayload(t *testing.T) {
@@ -244,9 +245,9 @@ func TestMergeClaimsAndPassedAccessTokenPayload(t *testing.T) {
 
 	accessTokenPayload := sessionContainer.GetAccessTokenPayload()
 	if includesNullInPayload {
-		assert.Equal(t, 5, len(accessTokenPayload))
+		assert.Equal(t, 6, len(accessTokenPayload))
 	} else {
-		assert.Equal(t, 4, len(accessTokenPayload))
+		assert.Equal(t, 5, len(accessTokenPayload))
 	}
 
 	// We have the prop from the payload param
@@ -0,0 +1,237 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// makeRecipeImplementationWithDeviceInfo changes the functions creating and refreshing sessions of
+// originalImplementation so that they store the device info of the request in the session data
+func makeRecipeImplementationWithDeviceInfo(originalImplementation sessmodels.RecipeInterface, config sessmodels.DeviceInfoNormalisedConfig) sessmodels.RecipeInterface {
+	originalGetSessionInformation := *originalImplementation.GetSessionInformation
+	originalUpdateSessionData := *originalImplementation.UpdateSessionData
+
+	// updateDeviceInfo is only done on a best-effort basis, since the tokens of the session were already refreshed
+	updateDeviceInfo := func(sessionContainer sessmodels.SessionContainer, req *http.Request, userContext supertokens.UserContext) {
+		sessionHandle := sessionContainer.GetHandleWithContext(userContext)
+		sessionInformation, err := originalGetSessionInformation(sessionHandle, userContext)
+		if err == nil && sessionInformation != nil {
+			sessionData := addDeviceInfoToSessionData(config, sessionInformation.SessionData, req, userContext)
+			_, err = originalUpdateSessionData(sessionHandle, sessionData, userContext)
+		}
+		if err != nil {
+			supertokens.LogWarnMessage("refreshSession: could not update the device info of the session",
+				supertokens.RecipeIDLogField(RECIPE_ID),
+				supertokens.SessionHandleLogField(sessionHandle),
+				supertokens.ErrorLogField(err),
+			)
+		}
+	}
+
+	{
+		originalCreateNewSession := *originalImplementation.CreateNewSession
+
+		(*originalImplementation.CreateNewSession) = func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionData = addDeviceInfoToSessionData(config, sessionData, req, userContext)
+			return originalCreateNewSession(req, res, userID, accessTokenPayload, sessionData, userContext)
+		}
+	}
+
+	{
+		originalRefreshSession := *originalImplementation.RefreshSession
+
+		(*originalImplementation.RefreshSession) = func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalRefreshSession(req, res, userContext)
+			if err != nil {
+				return nil, err
+			}
+			updateDeviceInfo(sessionContainer, req, userContext)
+			return sessionContainer, nil
+		}
+	}
+
+	{
+		originalCreateNewSessionWithoutRequestResponse := *originalImplementation.CreateNewSessionWithoutRequestResponse
+
+		(*originalImplementation.CreateNewSessionWithoutRequestResponse) = func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			req := getRequestFromUserContext(userContext)
+			if req != nil {
+				sessionData = addDeviceInfoToSessionData(config, sessionData, req, userContext)
+			}
+			return originalCreateNewSessionWithoutRequestResponse(userID, accessTokenPayload, sessionData, disableAntiCsrf, userContext)
+		}
+	}
+
+	{
+		originalRefreshSessionWithoutRequestResponse := *originalImplementation.RefreshSessionWithoutRequestResponse
+
+		(*originalImplementation.RefreshSessionWithoutRequestResponse) = func(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalRefreshSessionWithoutRequestResponse(refreshToken, disableAntiCsrf, antiCsrfToken, userContext)
+			if err != nil {
+				return nil, err
+			}
+			req := getRequestFromUserContext(userContext)
+			if req != nil {
+				updateDeviceInfo(sessionContainer, req, userContext)
+			}
+			return sessionContainer, nil
+		}
+	}
+
+	// the device info is kept when the session data is replaced
+	(*originalImplementation.UpdateSessionData) = func(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
+		if _, ok := newSessionData[deviceInfoSessionDataKey]; !ok {
+			sessionInformation, err := originalGetSessionInformation(sessionHandle, userContext)
+			if err != nil {
+				return false, err
+			}
+			if sessionInformation == nil {
+				return false, nil
+			}
+			if sessionInformation.DeviceInfo != nil {
+				sessionData := map[string]interface{}{}
+				for key, value := range newSessionData {
+					sessionData[key] = value
+				}
+				sessionData[deviceInfoSessionDataKey] = *sessionInformation.DeviceInfo
+				newSessionData = sessionData
+			}
+		}
+		return originalUpdateSessionData(sessionHandle, newSessionData, userContext)
+	}
+
+	return originalImplementation
+}
+
+func addDeviceInfoToSessionData(config sessmodels.DeviceInfoNormalisedConfig, sessionData map[string]interface{}, req *http.Request, userContext supertokens.UserContext) map[string]interface{} {
+	userAgent := req.Header.Get("User-Agent")
+	result := map[string]interface{}{}
+	for key, value := range sessionData {
+		result[key] = value
+	}
+	result[deviceInfoSessionDataKey] = sessmodels.DeviceInfo{
+		UserAgent:  userAgent,
+		IPAddress:  config.GetIPAddress(req, userContext),
+		DeviceName: config.GetDeviceName(userAgent, userContext),
+		LastSeen:   getCurrTimeInMS(),
+	}
+	return result
+}
+
+func getDeviceInfoFromSessionData(sessionData map[string]interface{}) *sessmodels.DeviceInfo {
+	value, ok := sessionData[deviceInfoSessionDataKey]
+	if !ok {
+		return nil
+	}
+	// the value is a map if it was read from the core, so it is converted using its JSON representation
+	deviceInfoJSON, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var deviceInfo sessmodels.DeviceInfo
+	err = json.Unmarshal(deviceInfoJSON, &deviceInfo)
+	if err != nil {
+		return nil
+	}
+	return &deviceInfo
+}
+
+func getRequestFromUserContext(userContext supertokens.UserContext) *http.Request {
+	if userContext == nil {
+		return nil
+	}
+	defaultValues, ok := (*userContext)["_default"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	req, _ := defaultValues["request"].(*http.Request)
+	return req
+}
+
+// setRequestInUserContext adds req to userContext like supertokens.MakeDefaultUserContextFromAPI, unless it already has one
+func setRequestInUserContext(userContext supertokens.UserContext, req *http.Request) supertokens.UserContext {
+	if req == nil || getRequestFromUserContext(userContext) != nil {
+		return userContext
+	}
+	if userContext == nil {
+		return supertokens.MakeDefaultUserContextFromAPI(req)
+	}
+	defaultValues, ok := (*userContext)["_default"].(map[string]interface{})
+	if !ok {
+		defaultValues = map[string]interface{}{}
+		(*userContext)["_default"] = defaultValues
+	}
+	defaultValues["request"] = req
+	return userContext
+}
+
+func defaultGetIPAddress(req *http.Request, _ supertokens.UserContext) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func defaultGetDeviceName(userAgent string, _ supertokens.UserContext) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+	browser := ""
+	// the order matters, since the user agent of most browsers also contains the names of other browsers
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+	os := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			os = candidate.name
+			break
+		}
+	}
+	if browser != "" && os != "" {
+		return browser + " on " + os
+	} else if browser != "" {
+		return browser
+	} else if os != "" {
+		return os
+	}
+	return "Unknown device"
+}
@@ -0,0 +1,141 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestDefaultGetDeviceName(t *testing.T) {
+	userAgents := map[string]string{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0": "Edge on macOS",
+		"Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36":                        "Chrome on Android",
+		"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1":      "Safari on iPad",
+		"curl/8.4.0": "Unknown device",
+		"":           "Unknown device",
+	}
+	for userAgent, deviceName := range userAgents {
+		assert.Equal(t, deviceName, defaultGetDeviceName(userAgent, nil), userAgent)
+	}
+}
+
+func TestDefaultGetIPAddress(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "[2001:db8::1]:443"
+	assert.Equal(t, "2001:db8::1", defaultGetIPAddress(req, nil))
+}
+
+func TestActiveSessionsAPIsAgainstFakeCore(t *testing.T) {
+	core, testServer := startFakeCoreForTest(t, &sessmodels.TypeInput{
+		DeviceInfo: &sessmodels.DeviceInfoConfig{},
+	})
+
+	createSession := func(userAgent string) sessmodels.SessionContainer {
+		req := httptest.NewRequest(http.MethodPost, "/auth/signin", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = "203.0.113.7:4321"
+		sessionContainer, err := CreateNewSession(req, httptest.NewRecorder(), "userId", nil, map[string]interface{}{"a": "b"})
+		assert.NoError(t, err)
+		return sessionContainer
+	}
+	chromeUserAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	currentSession := createSession(chromeUserAgent)
+	otherSession := createSession("Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1")
+	_, err := CreateNewSession(httptest.NewRequest(http.MethodPost, "/auth/signin", nil), httptest.NewRecorder(), "otherUserId", nil, nil)
+	assert.NoError(t, err)
+
+	sessionInformation, err := GetSessionInformation(currentSession.GetHandle())
+	assert.NoError(t, err)
+	assert.Equal(t, "Chrome on Windows", sessionInformation.DeviceInfo.DeviceName)
+	assert.Equal(t, "203.0.113.7", sessionInformation.DeviceInfo.IPAddress)
+	assert.Equal(t, chromeUserAgent, sessionInformation.DeviceInfo.UserAgent)
+	// the device info is not part of the session data
+	sessionData, err := currentSession.GetSessionData()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "b"}, sessionData)
+
+	// replacing the session data keeps the device info
+	err = currentSession.UpdateSessionData(map[string]interface{}{"c": "d"})
+	assert.NoError(t, err)
+	sessionInformation, err = GetSessionInformation(currentSession.GetHandle())
+	assert.NoError(t, err)
+	assert.Equal(t, "d", sessionInformation.SessionData["c"])
+	assert.Equal(t, "Chrome on Windows", sessionInformation.DeviceInfo.DeviceName)
+
+	// refreshing the session updates its device info
+	refreshReq := httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil)
+	refreshReq.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0")
+	lastSeen := sessionInformation.DeviceInfo.LastSeen
+	time.Sleep(2 * time.Millisecond)
+	refreshedSession, err := RefreshSessionWithoutRequestResponseWithContext(*currentSession.GetAllSessionTokensDangerously().RefreshToken, true, nil, supertokens.MakeDefaultUserContextFromAPI(refreshReq))
+	assert.NoError(t, err)
+	sessionInformation, err = GetSessionInformation(currentSession.GetHandle())
+	assert.NoError(t, err)
+	assert.Equal(t, "Firefox on Linux", sessionInformation.DeviceInfo.DeviceName)
+	assert.Greater(t, sessionInformation.DeviceInfo.LastSeen, lastSeen)
+	assert.Equal(t, "d", sessionInformation.SessionData["c"])
+	currentSession = refreshedSession
+
+	sendRequest := func(method string, path string, body string) map[string]interface{} {
+		req, err := http.NewRequest(method, testServer.URL+path, strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+currentSession.GetAllSessionTokensDangerously().AccessToken)
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		result := map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		return result
+	}
+
+	result := sendRequest(http.MethodGet, "/auth/session/list", "")
+	assert.Equal(t, "OK", result["status"])
+	sessions := result["sessions"].([]interface{})
+	assert.Equal(t, 2, len(sessions))
+	deviceNames := map[string]string{}
+	for _, activeSession := range sessions {
+		activeSession := activeSession.(map[string]interface{})
+		deviceNames[activeSession["sessionHandle"].(string)] = activeSession["deviceInfo"].(map[string]interface{})["deviceName"].(string)
+		assert.Equal(t, activeSession["sessionHandle"] == currentSession.GetHandle(), activeSession["isCurrentSession"])
+	}
+	assert.Equal(t, map[string]string{currentSession.GetHandle(): "Firefox on Linux", otherSession.GetHandle(): "Safari on iPhone"}, deviceNames)
+
+	otherUserSessionHandles, err := GetAllSessionHandlesForUser("otherUserId")
+	assert.NoError(t, err)
+	result = sendRequest(http.MethodPost, "/auth/session/revoke", `{"sessionHandle":"`+otherUserSessionHandles[0]+`"}`)
+	assert.Equal(t, "UNKNOWN_SESSION_ERROR", result["status"])
+
+	result = sendRequest(http.MethodPost, "/auth/session/revoke", `{"sessionHandle":"`+otherSession.GetHandle()+`"}`)
+	assert.Equal(t, "OK", result["status"])
+	sessionHandles, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{currentSession.GetHandle()}, sessionHandles)
+
+	// the APIs are not exposed without DeviceInfo
+	testServer = initWithFakeCoreForTest(t, core, nil)
+	res, err := http.Get(testServer.URL + "/auth/session/list")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
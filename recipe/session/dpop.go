@@ -0,0 +1,485 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	defaultErrors "errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// dpopConfirmationPayloadKey is the access token payload key holding the thumbprint of the DPoP key the
+// access token is bound to, as {"jkt": thumbprint}
+const dpopConfirmationPayloadKey = "cnf"
+
+// makeRecipeImplementationWithDPoP changes the functions creating, verifying and refreshing sessions of
+// originalImplementation so that the access tokens created with a DPoP proof are bound to its key, and can
+// only be used with proofs signed by the same key
+func makeRecipeImplementationWithDPoP(originalImplementation sessmodels.RecipeInterface, config sessmodels.TypeNormalisedInput) sessmodels.RecipeInterface {
+	dpopConfig := *config.DPoP
+
+	{
+		originalCreateNewSession := *originalImplementation.CreateNewSession
+
+		(*originalImplementation.CreateNewSession) = func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			tokenTransferMethod := config.GetTokenTransferMethod(req, true, userContext)
+			if tokenTransferMethod == sessmodels.AnyTransferMethod {
+				tokenTransferMethod = sessmodels.HeaderTransferMethod
+			}
+			if tokenTransferMethod == sessmodels.HeaderTransferMethod {
+				proof := getHeader(req, dpopHeaderKey)
+				if proof == nil && dpopConfig.Required {
+					return nil, newInvalidDPoPProofError("a DPoP proof is required to create a session", false)
+				}
+				if proof != nil {
+					thumbprint, err := verifyDPoPProof(dpopConfig, req, *proof, nil, userContext)
+					if err != nil {
+						return nil, err
+					}
+					accessTokenPayload = addDPoPConfirmationToAccessTokenPayload(accessTokenPayload, thumbprint)
+				}
+			}
+			return originalCreateNewSession(req, res, userID, accessTokenPayload, sessionData, userContext)
+		}
+	}
+
+	{
+		originalGetSession := *originalImplementation.GetSession
+
+		(*originalImplementation.GetSession) = func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			// the proof is checked before the session is verified, so that a bound access token used without its key
+			// has no effect on the session, like extending its idle timeout or revoking it for a fingerprint mismatch
+			accessToken, tokenTransferMethod, boundThumbprint, err := getAccessTokenOfRequestForDPoP(config, req, userContext)
+			if err != nil {
+				return nil, err
+			}
+			if boundThumbprint != nil {
+				if tokenTransferMethod != sessmodels.HeaderTransferMethod {
+					return nil, newInvalidDPoPProofError("a DPoP bound access token must be sent in the Authorization header", false)
+				}
+				err = checkDPoPProofOfRequest(dpopConfig, req, accessToken, *boundThumbprint, userContext)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return originalGetSession(req, res, options, userContext)
+		}
+	}
+
+	{
+		originalRefreshSession := *originalImplementation.RefreshSession
+
+		(*originalImplementation.RefreshSession) = func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			// the proof is verified before refreshing, so that an invalid proof does not rotate the tokens
+			var thumbprint *string = nil
+			if proof := getHeader(req, dpopHeaderKey); proof != nil {
+				proofThumbprint, err := verifyDPoPProof(dpopConfig, req, *proof, nil, userContext)
+				if err != nil {
+					return nil, err
+				}
+				thumbprint = &proofThumbprint
+			}
+			sessionContainer, err := originalRefreshSession(req, res, userContext)
+			if err != nil {
+				return nil, err
+			}
+			boundThumbprint := getDPoPThumbprintFromAccessTokenPayload(sessionContainer.GetAccessTokenPayloadWithContext(userContext))
+			if boundThumbprint == nil || (thumbprint != nil && *thumbprint == *boundThumbprint) {
+				return sessionContainer, nil
+			}
+			// the new tokens are already attached to the response, so the session is revoked since
+			// its refresh token is used by a client that does not have its key
+			supertokens.LogDebugMessage("refreshSession: revoking session because the DPoP proof does not match its access token")
+			err = sessionContainer.RevokeSessionWithContext(userContext)
+			if err != nil {
+				return nil, err
+			}
+			return nil, newInvalidDPoPProofError("the DPoP proof does not match the session", true)
+		}
+	}
+
+	{
+		originalGetSessionWithoutRequestResponse := *originalImplementation.GetSessionWithoutRequestResponse
+
+		(*originalImplementation.GetSessionWithoutRequestResponse) = func(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			// like in GetSession, the proof is checked before the session is verified
+			boundThumbprint := getDPoPThumbprintOfAccessToken(accessToken)
+			if boundThumbprint != nil {
+				req := getRequestFromUserContext(userContext)
+				if req == nil {
+					return nil, newInvalidDPoPProofError("a DPoP bound access token can only be verified with the request in the userContext", false)
+				}
+				err := checkDPoPProofOfRequest(dpopConfig, req, &accessToken, *boundThumbprint, userContext)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return originalGetSessionWithoutRequestResponse(accessToken, antiCsrfToken, options, userContext)
+		}
+	}
+
+	{
+		originalRefreshSessionWithoutRequestResponse := *originalImplementation.RefreshSessionWithoutRequestResponse
+
+		(*originalImplementation.RefreshSessionWithoutRequestResponse) = func(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			// like in RefreshSession, the proof is verified before refreshing, so that an invalid proof does not
+			// rotate the tokens
+			req := getRequestFromUserContext(userContext)
+			var thumbprint *string = nil
+			if req != nil {
+				if proof := getHeader(req, dpopHeaderKey); proof != nil {
+					proofThumbprint, err := verifyDPoPProof(dpopConfig, req, *proof, nil, userContext)
+					if err != nil {
+						return nil, err
+					}
+					thumbprint = &proofThumbprint
+				}
+			}
+			sessionContainer, err := originalRefreshSessionWithoutRequestResponse(refreshToken, disableAntiCsrf, antiCsrfToken, userContext)
+			if err != nil {
+				return nil, err
+			}
+			boundThumbprint := getDPoPThumbprintFromAccessTokenPayload(sessionContainer.GetAccessTokenPayloadWithContext(userContext))
+			if boundThumbprint == nil {
+				return sessionContainer, nil
+			}
+			// the new tokens are only returned to the caller, so the session is kept
+			if req == nil {
+				return nil, newInvalidDPoPProofError("a DPoP bound session can only be refreshed with the request in the userContext", false)
+			}
+			if thumbprint == nil {
+				return nil, newInvalidDPoPProofError("a DPoP proof is required for a DPoP bound access token", false)
+			}
+			if *thumbprint != *boundThumbprint {
+				return nil, newInvalidDPoPProofError("the DPoP proof is not signed by the key the access token is bound to", false)
+			}
+			return sessionContainer, nil
+		}
+	}
+
+	return originalImplementation
+}
+
+func newInvalidDPoPProofError(message string, clearTokens bool) errors.UnauthorizedError {
+	reason := errors.InvalidDPoPProofReason
+	supertokens.LogDebugMessage("dpop: returning UNAUTHORISED: " + message)
+	return errors.UnauthorizedError{Msg: message, ClearTokens: &clearTokens, Reason: &reason}
+}
+
+// getAccessTokenOfRequestForDPoP returns the access token used by GetSession for req, with its transfer method and
+// the thumbprint of the DPoP key it is bound to, if any
+func getAccessTokenOfRequestForDPoP(config sessmodels.TypeNormalisedInput, req *http.Request, userContext supertokens.UserContext) (*string, sessmodels.TokenTransferMethod, *string, error) {
+	allowedTokenTransferMethod := config.GetTokenTransferMethod(req, false, userContext)
+	// like in GetSession, the header is used before the cookies
+	for _, tokenTransferMethod := range []sessmodels.TokenTransferMethod{sessmodels.HeaderTransferMethod, sessmodels.CookieTransferMethod} {
+		if allowedTokenTransferMethod != sessmodels.AnyTransferMethod && allowedTokenTransferMethod != tokenTransferMethod {
+			continue
+		}
+		token, err := getToken(config, req, sessmodels.AccessToken, tokenTransferMethod)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		if token == nil {
+			continue
+		}
+		parsedToken, err := parseJWTWithoutSignatureVerification(*token)
+		if err != nil || validateAccessTokenStructure(parsedToken.Payload) != nil {
+			continue
+		}
+		return token, tokenTransferMethod, getDPoPThumbprintOfParsedAccessToken(parsedToken), nil
+	}
+	return nil, "", nil, nil
+}
+
+// getDPoPThumbprintOfAccessToken returns the thumbprint of the DPoP key accessToken is bound to, if any. The signature
+// of accessToken is not verified, which is left to the verification of the session.
+func getDPoPThumbprintOfAccessToken(accessToken string) *string {
+	parsedToken, err := parseJWTWithoutSignatureVerification(accessToken)
+	if err != nil || validateAccessTokenStructure(parsedToken.Payload) != nil {
+		return nil
+	}
+	return getDPoPThumbprintOfParsedAccessToken(parsedToken)
+}
+
+func getDPoPThumbprintOfParsedAccessToken(parsedToken ParsedJWTInfo) *string {
+	userData, _ := parsedToken.Payload["userData"].(map[string]interface{})
+	return getDPoPThumbprintFromAccessTokenPayload(userData)
+}
+
+// checkDPoPProofOfRequest checks that req has a valid DPoP proof for accessToken, signed by the key with boundThumbprint
+func checkDPoPProofOfRequest(config sessmodels.DPoPNormalisedConfig, req *http.Request, accessToken *string, boundThumbprint string, userContext supertokens.UserContext) error {
+	proof := getHeader(req, dpopHeaderKey)
+	if proof == nil {
+		return newInvalidDPoPProofError("a DPoP proof is required for a DPoP bound access token", false)
+	}
+	thumbprint, err := verifyDPoPProof(config, req, *proof, accessToken, userContext)
+	if err != nil {
+		return err
+	}
+	if thumbprint != boundThumbprint {
+		return newInvalidDPoPProofError("the DPoP proof is not signed by the key the access token is bound to", false)
+	}
+	return nil
+}
+
+// verifyDPoPProof checks the signature, method, URL, issue time and ID of proof, and its access token hash if
+// accessToken is not nil, and returns the thumbprint of its key
+func verifyDPoPProof(config sessmodels.DPoPNormalisedConfig, req *http.Request, proof string, accessToken *string, userContext supertokens.UserContext) (string, error) {
+	var jwk map[string]interface{}
+	proofClaims := jwt.MapClaims{}
+	parser := jwt.Parser{ValidMethods: config.AllowedAlgorithms, SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(proof, proofClaims, func(token *jwt.Token) (interface{}, error) {
+		if token.Header["typ"] != "dpop+jwt" {
+			return nil, defaultErrors.New("the typ of the proof must be dpop+jwt")
+		}
+		var ok bool
+		jwk, ok = token.Header["jwk"].(map[string]interface{})
+		if !ok {
+			return nil, defaultErrors.New("the proof must have a jwk header")
+		}
+		return getPublicKeyFromDPoPJWK(jwk)
+	})
+	if err != nil {
+		return "", newInvalidDPoPProofError("invalid DPoP proof: "+err.Error(), false)
+	}
+
+	if proofClaims["htm"] != req.Method {
+		return "", newInvalidDPoPProofError("the DPoP proof is not for the method of the request", false)
+	}
+	htu, _ := proofClaims["htu"].(string)
+	if normaliseDPoPURL(htu) != normaliseDPoPURL(config.GetRequestURL(req, userContext)) {
+		return "", newInvalidDPoPProofError("the DPoP proof is not for the URL of the request", false)
+	}
+	iat, ok := proofClaims["iat"].(float64)
+	if !ok {
+		return "", newInvalidDPoPProofError("the DPoP proof must have an iat claim", false)
+	}
+	issuedAt := time.Unix(int64(iat), 0)
+	if age := time.Since(issuedAt); age > config.MaxProofAge || age < -config.MaxProofAge {
+		return "", newInvalidDPoPProofError("the DPoP proof is too old or issued in the future", false)
+	}
+	if accessToken != nil {
+		accessTokenHash := sha256.Sum256([]byte(*accessToken))
+		if proofClaims["ath"] != base64.RawURLEncoding.EncodeToString(accessTokenHash[:]) {
+			return "", newInvalidDPoPProofError("the DPoP proof is not for the access token of the request", false)
+		}
+	}
+	jti, _ := proofClaims["jti"].(string)
+	if jti == "" {
+		return "", newInvalidDPoPProofError("the DPoP proof must have a jti claim", false)
+	}
+	isNew, err := config.ReplayCache.Add(jti, issuedAt.Add(config.MaxProofAge), userContext)
+	if err != nil {
+		return "", err
+	}
+	if !isNew {
+		return "", newInvalidDPoPProofError("the DPoP proof was already used", false)
+	}
+
+	return getDPoPJWKThumbprint(jwk)
+}
+
+// normaliseDPoPURL removes the query and fragment of a URL and lowercases its scheme and host, as they are not
+// compared when checking the htu claim of a proof
+func normaliseDPoPURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Host == "" {
+		return rawURL
+	}
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+	parsedURL.RawQuery = ""
+	parsedURL.Fragment = ""
+	if parsedURL.Path == "" {
+		parsedURL.Path = "/"
+	}
+	return parsedURL.String()
+}
+
+func getPublicKeyFromDPoPJWK(jwk map[string]interface{}) (interface{}, error) {
+	if _, ok := jwk["d"]; ok {
+		return nil, defaultErrors.New("the jwk of the proof cannot be a private key")
+	}
+	getBytes := func(member string) ([]byte, error) {
+		value, _ := jwk[member].(string)
+		decoded, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(decoded) == 0 {
+			return nil, defaultErrors.New("invalid " + member + " in the jwk of the proof")
+		}
+		return decoded, nil
+	}
+
+	switch jwk["kty"] {
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk["crv"] {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, defaultErrors.New("unsupported crv in the jwk of the proof")
+		}
+		x, err := getBytes("x")
+		if err != nil {
+			return nil, err
+		}
+		y, err := getBytes("y")
+		if err != nil {
+			return nil, err
+		}
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, defaultErrors.New("invalid point in the jwk of the proof")
+		}
+		return publicKey, nil
+	case "RSA":
+		n, err := getBytes("n")
+		if err != nil {
+			return nil, err
+		}
+		e, err := getBytes("e")
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, defaultErrors.New("invalid e in the jwk of the proof")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "OKP":
+		if jwk["crv"] != "Ed25519" {
+			return nil, defaultErrors.New("unsupported crv in the jwk of the proof")
+		}
+		x, err := getBytes("x")
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, defaultErrors.New("invalid x in the jwk of the proof")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, defaultErrors.New("unsupported kty in the jwk of the proof")
+}
+
+// getDPoPJWKThumbprint returns the SHA-256 thumbprint of jwk (RFC 7638), which is the hash of its required members
+// serialised in lexicographic order without whitespace
+func getDPoPJWKThumbprint(jwk map[string]interface{}) (string, error) {
+	var members []string
+	switch jwk["kty"] {
+	case "EC":
+		members = []string{"crv", "kty", "x", "y"}
+	case "RSA":
+		members = []string{"e", "kty", "n"}
+	case "OKP":
+		members = []string{"crv", "kty", "x"}
+	default:
+		return "", defaultErrors.New("unsupported kty in the jwk of the proof")
+	}
+	requiredMembers := map[string]interface{}{}
+	for _, member := range members {
+		requiredMembers[member] = jwk[member]
+	}
+	// the keys of a map are serialised in lexicographic order
+	serialised, err := json.Marshal(requiredMembers)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(serialised)
+	return base64.RawURLEncoding.EncodeToString(hash[:]), nil
+}
+
+func addDPoPConfirmationToAccessTokenPayload(accessTokenPayload map[string]interface{}, thumbprint string) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range accessTokenPayload {
+		result[key] = value
+	}
+	result[dpopConfirmationPayloadKey] = map[string]interface{}{
+		"jkt": thumbprint,
+	}
+	return result
+}
+
+func getDPoPThumbprintFromAccessTokenPayload(accessTokenPayload map[string]interface{}) *string {
+	confirmation, ok := accessTokenPayload[dpopConfirmationPayloadKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	thumbprint, ok := confirmation["jkt"].(string)
+	if !ok {
+		return nil
+	}
+	return &thumbprint
+}
+
+func isSupportedDPoPAlgorithm(algorithm string) bool {
+	for _, supportedAlgorithm := range supportedDPoPAlgorithms {
+		if algorithm == supportedAlgorithm {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryDPoPReplayCache is the default sessmodels.DPoPReplayCache, which keeps the IDs of the proofs in memory
+type memoryDPoPReplayCache struct {
+	mutex      sync.Mutex
+	expires    map[string]time.Time
+	lastPruned time.Time
+}
+
+func newMemoryDPoPReplayCache() *memoryDPoPReplayCache {
+	return &memoryDPoPReplayCache{
+		expires: map[string]time.Time{},
+	}
+}
+
+func (c *memoryDPoPReplayCache) Add(jti string, expiresAt time.Time, _ supertokens.UserContext) (bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	now := time.Now()
+	// the expired IDs are removed at most once per second, so that adding an ID does not always go through all of them
+	if now.Sub(c.lastPruned) >= time.Second {
+		for otherJTI, otherExpiresAt := range c.expires {
+			if !now.Before(otherExpiresAt) {
+				delete(c.expires, otherJTI)
+			}
+		}
+		c.lastPruned = now
+	}
+	if otherExpiresAt, ok := c.expires[jti]; ok && now.Before(otherExpiresAt) {
+		return false, nil
+	}
+	c.expires[jti] = expiresAt
+	return true, nil
+}
@@ -0,0 +1,410 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	defaultErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func makeDPoPProofForTest(t *testing.T, key *ecdsa.PrivateKey, method string, url string, accessToken *string, iat time.Time, jti string) string {
+	proofClaims := jwt.MapClaims{
+		"htm": method,
+		"htu": url,
+		"iat": iat.Unix(),
+		"jti": jti,
+	}
+	if accessToken != nil {
+		accessTokenHash := sha256.Sum256([]byte(*accessToken))
+		proofClaims["ath"] = base64.RawURLEncoding.EncodeToString(accessTokenHash[:])
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, proofClaims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = map[string]interface{}{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	proof, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return proof
+}
+
+func TestDPoPConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "api.supertokens.io",
+		WebsiteDomain: "supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{DPoP: &sessmodels.DPoPConfig{}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultDPoPMaxProofAge, config.DPoP.MaxProofAge)
+	assert.Equal(t, supportedDPoPAlgorithms, config.DPoP.AllowedAlgorithms)
+	assert.NotNil(t, config.DPoP.ReplayCache)
+	assert.Equal(t, "https://api.supertokens.io/auth/session/refresh", config.DPoP.GetRequestURL(httptest.NewRequest("POST", "/auth/session/refresh?a=b", nil), &map[string]interface{}{}))
+
+	maxProofAge := time.Duration(0)
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{DPoP: &sessmodels.DPoPConfig{MaxProofAge: &maxProofAge}})
+	assert.EqualError(t, err, "DPoP.MaxProofAge must be positive")
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{DPoP: &sessmodels.DPoPConfig{AllowedAlgorithms: []string{"HS256"}}})
+	assert.EqualError(t, err, "DPoP.AllowedAlgorithms must only contain ES256, ES384, RS256, PS256, EdDSA, got: HS256")
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{DPoP: &sessmodels.DPoPConfig{AllowedAlgorithms: []string{}}})
+	assert.EqualError(t, err, "DPoP.AllowedAlgorithms cannot be empty")
+}
+
+func TestVerifyDPoPProof(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	config := sessmodels.DPoPNormalisedConfig{
+		MaxProofAge:       time.Minute,
+		AllowedAlgorithms: supportedDPoPAlgorithms,
+		GetRequestURL: func(req *http.Request, userContext supertokens.UserContext) string {
+			return "https://api.supertokens.io" + req.URL.Path
+		},
+		ReplayCache: newMemoryDPoPReplayCache(),
+	}
+	req := httptest.NewRequest("GET", "/user?a=b", nil)
+	accessToken := "accessToken"
+	userContext := &map[string]interface{}{}
+	isInvalidProof := func(err error) bool {
+		unauthorizedError := errors.UnauthorizedError{}
+		return defaultErrors.As(err, &unauthorizedError) && *unauthorizedError.Reason == errors.InvalidDPoPProofReason && !*unauthorizedError.ClearTokens
+	}
+
+	thumbprint, err := verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://API.supertokens.io/user?c=d", &accessToken, time.Now(), "1"), &accessToken, userContext)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, thumbprint)
+
+	// a proof can only be used once
+	proof := makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/user", &accessToken, time.Now(), "2")
+	otherThumbprint, err := verifyDPoPProof(config, req, proof, &accessToken, userContext)
+	assert.NoError(t, err)
+	assert.Equal(t, thumbprint, otherThumbprint)
+	_, err = verifyDPoPProof(config, req, proof, &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "POST", "https://api.supertokens.io/user", &accessToken, time.Now(), "3"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/other", &accessToken, time.Now(), "4"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/user", &accessToken, time.Now().Add(-2*time.Minute), "5"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/user", &accessToken, time.Now().Add(2*time.Minute), "6"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+	otherAccessToken := "otherAccessToken"
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/user", &otherAccessToken, time.Now(), "7"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/user", nil, time.Now(), "8"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+	_, err = verifyDPoPProof(config, req, "not a proof", &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+
+	config.AllowedAlgorithms = []string{"RS256"}
+	_, err = verifyDPoPProof(config, req, makeDPoPProofForTest(t, key, "GET", "https://api.supertokens.io/user", &accessToken, time.Now(), "9"), &accessToken, userContext)
+	assert.True(t, isInvalidProof(err))
+}
+
+func TestDPoPProofWithoutTypOrWithPrivateKeyIsRejected(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	config := sessmodels.DPoPNormalisedConfig{
+		MaxProofAge:       time.Minute,
+		AllowedAlgorithms: supportedDPoPAlgorithms,
+		GetRequestURL: func(req *http.Request, userContext supertokens.UserContext) string {
+			return "https://api.supertokens.io" + req.URL.Path
+		},
+		ReplayCache: newMemoryDPoPReplayCache(),
+	}
+	req := httptest.NewRequest("POST", "/auth/session/refresh", nil)
+	makeProof := func(header map[string]interface{}, jti string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"htm": "POST",
+			"htu": "https://api.supertokens.io/auth/session/refresh",
+			"iat": time.Now().Unix(),
+			"jti": jti,
+		})
+		for key, value := range header {
+			token.Header[key] = value
+		}
+		proof, err := token.SignedString(privateKey)
+		assert.NoError(t, err)
+		return proof
+	}
+	jwk := map[string]interface{}{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"x":   base64.RawURLEncoding.EncodeToString(privateKey.Public().(ed25519.PublicKey)),
+	}
+
+	_, err = verifyDPoPProof(config, req, makeProof(map[string]interface{}{"typ": "dpop+jwt", "jwk": jwk}, "1"), nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	_, err = verifyDPoPProof(config, req, makeProof(map[string]interface{}{"jwk": jwk}, "2"), nil, &map[string]interface{}{})
+	assert.Error(t, err)
+
+	jwkWithPrivateKey := map[string]interface{}{"d": base64.RawURLEncoding.EncodeToString(privateKey.Seed())}
+	for key, value := range jwk {
+		jwkWithPrivateKey[key] = value
+	}
+	_, err = verifyDPoPProof(config, req, makeProof(map[string]interface{}{"typ": "dpop+jwt", "jwk": jwkWithPrivateKey}, "3"), nil, &map[string]interface{}{})
+	assert.Error(t, err)
+}
+
+func TestGetDPoPJWKThumbprint(t *testing.T) {
+	// the example of RFC 7638
+	thumbprint, err := getDPoPJWKThumbprint(map[string]interface{}{
+		"kty": "RSA",
+		"n":   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		"e":   "AQAB",
+		"alg": "RS256",
+		"kid": "2011-04-29",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", thumbprint)
+}
+
+func TestMemoryDPoPReplayCache(t *testing.T) {
+	cache := newMemoryDPoPReplayCache()
+	isNew, err := cache.Add("1", time.Now().Add(time.Minute), nil)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+	isNew, err = cache.Add("1", time.Now().Add(time.Minute), nil)
+	assert.NoError(t, err)
+	assert.False(t, isNew)
+
+	isNew, err = cache.Add("2", time.Now().Add(-time.Second), nil)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+	// the expired IDs are removed
+	cache.lastPruned = time.Time{}
+	isNew, err = cache.Add("3", time.Now().Add(time.Minute), nil)
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+	assert.Len(t, cache.expires, 2)
+}
+
+func TestGetTokenWithDPoPScheme(t *testing.T) {
+	config := sessmodels.TypeNormalisedInput{}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "DPoP accessToken")
+	token, err := getToken(config, req, sessmodels.AccessToken, sessmodels.HeaderTransferMethod)
+	assert.NoError(t, err)
+	assert.Equal(t, "accessToken", *token)
+
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	token, err = getToken(config, req, sessmodels.AccessToken, sessmodels.HeaderTransferMethod)
+	assert.NoError(t, err)
+	assert.Nil(t, token)
+}
+
+func TestDPoPAgainstFakeCore(t *testing.T) {
+	_, testServer := startFakeCoreForTest(t, &sessmodels.TypeInput{
+		DPoP: &sessmodels.DPoPConfig{Required: true},
+	})
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	proofCount := 0
+	makeProof := func(key *ecdsa.PrivateKey, method string, url string, accessToken *string) string {
+		proofCount++
+		return makeDPoPProofForTest(t, key, method, url, accessToken, time.Now(), strconv.Itoa(proofCount))
+	}
+
+	// a session cannot be created without a proof when they are required
+	req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+	req.Header.Set("st-auth-mode", "header")
+	_, err = CreateNewSession(req, httptest.NewRecorder(), "userId", nil, nil)
+	unauthorizedError := errors.UnauthorizedError{}
+	assert.True(t, defaultErrors.As(err, &unauthorizedError))
+	assert.Equal(t, errors.InvalidDPoPProofReason, *unauthorizedError.Reason)
+
+	req.Header.Set("DPoP", makeProof(key, http.MethodPost, "https://api.supertokens.io/signin", nil))
+	newSession, err := CreateNewSession(req, httptest.NewRecorder(), "userId", nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, newSession.GetAccessTokenPayload(), "cnf")
+	accessToken := newSession.GetAccessToken()
+	refreshToken := *newSession.GetAllSessionTokensDangerously().RefreshToken
+
+	sendRequest := func(method string, path string, token string, proof *string) *http.Response {
+		req, err := http.NewRequest(method, testServer.URL+path, nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "DPoP "+token)
+		req.Header.Set("st-auth-mode", "header")
+		if proof != nil {
+			req.Header.Set("DPoP", *proof)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res
+	}
+
+	proof := makeProof(key, http.MethodGet, "https://api.supertokens.io/verify", &accessToken)
+	res := sendRequest(http.MethodGet, "/verify", accessToken, &proof)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	// the proof cannot be replayed, and the access token cannot be used without a proof or with another key
+	res = sendRequest(http.MethodGet, "/verify", accessToken, &proof)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Contains(t, res.Header.Get("WWW-Authenticate"), `DPoP error="invalid_dpop_proof"`)
+	res = sendRequest(http.MethodGet, "/verify", accessToken, nil)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	otherProof := makeProof(otherKey, http.MethodGet, "https://api.supertokens.io/verify", &accessToken)
+	res = sendRequest(http.MethodGet, "/verify", accessToken, &otherProof)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	// the session is verified with the request in the userContext when there is no request
+	verifyReq := httptest.NewRequest(http.MethodGet, "/verify", nil)
+	verifyReq.Header.Set("DPoP", makeProof(key, http.MethodGet, "https://api.supertokens.io/verify", &accessToken))
+	_, err = GetSessionWithoutRequestResponseWithContext(accessToken, nil, nil, supertokens.MakeDefaultUserContextFromAPI(verifyReq))
+	assert.NoError(t, err)
+	_, err = GetSessionWithoutRequestResponse(accessToken, nil, nil)
+	assert.True(t, defaultErrors.As(err, &unauthorizedError))
+
+	// the session can be refreshed with a proof signed by its key
+	proof = makeProof(key, http.MethodPost, "https://api.supertokens.io/auth/session/refresh", nil)
+	res = sendRequest(http.MethodPost, "/auth/session/refresh", refreshToken, &proof)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	refreshToken = res.Header.Get("st-refresh-token")
+	assert.NotEmpty(t, refreshToken)
+
+	// refreshing it with another key revokes it
+	otherProof = makeProof(otherKey, http.MethodPost, "https://api.supertokens.io/auth/session/refresh", nil)
+	res = sendRequest(http.MethodPost, "/auth/session/refresh", refreshToken, &otherProof)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	sessionInformation, err := GetSessionInformation(newSession.GetHandle())
+	assert.NoError(t, err)
+	assert.Nil(t, sessionInformation)
+}
+
+func TestDPoPProofIsCheckedBeforeVerifyingTheSessionAgainstFakeCore(t *testing.T) {
+	timeout := time.Hour
+	activityUpdateInterval := time.Millisecond
+	_, testServer := startFakeCoreForTest(t, &sessmodels.TypeInput{
+		DPoP:        &sessmodels.DPoPConfig{},
+		IdleTimeout: &sessmodels.IdleTimeoutConfig{Timeout: &timeout, ActivityUpdateInterval: &activityUpdateInterval},
+	})
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+	req.Header.Set("st-auth-mode", "header")
+	req.Header.Set("DPoP", makeDPoPProofForTest(t, key, http.MethodPost, "https://api.supertokens.io/signin", nil, time.Now(), "1"))
+	newSession, err := CreateNewSession(req, httptest.NewRecorder(), "userId", nil, nil)
+	assert.NoError(t, err)
+	accessToken := newSession.GetAccessToken()
+	getLastActivity := func() interface{} {
+		sessionInformation, err := GetSessionInformation(newSession.GetHandle())
+		assert.NoError(t, err)
+		return sessionInformation.AccessTokenPayload[idleTimeoutPayloadKey].(map[string]interface{})["la"]
+	}
+	lastActivity := getLastActivity()
+	time.Sleep(5 * time.Millisecond)
+
+	verify := func(proof *string) int {
+		req, err := http.NewRequest(http.MethodGet, testServer.URL+"/verify", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", "DPoP "+accessToken)
+		req.Header.Set("st-auth-mode", "header")
+		if proof != nil {
+			req.Header.Set("DPoP", *proof)
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// the bound access token used without a proof does not update the time of the last activity
+	assert.Equal(t, http.StatusUnauthorized, verify(nil))
+	assert.Equal(t, lastActivity, getLastActivity())
+
+	proof := makeDPoPProofForTest(t, key, http.MethodGet, "https://api.supertokens.io/verify", &accessToken, time.Now(), "2")
+	assert.Equal(t, http.StatusOK, verify(&proof))
+	assert.NotEqual(t, lastActivity, getLastActivity())
+}
+
+type refreshCountingInstrumentationForTest struct {
+	refreshes int32
+}
+
+func (i *refreshCountingInstrumentationForTest) StartSpan(ctx context.Context, name string, fields []supertokens.LogField) (context.Context, supertokens.EndSpanFunc) {
+	return ctx, func(err error) {}
+}
+
+func (i *refreshCountingInstrumentationForTest) IncrementCounter(ctx context.Context, name string, fields []supertokens.LogField) {
+	if name == supertokens.CounterNameRefreshes {
+		atomic.AddInt32(&i.refreshes, 1)
+	}
+}
+
+func TestDPoPProofIsVerifiedBeforeRefreshingWithoutRequestResponseAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+	instrumentation := &refreshCountingInstrumentationForTest{}
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{
+		RecipeList:      []supertokens.Recipe{Init(&sessmodels.TypeInput{DPoP: &sessmodels.DPoPConfig{}})},
+		Instrumentation: instrumentation,
+	})
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/signin", nil)
+	req.Header.Set("st-auth-mode", "header")
+	req.Header.Set("DPoP", makeDPoPProofForTest(t, key, http.MethodPost, "https://api.supertokens.io/signin", nil, time.Now(), "1"))
+	newSession, err := CreateNewSession(req, httptest.NewRecorder(), "userId", nil, nil)
+	assert.NoError(t, err)
+	refreshToken := *newSession.GetAllSessionTokensDangerously().RefreshToken
+
+	// an invalid proof does not rotate the refresh token
+	refreshReq := httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	refreshReq.Header.Set("DPoP", makeDPoPProofForTest(t, key, http.MethodGet, "https://api.supertokens.io/refresh", nil, time.Now(), "2"))
+	_, err = RefreshSessionWithoutRequestResponseWithContext(refreshToken, true, nil, supertokens.MakeDefaultUserContextFromAPI(refreshReq))
+	unauthorizedError := errors.UnauthorizedError{}
+	assert.True(t, defaultErrors.As(err, &unauthorizedError))
+	assert.Equal(t, errors.InvalidDPoPProofReason, *unauthorizedError.Reason)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&instrumentation.refreshes))
+
+	refreshReq.Header.Set("DPoP", makeDPoPProofForTest(t, key, http.MethodPost, "https://api.supertokens.io/refresh", nil, time.Now(), "3"))
+	refreshedSession, err := RefreshSessionWithoutRequestResponseWithContext(refreshToken, true, nil, supertokens.MakeDefaultUserContextFromAPI(refreshReq))
+	assert.NoError(t, err)
+	assert.Equal(t, newSession.GetHandle(), refreshedSession.GetHandle())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&instrumentation.refreshes))
+}
@@ -18,10 +18,25 @@ package errors
 import "github.com/supertokens/supertokens-golang/recipe/session/claims"
 
 const (
-	UnauthorizedErrorStr       = "UNAUTHORISED"
-	TryRefreshTokenErrorStr    = "TRY_REFRESH_TOKEN"
-	TokenTheftDetectedErrorStr = "TOKEN_THEFT_DETECTED"
-	InvalidClaimsErrorStr      = "INVALID_CLAIMS"
+	UnauthorizedErrorStr        = "UNAUTHORISED"
+	TryRefreshTokenErrorStr     = "TRY_REFRESH_TOKEN"
+	TokenTheftDetectedErrorStr  = "TOKEN_THEFT_DETECTED"
+	InvalidClaimsErrorStr       = "INVALID_CLAIMS"
+	SessionLimitReachedErrorStr = "SESSION_LIMIT_REACHED"
+)
+
+// Reasons set in UnauthorizedError.Reason
+const (
+	// IdleTimeoutReason is used when the session was revoked because it was not used for longer than its idle timeout
+	IdleTimeoutReason = "IDLE_TIMEOUT"
+	// SessionRevokedReason is used when the session of a long-lived connection was revoked after the connection was opened
+	SessionRevokedReason = "SESSION_REVOKED"
+	// FingerprintMismatchReason is used when the session was revoked because it was used by a client that
+	// does not match its fingerprint
+	FingerprintMismatchReason = "FINGERPRINT_MISMATCH"
+	// InvalidDPoPProofReason is used when the DPoP proof of a request using a sender-constrained access token
+	// is missing or invalid
+	InvalidDPoPProofReason = "INVALID_DPOP_PROOF"
 )
 
 // TryRefreshTokenError used for when the refresh API needs to be called
@@ -52,12 +67,24 @@ func (err TokenTheftDetectedError) Error() string {
 type UnauthorizedError struct {
 	Msg         string
 	ClearTokens *bool
+	// Reason is set if the user was logged out for a reason the frontend can show, like IdleTimeoutReason
+	Reason *string
 }
 
 func (err UnauthorizedError) Error() string {
 	return err.Msg
 }
 
+// SessionLimitReachedError used for when a session cannot be created because the user already has as many sessions as allowed
+type SessionLimitReachedError struct {
+	Msg    string
+	UserID string
+}
+
+func (err SessionLimitReachedError) Error() string {
+	return err.Msg
+}
+
 type InvalidClaimError struct {
 	Msg           string
 	InvalidClaims []claims.ClaimValidationError
@@ -0,0 +1,56 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+// initWithFakeCoreForTest initialises the SDK with the session recipe, pointing it to core, and returns a server
+// handling the APIs of the SDK and /verify, which responds with the user id of the session of the request
+func initWithFakeCoreForTest(t *testing.T, core *fakecore.Server, config *sessmodels.TypeInput) *httptest.Server {
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{RecipeList: []supertokens.Recipe{Init(config)}})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", VerifySession(nil, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(GetSessionFromRequestContext(r.Context()).GetUserID()))
+	}))
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	t.Cleanup(testServer.Close)
+	return testServer
+}
+
+// startFakeCoreForTest starts a fake core for the test and initialises the SDK with it, like initWithFakeCoreForTest
+func startFakeCoreForTest(t *testing.T, config *sessmodels.TypeInput) (*fakecore.Server, *httptest.Server) {
+	core := fakecore.StartForTest(t, resetAll)
+	return core, initWithFakeCoreForTest(t, core, config)
+}
+
+func verifyRequestWithCookieForTest(testServerURL string, accessToken string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, testServerURL+"/verify", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Cookie", "sAccessToken="+accessToken)
+	return http.DefaultClient.Do(req)
+}
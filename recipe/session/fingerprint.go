@@ -0,0 +1,175 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// newFingerprintClaim returns the claim holding the fingerprint of the client that created a session, and the
+// validator comparing it with the client of the current request, which is read from the userContext
+func newFingerprintClaim(config sessmodels.FingerprintNormalisedConfig) (*claims.TypeSessionClaim, claims.SessionClaimValidator) {
+	fingerprintClaim, validators := claims.FingerprintClaim(fingerprintClaimKey, func(userContext supertokens.UserContext) map[string]interface{} {
+		req := getRequestFromUserContext(userContext)
+		if req == nil {
+			return nil
+		}
+		return getFingerprintOfRequest(config, req, userContext)
+	})
+	return fingerprintClaim, validators.MatchesRequest(nil)
+}
+
+// makeRecipeImplementationWithFingerprint changes the functions creating and verifying sessions of
+// originalImplementation so that they store the fingerprint of the client in the new sessions, and
+// handle the clients that do not match the fingerprint of their session according to config.MismatchPolicy
+func makeRecipeImplementationWithFingerprint(originalImplementation sessmodels.RecipeInterface, config sessmodels.FingerprintNormalisedConfig, fingerprintClaim *claims.TypeSessionClaim, validator claims.SessionClaimValidator) sessmodels.RecipeInterface {
+	addFingerprintToAccessTokenPayload := func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+		result := map[string]interface{}{}
+		for key, value := range accessTokenPayload {
+			result[key] = value
+		}
+		return fingerprintClaim.Build(userID, result, userContext)
+	}
+
+	{
+		originalCreateNewSession := *originalImplementation.CreateNewSession
+
+		(*originalImplementation.CreateNewSession) = func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			userContext = setRequestInUserContext(userContext, req)
+			accessTokenPayload, err := addFingerprintToAccessTokenPayload(userID, accessTokenPayload, userContext)
+			if err != nil {
+				return nil, err
+			}
+			return originalCreateNewSession(req, res, userID, accessTokenPayload, sessionData, userContext)
+		}
+	}
+
+	{
+		originalGetSession := *originalImplementation.GetSession
+
+		(*originalImplementation.GetSession) = func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			// the request is needed by the validator, which can also be run by the global claim validators
+			userContext = setRequestInUserContext(userContext, req)
+			sessionContainer, err := originalGetSession(req, res, options, userContext)
+			if err != nil || sessionContainer == nil {
+				return sessionContainer, err
+			}
+			return checkFingerprint(config, validator, sessionContainer, options, userContext)
+		}
+	}
+
+	{
+		originalCreateNewSessionWithoutRequestResponse := *originalImplementation.CreateNewSessionWithoutRequestResponse
+
+		(*originalImplementation.CreateNewSessionWithoutRequestResponse) = func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			accessTokenPayload, err := addFingerprintToAccessTokenPayload(userID, accessTokenPayload, userContext)
+			if err != nil {
+				return nil, err
+			}
+			return originalCreateNewSessionWithoutRequestResponse(userID, accessTokenPayload, sessionData, disableAntiCsrf, userContext)
+		}
+	}
+
+	{
+		originalGetSessionWithoutRequestResponse := *originalImplementation.GetSessionWithoutRequestResponse
+
+		(*originalImplementation.GetSessionWithoutRequestResponse) = func(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalGetSessionWithoutRequestResponse(accessToken, antiCsrfToken, options, userContext)
+			if err != nil || sessionContainer == nil {
+				return sessionContainer, err
+			}
+			return checkFingerprint(config, validator, sessionContainer, options, userContext)
+		}
+	}
+
+	return originalImplementation
+}
+
+// checkFingerprint handles the mismatches between the client of the request and the fingerprint of the session
+// for the LOG and REVOKE policies. With the INVALID_CLAIM policy, the validator is a global claim validator instead.
+func checkFingerprint(config sessmodels.FingerprintNormalisedConfig, validator claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	if config.MismatchPolicy != sessmodels.LogFingerprintMismatchPolicy && config.MismatchPolicy != sessmodels.RevokeFingerprintMismatchPolicy {
+		return sessionContainer, nil
+	}
+	result := validator.Validate(sessionContainer.GetAccessTokenPayloadWithContext(userContext), userContext)
+	if result.IsValid {
+		return sessionContainer, nil
+	}
+
+	if config.MismatchPolicy == sessmodels.LogFingerprintMismatchPolicy {
+		supertokens.LogWarnMessage("getSession: the client does not match the fingerprint of the session",
+			supertokens.RecipeIDLogField(RECIPE_ID),
+			supertokens.SessionHandleLogField(sessionContainer.GetHandleWithContext(userContext)),
+			supertokens.UserIDLogField(sessionContainer.GetUserIDWithContext(userContext)),
+			supertokens.LogField{Key: LogKeyFingerprintMismatches, Value: result.Reason.(map[string]interface{})["mismatches"]},
+		)
+		return sessionContainer, nil
+	}
+
+	supertokens.LogDebugMessage("getSession: revoking session because the client does not match its fingerprint")
+	err := sessionContainer.RevokeSessionWithContext(userContext)
+	if err != nil {
+		return nil, err
+	}
+	if options != nil && options.SessionRequired != nil && !*options.SessionRequired {
+		return nil, nil
+	}
+	clearTokens := true
+	reason := errors.FingerprintMismatchReason
+	return nil, errors.UnauthorizedError{Msg: "the client does not match the fingerprint of the session", ClearTokens: &clearTokens, Reason: &reason}
+}
+
+// getFingerprintOfRequest returns the hashes of the characteristics of the client of req bound by config
+func getFingerprintOfRequest(config sessmodels.FingerprintNormalisedConfig, req *http.Request, userContext supertokens.UserContext) map[string]interface{} {
+	fingerprint := map[string]interface{}{}
+	if config.BindIPSubnet {
+		fingerprint["ip"] = hashFingerprintValue(getIPSubnet(config.GetIPAddress(req, userContext), config.IPv4SubnetPrefixLength, config.IPv6SubnetPrefixLength))
+	}
+	if config.BindUserAgent {
+		fingerprint["ua"] = hashFingerprintValue(req.Header.Get("User-Agent"))
+	}
+	if config.HeaderName != nil {
+		fingerprint["h"] = hashFingerprintValue(req.Header.Get(*config.HeaderName))
+	}
+	return fingerprint
+}
+
+// getIPSubnet returns the subnet of ipAddress in CIDR notation, or ipAddress itself if it cannot be parsed
+func getIPSubnet(ipAddress string, ipv4PrefixLength int, ipv6PrefixLength int) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ipAddress
+	}
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(ipv4PrefixLength, 32)).String() + "/" + strconv.Itoa(ipv4PrefixLength)
+	}
+	return ip.Mask(net.CIDRMask(ipv6PrefixLength, 128)).String() + "/" + strconv.Itoa(ipv6PrefixLength)
+}
+
+// hashFingerprintValue hashes the characteristics of the clients, since the access token payload can be read by them
+func hashFingerprintValue(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
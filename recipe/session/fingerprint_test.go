@@ -0,0 +1,172 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	defaultErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func TestFingerprintConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "api.supertokens.io",
+		WebsiteDomain: "supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Fingerprint: &sessmodels.FingerprintConfig{BindIPSubnet: true}})
+	assert.NoError(t, err)
+	assert.Equal(t, sessmodels.LogFingerprintMismatchPolicy, config.Fingerprint.MismatchPolicy)
+	assert.Equal(t, 24, config.Fingerprint.IPv4SubnetPrefixLength)
+	assert.Equal(t, 64, config.Fingerprint.IPv6SubnetPrefixLength)
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Fingerprint: &sessmodels.FingerprintConfig{}})
+	assert.EqualError(t, err, "Fingerprint must bind at least one of the IP subnet, user agent or a header")
+
+	prefixLength := 33
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Fingerprint: &sessmodels.FingerprintConfig{BindIPSubnet: true, IPv4SubnetPrefixLength: &prefixLength}})
+	assert.EqualError(t, err, "Fingerprint.IPv4SubnetPrefixLength must be between 0 and 32")
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Fingerprint: &sessmodels.FingerprintConfig{BindUserAgent: true, MismatchPolicy: "BLOCK"}})
+	assert.EqualError(t, err, "Fingerprint.MismatchPolicy must be one of IGNORE, LOG, INVALID_CLAIM or REVOKE")
+
+	headerName := ""
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{Fingerprint: &sessmodels.FingerprintConfig{HeaderName: &headerName}})
+	assert.EqualError(t, err, "Fingerprint.HeaderName cannot be empty")
+
+	// the IP address of the device info is used by default
+	config, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{
+		DeviceInfo: &sessmodels.DeviceInfoConfig{
+			GetIPAddress: func(req *http.Request, userContext supertokens.UserContext) string {
+				return "5.6.7.8"
+			},
+		},
+		Fingerprint: &sessmodels.FingerprintConfig{BindIPSubnet: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "5.6.7.8", config.Fingerprint.GetIPAddress(httptest.NewRequest("GET", "/", nil), &map[string]interface{}{}))
+}
+
+func TestGetIPSubnet(t *testing.T) {
+	assert.Equal(t, "1.2.3.0/24", getIPSubnet("1.2.3.4", 24, 64))
+	assert.Equal(t, "1.2.0.0/16", getIPSubnet("1.2.3.4", 16, 64))
+	assert.Equal(t, "2001:db8:1:2::/64", getIPSubnet("2001:db8:1:2:3:4:5:6", 24, 64))
+	assert.Equal(t, "not an ip", getIPSubnet("not an ip", 24, 64))
+}
+
+func TestGetFingerprintOfRequest(t *testing.T) {
+	headerName := "X-Device-Id"
+	config := sessmodels.FingerprintNormalisedConfig{
+		BindIPSubnet:           true,
+		IPv4SubnetPrefixLength: 24,
+		IPv6SubnetPrefixLength: 64,
+		GetIPAddress:           defaultGetIPAddress,
+		BindUserAgent:          true,
+		HeaderName:             &headerName,
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("X-Device-Id", "device")
+	fingerprint := getFingerprintOfRequest(config, req, &map[string]interface{}{})
+	assert.Equal(t, map[string]interface{}{
+		"ip": hashFingerprintValue("1.2.3.0/24"),
+		"ua": hashFingerprintValue("Mozilla/5.0"),
+		"h":  hashFingerprintValue("device"),
+	}, fingerprint)
+
+	// another address of the same subnet has the same fingerprint
+	req.RemoteAddr = "1.2.3.5:5678"
+	assert.Equal(t, fingerprint, getFingerprintOfRequest(config, req, &map[string]interface{}{}))
+
+	config.BindUserAgent = false
+	config.HeaderName = nil
+	assert.Equal(t, map[string]interface{}{"ip": hashFingerprintValue("1.2.3.0/24")}, getFingerprintOfRequest(config, req, &map[string]interface{}{}))
+}
+
+func TestSetRequestInUserContext(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	userContext := setRequestInUserContext(&map[string]interface{}{}, req)
+	assert.Equal(t, req, getRequestFromUserContext(userContext))
+
+	otherReq := httptest.NewRequest("GET", "/other", nil)
+	assert.Equal(t, req, getRequestFromUserContext(setRequestInUserContext(userContext, otherReq)))
+	assert.Equal(t, req, getRequestFromUserContext(setRequestInUserContext(nil, req)))
+}
+
+func TestFingerprintAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+
+	makeRequest := func(remoteAddr string, userAgent string) supertokens.UserContext {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("User-Agent", userAgent)
+		return supertokens.MakeDefaultUserContextFromAPI(req)
+	}
+	createSession := func(policy sessmodels.FingerprintMismatchPolicy) sessmodels.SessionContainer {
+		initWithFakeCoreForTest(t, core, &sessmodels.TypeInput{
+			Fingerprint: &sessmodels.FingerprintConfig{BindIPSubnet: true, BindUserAgent: true, MismatchPolicy: policy},
+		})
+		newSession, err := CreateNewSessionWithoutRequestResponseWithContext("userId", nil, nil, true, makeRequest("1.2.3.4:5678", "Mozilla/5.0"))
+		assert.NoError(t, err)
+		return newSession
+	}
+
+	// the INVALID_CLAIM policy makes the validator fail for another client
+	newSession := createSession(sessmodels.InvalidClaimFingerprintMismatchPolicy)
+	_, err := GetSessionWithoutRequestResponseWithContext(newSession.GetAccessToken(), nil, nil, makeRequest("1.2.3.5:5678", "Mozilla/5.0"))
+	assert.NoError(t, err)
+	_, err = GetSessionWithoutRequestResponseWithContext(newSession.GetAccessToken(), nil, nil, makeRequest("5.6.7.8:5678", "Mozilla/5.0"))
+	invalidClaimError := errors.InvalidClaimError{}
+	assert.True(t, defaultErrors.As(err, &invalidClaimError))
+	assert.Equal(t, "st-fp", invalidClaimError.InvalidClaims[0].ID)
+	assert.Equal(t, []string{"ip"}, invalidClaimError.InvalidClaims[0].Reason.(map[string]interface{})["mismatches"])
+	// without a request, the fingerprint cannot be checked
+	_, err = GetSessionWithoutRequestResponse(newSession.GetAccessToken(), nil, nil)
+	assert.NoError(t, err)
+
+	// the LOG policy does not fail
+	newSession = createSession(sessmodels.LogFingerprintMismatchPolicy)
+	_, err = GetSessionWithoutRequestResponseWithContext(newSession.GetAccessToken(), nil, nil, makeRequest("1.2.3.4:5678", "curl/7.0"))
+	assert.NoError(t, err)
+
+	// the REVOKE policy revokes the session
+	newSession = createSession(sessmodels.RevokeFingerprintMismatchPolicy)
+	_, err = GetSessionWithoutRequestResponseWithContext(newSession.GetAccessToken(), nil, nil, makeRequest("1.2.3.4:5678", "curl/7.0"))
+	unauthorizedError := errors.UnauthorizedError{}
+	assert.True(t, defaultErrors.As(err, &unauthorizedError))
+	assert.Equal(t, errors.FingerprintMismatchReason, *unauthorizedError.Reason)
+	sessionInformation, err := GetSessionInformation(newSession.GetHandle())
+	assert.NoError(t, err)
+	assert.Nil(t, sessionInformation)
+
+	// the request passed to CreateNewSession is used even if the userContext does not have it
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("st-auth-mode", "header")
+	newSession, err = CreateNewSession(req, httptest.NewRecorder(), "userId", nil, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, newSession.GetAccessTokenPayload(), "st-fp")
+}
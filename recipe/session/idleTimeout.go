@@ -0,0 +1,184 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// idleTimeoutPayloadKey is the access token payload key holding the idle timeout of the session ("t")
+// and the time of its last activity ("la"), both in milliseconds
+const idleTimeoutPayloadKey = "st-idle"
+
+// makeRecipeImplementationWithIdleTimeout changes the functions creating, verifying and refreshing
+// sessions of originalImplementation so that they track the last activity of the sessions, and
+// revoke the sessions that were idle for longer than their timeout
+func makeRecipeImplementationWithIdleTimeout(originalImplementation sessmodels.RecipeInterface, config sessmodels.IdleTimeoutNormalisedConfig) sessmodels.RecipeInterface {
+	{
+		originalCreateNewSession := *originalImplementation.CreateNewSession
+
+		(*originalImplementation.CreateNewSession) = func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			accessTokenPayload = addIdleTimeoutToAccessTokenPayload(config, userID, accessTokenPayload, userContext)
+			return originalCreateNewSession(req, res, userID, accessTokenPayload, sessionData, userContext)
+		}
+	}
+
+	{
+		originalGetSession := *originalImplementation.GetSession
+
+		(*originalImplementation.GetSession) = func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalGetSession(req, res, options, userContext)
+			if err != nil || sessionContainer == nil {
+				return sessionContainer, err
+			}
+			return checkIdleTimeout(config, sessionContainer, options, userContext)
+		}
+	}
+
+	{
+		originalRefreshSession := *originalImplementation.RefreshSession
+
+		(*originalImplementation.RefreshSession) = func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalRefreshSession(req, res, userContext)
+			if err != nil {
+				return nil, err
+			}
+			return checkIdleTimeout(config, sessionContainer, nil, userContext)
+		}
+	}
+
+	{
+		originalCreateNewSessionWithoutRequestResponse := *originalImplementation.CreateNewSessionWithoutRequestResponse
+
+		(*originalImplementation.CreateNewSessionWithoutRequestResponse) = func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			accessTokenPayload = addIdleTimeoutToAccessTokenPayload(config, userID, accessTokenPayload, userContext)
+			return originalCreateNewSessionWithoutRequestResponse(userID, accessTokenPayload, sessionData, disableAntiCsrf, userContext)
+		}
+	}
+
+	{
+		originalGetSessionWithoutRequestResponse := *originalImplementation.GetSessionWithoutRequestResponse
+
+		(*originalImplementation.GetSessionWithoutRequestResponse) = func(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalGetSessionWithoutRequestResponse(accessToken, antiCsrfToken, options, userContext)
+			if err != nil || sessionContainer == nil {
+				return sessionContainer, err
+			}
+			return checkIdleTimeout(config, sessionContainer, options, userContext)
+		}
+	}
+
+	{
+		originalRefreshSessionWithoutRequestResponse := *originalImplementation.RefreshSessionWithoutRequestResponse
+
+		(*originalImplementation.RefreshSessionWithoutRequestResponse) = func(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalRefreshSessionWithoutRequestResponse(refreshToken, disableAntiCsrf, antiCsrfToken, userContext)
+			if err != nil {
+				return nil, err
+			}
+			return checkIdleTimeout(config, sessionContainer, nil, userContext)
+		}
+	}
+
+	return originalImplementation
+}
+
+func addIdleTimeoutToAccessTokenPayload(config sessmodels.IdleTimeoutNormalisedConfig, userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) map[string]interface{} {
+	timeout := config.GetTimeout(userID, accessTokenPayload, userContext)
+	if timeout <= 0 {
+		return accessTokenPayload
+	}
+	result := map[string]interface{}{}
+	for key, value := range accessTokenPayload {
+		result[key] = value
+	}
+	result[idleTimeoutPayloadKey] = map[string]interface{}{
+		"t":  uint64(timeout / time.Millisecond),
+		"la": getCurrTimeInMS(),
+	}
+	return result
+}
+
+// checkIdleTimeout revokes the session if it was idle for longer than its timeout, and otherwise
+// updates the time of its last activity if it was not updated for ActivityUpdateInterval
+func checkIdleTimeout(config sessmodels.IdleTimeoutNormalisedConfig, sessionContainer sessmodels.SessionContainer, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	timeout, lastActivity, ok := getIdleTimeoutFromAccessTokenPayload(sessionContainer.GetAccessTokenPayloadWithContext(userContext))
+	if !ok {
+		return sessionContainer, nil
+	}
+	now := getCurrTimeInMS()
+	if now > lastActivity+timeout {
+		supertokens.LogDebugMessage("getSession: revoking session because of its idle timeout")
+		err := sessionContainer.RevokeSessionWithContext(userContext)
+		if err != nil {
+			return nil, err
+		}
+		if options != nil && options.SessionRequired != nil && !*options.SessionRequired {
+			return nil, nil
+		}
+		clearTokens := true
+		reason := errors.IdleTimeoutReason
+		return nil, errors.UnauthorizedError{Msg: "session expired due to inactivity", ClearTokens: &clearTokens, Reason: &reason}
+	}
+
+	updateInterval := uint64(config.ActivityUpdateInterval / time.Millisecond)
+	if updateInterval > timeout/2 {
+		updateInterval = timeout / 2
+	}
+	if now-lastActivity >= updateInterval {
+		err := sessionContainer.MergeIntoAccessTokenPayloadWithContext(map[string]interface{}{
+			idleTimeoutPayloadKey: map[string]interface{}{
+				"t":  timeout,
+				"la": now,
+			},
+		}, userContext)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sessionContainer, nil
+}
+
+func getIdleTimeoutFromAccessTokenPayload(accessTokenPayload map[string]interface{}) (uint64, uint64, bool) {
+	idleTimeout, ok := accessTokenPayload[idleTimeoutPayloadKey].(map[string]interface{})
+	if !ok {
+		return 0, 0, false
+	}
+	timeout, ok := getUint64FromJSONNumber(idleTimeout["t"])
+	if !ok {
+		return 0, 0, false
+	}
+	lastActivity, ok := getUint64FromJSONNumber(idleTimeout["la"])
+	if !ok {
+		return 0, 0, false
+	}
+	return timeout, lastActivity, true
+}
+
+func getUint64FromJSONNumber(value interface{}) (uint64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return uint64(number), true
+	case uint64:
+		return number, true
+	}
+	return 0, false
+}
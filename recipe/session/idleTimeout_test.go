@@ -0,0 +1,123 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestIdleTimeoutConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "api.supertokens.io",
+		WebsiteDomain: "supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	timeout := time.Hour
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{IdleTimeout: &sessmodels.IdleTimeoutConfig{Timeout: &timeout}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultIdleTimeoutActivityUpdateInterval, config.IdleTimeout.ActivityUpdateInterval)
+	assert.Equal(t, time.Hour, config.IdleTimeout.GetTimeout("userId", nil, &map[string]interface{}{}))
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{IdleTimeout: &sessmodels.IdleTimeoutConfig{}})
+	assert.EqualError(t, err, "IdleTimeout.Timeout or IdleTimeout.GetTimeout must be set")
+
+	timeout = 0
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{IdleTimeout: &sessmodels.IdleTimeoutConfig{Timeout: &timeout}})
+	assert.EqualError(t, err, "IdleTimeout.Timeout must be positive")
+}
+
+func TestAddIdleTimeoutToAccessTokenPayload(t *testing.T) {
+	config := sessmodels.IdleTimeoutNormalisedConfig{
+		GetTimeout: func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) time.Duration {
+			if userID == "withoutTimeout" {
+				return 0
+			}
+			return time.Minute
+		},
+		ActivityUpdateInterval: time.Minute,
+	}
+	accessTokenPayload := map[string]interface{}{"a": "b"}
+
+	assert.Equal(t, accessTokenPayload, addIdleTimeoutToAccessTokenPayload(config, "withoutTimeout", accessTokenPayload, &map[string]interface{}{}))
+
+	result := addIdleTimeoutToAccessTokenPayload(config, "userId", accessTokenPayload, &map[string]interface{}{})
+	assert.NotContains(t, accessTokenPayload, idleTimeoutPayloadKey)
+	assert.Equal(t, "b", result["a"])
+	timeout, lastActivity, ok := getIdleTimeoutFromAccessTokenPayload(result)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(60000), timeout)
+	assert.InDelta(t, getCurrTimeInMS(), lastActivity, 1000)
+}
+
+func TestIdleTimeoutAgainstFakeCore(t *testing.T) {
+	activityUpdateInterval := time.Millisecond
+	antiCsrf := "NONE"
+	_, testServer := startFakeCoreForTest(t, &sessmodels.TypeInput{
+		AntiCsrf: &antiCsrf,
+		IdleTimeout: &sessmodels.IdleTimeoutConfig{
+			GetTimeout: func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) time.Duration {
+				if timeout, ok := (*userContext)["idleTimeout"].(time.Duration); ok {
+					return timeout
+				}
+				return 0
+			},
+			ActivityUpdateInterval: &activityUpdateInterval,
+		},
+	})
+
+	newSession, err := CreateNewSessionWithoutRequestResponseWithContext("userId", nil, nil, true, &map[string]interface{}{"idleTimeout": 200 * time.Millisecond})
+	assert.NoError(t, err)
+	accessToken := newSession.GetAllSessionTokensDangerously().AccessToken
+
+	// verifying the session updates its last activity, so it is not revoked while it is used
+	for i := 0; i < 3; i++ {
+		time.Sleep(120 * time.Millisecond)
+		verifiedSession, err := GetSessionWithoutRequestResponse(accessToken, nil, nil)
+		assert.NoError(t, err)
+		tokens := verifiedSession.GetAllSessionTokensDangerously()
+		assert.True(t, tokens.AccessAndFrontTokenUpdated)
+		accessToken = tokens.AccessToken
+	}
+
+	time.Sleep(250 * time.Millisecond)
+	res, err := verifyRequestWithCookieForTest(testServer.URL, url.QueryEscape(accessToken))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"message":"unauthorised","reason":"IDLE_TIMEOUT"}`, string(body))
+	sessionInformation, err := GetSessionInformation(newSession.GetHandle())
+	assert.NoError(t, err)
+	assert.Nil(t, sessionInformation)
+
+	sessionWithoutTimeout, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	assert.NotContains(t, sessionWithoutTimeout.GetAccessTokenPayload(), "st-idle")
+	time.Sleep(250 * time.Millisecond)
+	_, err = GetSessionWithoutRequestResponse(sessionWithoutTimeout.GetAllSessionTokensDangerously().AccessToken, nil, nil)
+	assert.NoError(t, err)
+}
@@ -37,20 +37,28 @@ func CreateNewSessionWithContext(req *http.Request, res http.ResponseWriter, use
 		return nil, err
 	}
 
-	claimsAddedByOtherRecipes := instance.getClaimsAddedByOtherRecipes()
-	finalAccessTokenPayload := accessTokenPayload
-	if finalAccessTokenPayload == nil {
-		finalAccessTokenPayload = map[string]interface{}{}
+	finalAccessTokenPayload, err := instance.addClaimsAddedByOtherRecipesToPayload(userID, accessTokenPayload, userContext)
+	if err != nil {
+		return nil, err
 	}
 
-	for _, claim := range claimsAddedByOtherRecipes {
-		finalAccessTokenPayload, err = claim.Build(userID, finalAccessTokenPayload, userContext)
-		if err != nil {
-			return nil, err
-		}
+	return (*instance.RecipeImpl.CreateNewSession)(req, res, userID, finalAccessTokenPayload, sessionData, userContext)
+}
+
+// CreateNewSessionWithoutRequestResponseWithContext creates a session without setting its tokens in a response.
+// They are returned by the session's GetAllSessionTokensDangerously function instead, to be sent to the client.
+func CreateNewSessionWithoutRequestResponseWithContext(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
 	}
 
-	return (*instance.RecipeImpl.CreateNewSession)(req, res, userID, finalAccessTokenPayload, sessionData, userContext)
+	finalAccessTokenPayload, err := instance.addClaimsAddedByOtherRecipesToPayload(userID, accessTokenPayload, userContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return (*instance.RecipeImpl.CreateNewSessionWithoutRequestResponse)(userID, finalAccessTokenPayload, sessionData, disableAntiCsrf, userContext)
 }
 
 func GetSessionWithContext(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
@@ -63,23 +71,48 @@ func GetSessionWithContext(req *http.Request, res http.ResponseWriter, options *
 		return nil, err
 	}
 
-	if sessionContainer != nil {
-		var overrideGlobalClaimValidators func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) = nil
-		if options != nil {
-			overrideGlobalClaimValidators = options.OverrideGlobalClaimValidators
-		}
-		claimValidators, err := getRequiredClaimValidators(sessionContainer, overrideGlobalClaimValidators, userContext)
-		if err != nil {
-			return nil, err
-		}
-		err = sessionContainer.AssertClaimsWithContext(claimValidators, userContext)
-		if err != nil {
-			return nil, err
-		}
+	err = assertRequiredClaims(sessionContainer, options, userContext)
+	if err != nil {
+		return nil, err
 	}
 	return sessionContainer, nil
 }
 
+// GetSessionWithoutRequestResponseWithContext verifies an access token that was not sent in a request, for example
+// by a websocket or message queue client. The anti-csrf token is only checked if options.AntiCsrfCheck is true.
+// If the access token had to be changed, the session's GetAllSessionTokensDangerously function returns the new one.
+func GetSessionWithoutRequestResponseWithContext(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	sessionContainer, err := (*instance.RecipeImpl.GetSessionWithoutRequestResponse)(accessToken, antiCsrfToken, options, userContext)
+	if err != nil {
+		return nil, err
+	}
+
+	err = assertRequiredClaims(sessionContainer, options, userContext)
+	if err != nil {
+		return nil, err
+	}
+	return sessionContainer, nil
+}
+
+func assertRequiredClaims(sessionContainer sessmodels.SessionContainer, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) error {
+	if sessionContainer == nil {
+		return nil
+	}
+	var overrideGlobalClaimValidators func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) = nil
+	if options != nil {
+		overrideGlobalClaimValidators = options.OverrideGlobalClaimValidators
+	}
+	claimValidators, err := getRequiredClaimValidators(sessionContainer, overrideGlobalClaimValidators, userContext)
+	if err != nil {
+		return err
+	}
+	return sessionContainer.AssertClaimsWithContext(claimValidators, userContext)
+}
+
 func GetSessionInformationWithContext(sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -96,6 +129,17 @@ func RefreshSessionWithContext(req *http.Request, res http.ResponseWriter, userC
 	return (*instance.RecipeImpl.RefreshSession)(req, res, userContext)
 }
 
+// RefreshSessionWithoutRequestResponseWithContext refreshes a session using a refresh token that was not sent in a
+// request. The new tokens are returned by the session's GetAllSessionTokensDangerously function. disableAntiCsrf
+// should be true unless the tokens are sent to a browser as cookies.
+func RefreshSessionWithoutRequestResponseWithContext(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+	return (*instance.RecipeImpl.RefreshSessionWithoutRequestResponse)(refreshToken, disableAntiCsrf, antiCsrfToken, userContext)
+}
+
 func RevokeAllSessionsForUserWithContext(userID string, userContext supertokens.UserContext) ([]string, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -291,6 +335,46 @@ func FetchAndSetClaimWithContext(sessionHandle string, claim *claims.TypeSession
 	return (*instance.RecipeImpl.FetchAndSetClaim)(sessionHandle, claim, userContext)
 }
 
+// RefreshClaimForAllSessionsOfUserWithContext fetches the value of claim for userID and sets it in all their sessions,
+// so that they get it without waiting for the validators of claim to refetch it. Unlike FetchAndSetClaim, the claim
+// is removed from the sessions if there is no value anymore. The sessions that are revoked meanwhile are skipped, and
+// the handles of the updated ones are returned. Like FetchAndSetClaim, this updates the access token payload stored
+// for the sessions, which is used by their next access tokens.
+func RefreshClaimForAllSessionsOfUserWithContext(userID string, claim *claims.TypeSessionClaim, userContext supertokens.UserContext) ([]string, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return nil, err
+	}
+
+	sessionHandles, err := (*instance.RecipeImpl.GetAllSessionHandlesForUser)(userID, userContext)
+	if err != nil {
+		return nil, err
+	}
+	updatedSessionHandles := []string{}
+	if len(sessionHandles) == 0 {
+		return updatedSessionHandles, nil
+	}
+	value, err := claim.FetchValue(userID, userContext)
+	if err != nil {
+		return nil, err
+	}
+	for _, sessionHandle := range sessionHandles {
+		var updated bool
+		if value == nil {
+			updated, err = (*instance.RecipeImpl.RemoveClaim)(sessionHandle, claim, userContext)
+		} else {
+			updated, err = (*instance.RecipeImpl.SetClaimValue)(sessionHandle, claim, value, userContext)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			updatedSessionHandles = append(updatedSessionHandles, sessionHandle)
+		}
+	}
+	return updatedSessionHandles, nil
+}
+
 func SetClaimValueWithContext(sessionHandle string, claim *claims.TypeSessionClaim, value interface{}, userContext supertokens.UserContext) (bool, error) {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -318,6 +402,19 @@ func RemoveClaimWithContext(sessionHandle string, claim *claims.TypeSessionClaim
 	return (*instance.RecipeImpl.RemoveClaim)(sessionHandle, claim, userContext)
 }
 
+// GetSigningKeySetStatus returns how old the keys used to verify access tokens are. It can only be
+// used if NetworklessVerification is enabled in the config of the session recipe.
+func GetSigningKeySetStatus() (sessmodels.SigningKeySetStatus, error) {
+	instance, err := getRecipeInstanceOrThrowError()
+	if err != nil {
+		return sessmodels.SigningKeySetStatus{}, err
+	}
+	if instance.signingKeySet == nil {
+		return sessmodels.SigningKeySetStatus{}, errors.New("NetworklessVerification is not enabled in the config of the session recipe")
+	}
+	return instance.signingKeySet.getStatus(), nil
+}
+
 func VerifySession(options *sessmodels.VerifySessionOptions, otherHandler http.HandlerFunc) http.HandlerFunc {
 	instance, err := getRecipeInstanceOrThrowError()
 	if err != nil {
@@ -343,6 +440,18 @@ func GetSession(req *http.Request, res http.ResponseWriter, options *sessmodels.
 	return GetSessionWithContext(req, res, options, &map[string]interface{}{})
 }
 
+func CreateNewSessionWithoutRequestResponse(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool) (sessmodels.SessionContainer, error) {
+	return CreateNewSessionWithoutRequestResponseWithContext(userID, accessTokenPayload, sessionData, disableAntiCsrf, &map[string]interface{}{})
+}
+
+func GetSessionWithoutRequestResponse(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions) (sessmodels.SessionContainer, error) {
+	return GetSessionWithoutRequestResponseWithContext(accessToken, antiCsrfToken, options, &map[string]interface{}{})
+}
+
+func RefreshSessionWithoutRequestResponse(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string) (sessmodels.SessionContainer, error) {
+	return RefreshSessionWithoutRequestResponseWithContext(refreshToken, disableAntiCsrf, antiCsrfToken, &map[string]interface{}{})
+}
+
 func GetSessionInformation(sessionHandle string) (*sessmodels.SessionInformation, error) {
 	return GetSessionInformationWithContext(sessionHandle, &map[string]interface{}{})
 }
@@ -415,6 +524,10 @@ func FetchAndSetClaim(sessionHandle string, claim *claims.TypeSessionClaim) (boo
 	return FetchAndSetClaimWithContext(sessionHandle, claim, &map[string]interface{}{})
 }
 
+func RefreshClaimForAllSessionsOfUser(userID string, claim *claims.TypeSessionClaim) ([]string, error) {
+	return RefreshClaimForAllSessionsOfUserWithContext(userID, claim, &map[string]interface{}{})
+}
+
 func SetClaimValue(sessionHandle string, claim *claims.TypeSessionClaim, value interface{}) (bool, error) {
 	return SetClaimValueWithContext(sessionHandle, claim, value, &map[string]interface{}{})
 }
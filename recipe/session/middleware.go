@@ -26,7 +26,8 @@ import (
 func VerifySessionHelper(recipeInstance Recipe, options *sessmodels.VerifySessionOptions, otherHandler http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		dw := supertokens.MakeDoneWriter(w)
-		userContext := supertokens.MakeDefaultUserContextFromAPI(r)
+		spanCtx, endSpan := supertokens.StartSpan(r.Context(), supertokens.SpanNameVerifySession, supertokens.RecipeIDLogField(recipeInstance.RecipeModule.GetRecipeID()))
+		userContext := supertokens.MakeDefaultUserContextFromAPI(r.WithContext(spanCtx))
 		session, err := (*recipeInstance.APIImpl.VerifySession)(options, sessmodels.APIOptions{
 			Config:               recipeInstance.Config,
 			OtherHandler:         otherHandler,
@@ -35,6 +36,7 @@ func VerifySessionHelper(recipeInstance Recipe, options *sessmodels.VerifySessio
 			RecipeID:             recipeInstance.RecipeModule.GetRecipeID(),
 			RecipeImplementation: recipeInstance.RecipeImpl,
 		}, userContext)
+		endSpan(err)
 		if err != nil {
 			err = supertokens.ErrorHandler(err, r, dw)
 			if err != nil {
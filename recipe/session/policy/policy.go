@@ -0,0 +1,253 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package policy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	// imported to set the claims of userrolesclaims
+	_ "github.com/supertokens/supertokens-golang/recipe/userroles"
+	"github.com/supertokens/supertokens-golang/recipe/userroles/userrolesclaims"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// Keys of the fields attached to the log events of denied requests in dry run mode
+const (
+	LogKeyPath = "path"
+	LogKeyRule = "rule"
+)
+
+// TypeInput is the policy table of a Router. It can be written in Go, or unmarshalled
+// from JSON or YAML (except for the claim validators of the rules).
+type TypeInput struct {
+	// Rules are matched in order against the method and path of each request, and the first matching rule is applied
+	Rules []Rule `json:"rules" yaml:"rules"`
+	// DefaultRule is applied to the requests that match no rule. If nil, these requests require a session, and a rule
+	// whose Public field is true must be used to pass them through without verifying their session
+	DefaultRule *Rule `json:"defaultRule,omitempty" yaml:"defaultRule,omitempty"`
+	// DryRun makes the router log the requests it would deny as warnings instead of denying them
+	DryRun bool `json:"dryRun" yaml:"dryRun"`
+}
+
+type Rule struct {
+	// Methods the rule applies to. It applies to all methods if empty
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// Path is the pattern of the paths the rule applies to. Its segments can be "*", ":name" or "{name}" to
+	// match any segment, and its last segment can be "**" to match any number of segments
+	Path string `json:"path" yaml:"path"`
+	// Public requests are passed through without verifying their session
+	Public bool `json:"public,omitempty" yaml:"public,omitempty"`
+	// SessionRequired defaults to true. If false, the roles, permissions and claim validators are only checked if there is a session
+	SessionRequired *bool `json:"sessionRequired,omitempty" yaml:"sessionRequired,omitempty"`
+	// Roles and Permissions the user must all have, checked using the claims of the userroles recipe (which must be initialised)
+	Roles       []string `json:"roles,omitempty" yaml:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// ClaimValidators are checked in addition to the global claim validators and the ones of Roles and Permissions
+	ClaimValidators []claims.SessionClaimValidator `json:"-" yaml:"-"`
+	// DryRun makes the router log the requests it would deny because of this rule instead of denying them
+	DryRun bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty"`
+}
+
+type Router struct {
+	rules       []normalisedRule
+	defaultRule normalisedRule
+}
+
+type normalisedRule struct {
+	rule    Rule
+	methods map[string]bool
+	pattern []string
+	dryRun  bool
+}
+
+// NewRouter validates the policy table of config and returns a Router applying it
+func NewRouter(config TypeInput) (*Router, error) {
+	router := &Router{}
+	for _, rule := range config.Rules {
+		normalisedRule, err := normaliseRule(rule, config.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		router.rules = append(router.rules, normalisedRule)
+	}
+	defaultRule := Rule{}
+	if config.DefaultRule != nil {
+		defaultRule = *config.DefaultRule
+	}
+	if defaultRule.Path == "" {
+		defaultRule.Path = "/**"
+	}
+	normalisedDefaultRule, err := normaliseRule(defaultRule, config.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	router.defaultRule = normalisedDefaultRule
+	return router, nil
+}
+
+func normaliseRule(rule Rule, dryRun bool) (normalisedRule, error) {
+	if !strings.HasPrefix(rule.Path, "/") {
+		return normalisedRule{}, errors.New("the path of a policy rule must start with /, got: " + rule.Path)
+	}
+	pattern := splitPath(rule.Path)
+	for i, segment := range pattern {
+		if segment == "**" && i != len(pattern)-1 {
+			return normalisedRule{}, errors.New("** can only be the last segment of the path of a policy rule, got: " + rule.Path)
+		}
+	}
+	var methods map[string]bool
+	if len(rule.Methods) > 0 {
+		methods = map[string]bool{}
+		for _, method := range rule.Methods {
+			methods[strings.ToUpper(method)] = true
+		}
+	}
+	return normalisedRule{
+		rule:    rule,
+		methods: methods,
+		pattern: pattern,
+		dryRun:  dryRun || rule.DryRun,
+	}, nil
+}
+
+// Middleware returns supertokens.Middleware wrapping a handler that applies the policy table before calling theirHandler.
+// It should be used instead of supertokens.Middleware, after calling supertokens.Init.
+func (router *Router) Middleware(theirHandler http.Handler) http.Handler {
+	handlers := make([]http.Handler, len(router.rules))
+	for i := range router.rules {
+		handlers[i] = router.rules[i].makeHandler(theirHandler)
+	}
+	defaultHandler := router.defaultRule.makeHandler(theirHandler)
+
+	return supertokens.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i, rule := range router.rules {
+			if rule.matches(r.Method, r.URL.Path) {
+				handlers[i].ServeHTTP(w, r)
+				return
+			}
+		}
+		defaultHandler.ServeHTTP(w, r)
+	}))
+}
+
+func (rule normalisedRule) matches(method string, requestPath string) bool {
+	if rule.methods != nil && !rule.methods[method] {
+		return false
+	}
+	// the path is cleaned so that paths like /public/../admin are matched against the rules of the path they resolve to
+	segments := splitPath(path.Clean("/" + requestPath))
+	for i, patternSegment := range rule.pattern {
+		if patternSegment == "**" {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		isWildcard := patternSegment == "*" || strings.HasPrefix(patternSegment, ":") ||
+			strings.HasPrefix(patternSegment, "{") && strings.HasSuffix(patternSegment, "}")
+		if !isWildcard && patternSegment != segments[i] {
+			return false
+		}
+	}
+	return len(segments) == len(rule.pattern)
+}
+
+func (rule normalisedRule) isSessionRequired() bool {
+	return rule.rule.SessionRequired == nil || *rule.rule.SessionRequired
+}
+
+func (rule normalisedRule) getClaimValidators(globalClaimValidators []claims.SessionClaimValidator) []claims.SessionClaimValidator {
+	claimValidators := append([]claims.SessionClaimValidator{}, globalClaimValidators...)
+	for _, role := range rule.rule.Roles {
+		claimValidators = append(claimValidators, userrolesclaims.UserRoleClaimValidators.Includes(role, nil, nil))
+	}
+	for _, permission := range rule.rule.Permissions {
+		claimValidators = append(claimValidators, userrolesclaims.PermissionClaimValidators.Includes(permission, nil, nil))
+	}
+	return append(claimValidators, rule.rule.ClaimValidators...)
+}
+
+func (rule normalisedRule) makeHandler(theirHandler http.Handler) http.Handler {
+	if rule.rule.Public {
+		return theirHandler
+	}
+	if rule.dryRun {
+		return rule.makeDryRunHandler(theirHandler)
+	}
+	sessionRequired := rule.isSessionRequired()
+	return session.VerifySession(&sessmodels.VerifySessionOptions{
+		SessionRequired: &sessionRequired,
+		OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+			return rule.getClaimValidators(globalClaimValidators), nil
+		},
+	}, theirHandler.ServeHTTP)
+}
+
+// makeDryRunHandler returns a handler that checks the session of the requests like the one of makeHandler
+// would, but logs the requests it would deny and passes all of them to theirHandler
+func (rule normalisedRule) makeDryRunHandler(theirHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userContext := supertokens.MakeDefaultUserContextFromAPI(r)
+		// the session is fetched without checking any claim, so that the request can be passed on with it if a claim is invalid
+		var claimValidators []claims.SessionClaimValidator
+		sessionRequired := false
+		sessionContainer, err := session.GetSessionWithContext(r, w, &sessmodels.VerifySessionOptions{
+			SessionRequired: &sessionRequired,
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				claimValidators = rule.getClaimValidators(globalClaimValidators)
+				return []claims.SessionClaimValidator{}, nil
+			},
+		}, userContext)
+		if err == nil {
+			if sessionContainer != nil {
+				err = sessionContainer.AssertClaimsWithContext(claimValidators, userContext)
+			} else if rule.isSessionRequired() {
+				err = errors.New("session does not exist")
+			}
+		}
+		if err != nil {
+			fields := []supertokens.LogField{
+				supertokens.HTTPMethodLogField(r.Method),
+				{Key: LogKeyPath, Value: r.URL.Path},
+				{Key: LogKeyRule, Value: rule.rule.Path},
+				supertokens.ErrorLogField(err),
+			}
+			if sessionContainer != nil {
+				fields = append(fields, supertokens.UserIDLogField(sessionContainer.GetUserIDWithContext(userContext)))
+			}
+			supertokens.LogWarnMessage("policy: dry run would deny the request", fields...)
+		}
+		if sessionContainer != nil {
+			r = r.WithContext(context.WithValue(r.Context(), sessmodels.SessionContext, sessionContainer))
+		}
+		theirHandler.ServeHTTP(w, r)
+	})
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,136 @@
+package policy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/userroles"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func TestRuleMatching(t *testing.T) {
+	router, err := NewRouter(TypeInput{
+		Rules: []Rule{
+			{Methods: []string{"get"}, Path: "/users/:id"},
+			{Path: "/admin/**"},
+			{Path: "/orgs/{org}/*/settings/"},
+			{Path: "/"},
+		},
+	})
+	assert.NoError(t, err)
+	getRule := func(method string, path string) string {
+		for _, rule := range router.rules {
+			if rule.matches(method, path) {
+				return rule.rule.Path
+			}
+		}
+		return ""
+	}
+
+	assert.Equal(t, "/users/:id", getRule(http.MethodGet, "/users/1"))
+	assert.Equal(t, "", getRule(http.MethodPost, "/users/1"))
+	assert.Equal(t, "", getRule(http.MethodGet, "/users"))
+	assert.Equal(t, "", getRule(http.MethodGet, "/users/1/posts"))
+	assert.Equal(t, "/admin/**", getRule(http.MethodDelete, "/admin"))
+	assert.Equal(t, "/admin/**", getRule(http.MethodDelete, "/admin/users/1"))
+	assert.Equal(t, "", getRule(http.MethodGet, "/administrator"))
+	assert.Equal(t, "/orgs/{org}/*/settings/", getRule(http.MethodPut, "/orgs/acme/team/settings"))
+	assert.Equal(t, "/", getRule(http.MethodGet, "/"))
+	assert.Equal(t, "/admin/**", getRule(http.MethodGet, "/users/../admin/users"))
+	assert.Equal(t, "/users/:id", getRule(http.MethodGet, "//users/./1/"))
+	assert.Equal(t, "", getRule(http.MethodGet, "/admin/../users"))
+}
+
+func TestInvalidRules(t *testing.T) {
+	_, err := NewRouter(TypeInput{Rules: []Rule{{Path: "users"}}})
+	assert.Error(t, err)
+	_, err = NewRouter(TypeInput{Rules: []Rule{{Path: "/**/users"}}})
+	assert.Error(t, err)
+
+	router, err := NewRouter(TypeInput{DefaultRule: &Rule{}})
+	assert.NoError(t, err)
+	assert.True(t, router.defaultRule.matches(http.MethodGet, "/anything"))
+}
+
+func TestDefaultRuleRequiresSession(t *testing.T) {
+	router, err := NewRouter(TypeInput{})
+	assert.NoError(t, err)
+	assert.True(t, router.defaultRule.matches(http.MethodGet, "/anything"))
+	assert.False(t, router.defaultRule.rule.Public)
+	assert.True(t, router.defaultRule.isSessionRequired())
+}
+
+func resetAll() {
+	supertokens.ResetForTest()
+	session.ResetForTest()
+	userroles.ResetForTest()
+}
+
+func TestPolicyRouterAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+	logMessages := []string{}
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{
+		RecipeList: []supertokens.Recipe{session.Init(nil), userroles.Init(nil)},
+		Logger: supertokens.LoggerFunc(func(level supertokens.LogLevel, message string, fields []supertokens.LogField) {
+			if level == supertokens.LogLevelWarn {
+				logMessages = append(logMessages, message)
+			}
+		}),
+	})
+
+	_, err := userroles.CreateNewRoleOrAddPermissions("admin", []string{"write"}, nil)
+	assert.NoError(t, err)
+	_, err = userroles.AddRoleToUser("admin", "admin", nil)
+	assert.NoError(t, err)
+	adminSession, err := session.CreateNewSessionWithoutRequestResponse("admin", nil, nil, true)
+	assert.NoError(t, err)
+	userSession, err := session.CreateNewSessionWithoutRequestResponse("user", nil, nil, true)
+	assert.NoError(t, err)
+
+	config := TypeInput{
+		Rules: []Rule{
+			{Path: "/public", Public: true},
+			{Methods: []string{http.MethodPost}, Path: "/admin/**", Roles: []string{"admin"}, Permissions: []string{"write"}},
+			{Path: "/admin/**", Roles: []string{"admin"}},
+		},
+	}
+	request := func(router *Router, method string, path string, accessToken string) int {
+		policyServer := httptest.NewServer(router.Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		})))
+		defer policyServer.Close()
+		req, err := http.NewRequest(method, policyServer.URL+path, nil)
+		assert.NoError(t, err)
+		if accessToken != "" {
+			req.Header.Add("Cookie", "sAccessToken="+url.QueryEscape(accessToken))
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return res.StatusCode
+	}
+
+	router, err := NewRouter(config)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/public", ""))
+	assert.Equal(t, http.StatusUnauthorized, request(router, http.MethodGet, "/other", ""))
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/other", userSession.GetAccessToken()))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodGet, "/admin/users", userSession.GetAccessToken()))
+	assert.Equal(t, http.StatusForbidden, request(router, http.MethodGet, "/other/../admin/users", userSession.GetAccessToken()))
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/admin/users", adminSession.GetAccessToken()))
+	assert.Equal(t, http.StatusOK, request(router, http.MethodPost, "/admin/users", adminSession.GetAccessToken()))
+	assert.Empty(t, logMessages)
+
+	config.DryRun = true
+	router, err = NewRouter(config)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/other", ""))
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/admin/users", userSession.GetAccessToken()))
+	assert.Equal(t, http.StatusOK, request(router, http.MethodGet, "/admin/users", adminSession.GetAccessToken()))
+	assert.Equal(t, []string{"policy: dry run would deny the request", "policy: dry run would deny the request"}, logMessages)
+}
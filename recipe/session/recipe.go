@@ -19,6 +19,7 @@ import (
 	defaultErrors "errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/supertokens/supertokens-golang/recipe/openid"
 	"github.com/supertokens/supertokens-golang/recipe/openid/openidmodels"
@@ -39,6 +40,7 @@ type Recipe struct {
 
 	claimsAddedByOtherRecipes          []*claims.TypeSessionClaim
 	claimValidatorsAddedByOtherRecipes []claims.SessionClaimValidator
+	signingKeySet                      *signingKeySet
 }
 
 const RECIPE_ID = "session"
@@ -47,7 +49,8 @@ var singletonInstance *Recipe
 
 func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *sessmodels.TypeInput, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (Recipe, error) {
 	r := &Recipe{
-		claimsAddedByOtherRecipes:          []*claims.TypeSessionClaim{},
+		// the time of the last primary authentication is part of every session
+		claimsAddedByOtherRecipes:          []*claims.TypeSessionClaim{claims.AuthFreshnessClaim},
 		claimValidatorsAddedByOtherRecipes: []claims.SessionClaimValidator{},
 	}
 
@@ -76,7 +79,29 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 	if err != nil {
 		return Recipe{}, err
 	}
-	recipeImplementation := makeRecipeImplementation(*querierInstance, verifiedConfig, appInfo)
+	if verifiedConfig.NetworklessVerification != nil {
+		r.signingKeySet = newSigningKeySet(*verifiedConfig.NetworklessVerification, *querierInstance)
+	}
+	recipeImplementation := makeRecipeImplementation(*querierInstance, verifiedConfig, appInfo, r.signingKeySet)
+	if verifiedConfig.IdleTimeout != nil {
+		recipeImplementation = makeRecipeImplementationWithIdleTimeout(recipeImplementation, *verifiedConfig.IdleTimeout)
+	}
+	if verifiedConfig.DeviceInfo != nil {
+		recipeImplementation = makeRecipeImplementationWithDeviceInfo(recipeImplementation, *verifiedConfig.DeviceInfo)
+	}
+	if verifiedConfig.SessionLimit != nil {
+		recipeImplementation = makeRecipeImplementationWithSessionLimit(recipeImplementation, *verifiedConfig.SessionLimit)
+	}
+	if verifiedConfig.Fingerprint != nil {
+		fingerprintClaim, fingerprintValidator := newFingerprintClaim(*verifiedConfig.Fingerprint)
+		recipeImplementation = makeRecipeImplementationWithFingerprint(recipeImplementation, *verifiedConfig.Fingerprint, fingerprintClaim, fingerprintValidator)
+		if verifiedConfig.Fingerprint.MismatchPolicy == sessmodels.InvalidClaimFingerprintMismatchPolicy {
+			r.claimValidatorsAddedByOtherRecipes = append(r.claimValidatorsAddedByOtherRecipes, fingerprintValidator)
+		}
+	}
+	if verifiedConfig.DPoP != nil {
+		recipeImplementation = makeRecipeImplementationWithDPoP(recipeImplementation, verifiedConfig)
+	}
 
 	if verifiedConfig.Jwt.Enable {
 		openIdRecipe, err := openid.MakeRecipe(recipeId, appInfo, &openidmodels.TypeInput{
@@ -131,6 +156,14 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	activeSessionsAPIPathNormalised, err := supertokens.NewNormalisedURLPath(activeSessionsAPIPath)
+	if err != nil {
+		return nil, err
+	}
+	revokeActiveSessionAPIPathNormalised, err := supertokens.NewNormalisedURLPath(revokeActiveSessionAPIPath)
+	if err != nil {
+		return nil, err
+	}
 	resp := []supertokens.APIHandled{{
 		Method:                 http.MethodPost,
 		PathWithoutAPIBasePath: refreshAPIPathNormalised,
@@ -143,6 +176,21 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		Disabled:               r.APIImpl.SignOutPOST == nil,
 	}}
 
+	// the active sessions APIs list the devices of the sessions, so they are only exposed with DeviceInfo
+	if r.Config.DeviceInfo != nil {
+		resp = append(resp, supertokens.APIHandled{
+			Method:                 http.MethodGet,
+			PathWithoutAPIBasePath: activeSessionsAPIPathNormalised,
+			ID:                     activeSessionsAPIPath,
+			Disabled:               r.APIImpl.ActiveSessionsGET == nil,
+		}, supertokens.APIHandled{
+			Method:                 http.MethodPost,
+			PathWithoutAPIBasePath: revokeActiveSessionAPIPathNormalised,
+			ID:                     revokeActiveSessionAPIPath,
+			Disabled:               r.APIImpl.RevokeActiveSessionPOST == nil,
+		})
+	}
+
 	if r.OpenIdRecipe != nil {
 		jwtAPIs, err := r.OpenIdRecipe.RecipeModule.GetAPIsHandled()
 		if err != nil {
@@ -169,6 +217,10 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		return api.HandleRefreshAPI(r.APIImpl, options)
 	} else if id == signoutAPIPath {
 		return api.SignOutAPI(r.APIImpl, options)
+	} else if id == activeSessionsAPIPath {
+		return api.ActiveSessionsAPI(r.APIImpl, options)
+	} else if id == revokeActiveSessionAPIPath {
+		return api.RevokeActiveSessionAPI(r.APIImpl, options)
 	} else if r.OpenIdRecipe != nil {
 		return r.OpenIdRecipe.RecipeModule.HandleAPIRequest(id, req, res, theirhandler, path, method)
 	}
@@ -177,6 +229,9 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 
 func (r *Recipe) getAllCORSHeaders() []string {
 	resp := getCORSAllowedHeaders()
+	if r.Config.DPoP != nil {
+		resp = append(resp, dpopHeaderKey)
+	}
 	if r.OpenIdRecipe != nil {
 		resp = append(resp, r.OpenIdRecipe.RecipeModule.GetAllCORSHeaders()...)
 	}
@@ -189,16 +244,22 @@ func (r *Recipe) handleError(err error, req *http.Request, res http.ResponseWrit
 		unauthErr := err.(errors.UnauthorizedError)
 		if unauthErr.ClearTokens == nil || *unauthErr.ClearTokens {
 			supertokens.LogDebugMessage("errorHandler: Clearing tokens because of UNAUTHORISED response")
-			clearSessionFromAllTokenTransferMethods(r.Config, req, res)
+			clearSessionFromAllTokenTransferMethods(r.Config, req, res, supertokens.MakeDefaultUserContextFromAPI(req))
+		}
+		if unauthErr.Reason != nil && *unauthErr.Reason == errors.InvalidDPoPProofReason && r.Config.DPoP != nil {
+			res.Header().Set("WWW-Authenticate", `DPoP error="invalid_dpop_proof", algs="`+strings.Join(r.Config.DPoP.AllowedAlgorithms, " ")+`"`)
+		}
+		if unauthErr.Reason != nil && *unauthErr.Reason == errors.IdleTimeoutReason {
+			return true, r.Config.ErrorHandlers.OnIdleTimeout(err.Error(), req, res)
 		}
 		return true, r.Config.ErrorHandlers.OnUnauthorised(err.Error(), req, res)
 	} else if defaultErrors.As(err, &errors.TryRefreshTokenError{}) {
 		supertokens.LogDebugMessage("errorHandler: returning TRY_REFRESH_TOKEN")
 		return true, r.Config.ErrorHandlers.OnTryRefreshToken(err.Error(), req, res)
 	} else if defaultErrors.As(err, &errors.TokenTheftDetectedError{}) {
-		supertokens.LogDebugMessage("errorHandler: clearing tokens because of TOKEN_THEFT_DETECTED response")
-		clearSessionFromAllTokenTransferMethods(r.Config, req, res)
 		errs := err.(errors.TokenTheftDetectedError)
+		supertokens.LogWarnMessage("errorHandler: clearing tokens because of TOKEN_THEFT_DETECTED response", supertokens.RecipeIDLogField(r.RecipeModule.GetRecipeID()), supertokens.SessionHandleLogField(errs.Payload.SessionHandle), supertokens.UserIDLogField(errs.Payload.UserID))
+		clearSessionFromAllTokenTransferMethods(r.Config, req, res, supertokens.MakeDefaultUserContextFromAPI(req))
 		return true, r.Config.ErrorHandlers.OnTokenTheftDetected(errs.Payload.SessionHandle, errs.Payload.UserID, req, res)
 	} else if defaultErrors.As(err, &errors.InvalidClaimError{}) {
 		supertokens.LogDebugMessage("errorHandler: returning INVALID_CLAIMS")
@@ -225,6 +286,22 @@ func (r *Recipe) getClaimsAddedByOtherRecipes() []*claims.TypeSessionClaim {
 	return r.claimsAddedByOtherRecipes
 }
 
+func (r *Recipe) addClaimsAddedByOtherRecipesToPayload(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+	finalAccessTokenPayload := accessTokenPayload
+	if finalAccessTokenPayload == nil {
+		finalAccessTokenPayload = map[string]interface{}{}
+	}
+
+	var err error
+	for _, claim := range r.getClaimsAddedByOtherRecipes() {
+		finalAccessTokenPayload, err = claim.Build(userID, finalAccessTokenPayload, userContext)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return finalAccessTokenPayload, nil
+}
+
 func (r *Recipe) AddClaimValidatorFromOtherRecipe(validator claims.SessionClaimValidator) error {
 	r.claimValidatorsAddedByOtherRecipes = append(r.claimValidatorsAddedByOtherRecipes, validator)
 	return nil
@@ -33,7 +33,7 @@ import (
 
 var handshakeInfoLock sync.Mutex
 
-func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.TypeNormalisedInput, appInfo supertokens.NormalisedAppinfo) sessmodels.RecipeInterface {
+func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.TypeNormalisedInput, appInfo supertokens.NormalisedAppinfo, keySet *signingKeySet) sessmodels.RecipeInterface {
 
 	// We are defining this here to reduce the scope of legacy code
 	const LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME = "sIdRefreshToken"
@@ -41,7 +41,9 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	var result sessmodels.RecipeInterface
 
 	var recipeImplHandshakeInfo *sessmodels.HandshakeInfo = nil
-	getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+	getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, &map[string]interface{}{})
+
+	coalescer := newRefreshCoalescer(config.RefreshCoalescing)
 
 	createNewSession := func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
 		supertokens.LogDebugMessage("createNewSession: Started")
@@ -63,7 +65,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		}
 
 		if outputTokenTransferMethod == sessmodels.CookieTransferMethod &&
-			config.CookieSameSite == "none" &&
+			config.GetCookieSameSite(req, userContext) == "none" &&
 			!config.CookieSecure &&
 			!((appInfo.TopLevelAPIDomain == "localhost" || isTopLevelAPIDomainIPAddress) &&
 				(appInfo.TopLevelWebsiteDomain == "localhost" || isTopLevelWebsiteDomainIPAddress)) {
@@ -74,7 +76,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 
 		disableAntiCSRF := outputTokenTransferMethod == sessmodels.HeaderTransferMethod
 		sessionResponse, err := createNewSessionHelper(
-			recipeImplHandshakeInfo, config, querier, userID, disableAntiCSRF, accessTokenPayload, sessionData,
+			recipeImplHandshakeInfo, config, querier, keySet, userID, disableAntiCSRF, accessTokenPayload, sessionData, userContext,
 		)
 		if err != nil {
 			return nil, err
@@ -82,25 +84,44 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 
 		for _, tokenTransferMethod := range availableTokenTransferMethods {
 			if tokenTransferMethod != outputTokenTransferMethod {
-				token, err := getToken(req, sessmodels.AccessToken, tokenTransferMethod)
+				token, err := getToken(config, req, sessmodels.AccessToken, tokenTransferMethod)
 				if err != nil {
 					return nil, err
 				}
 				if token != nil {
-					clearSession(config, res, tokenTransferMethod)
+					clearSession(config, req, res, tokenTransferMethod, userContext)
 				}
 			}
 		}
 
-		attachCreateOrRefreshSessionResponseToRes(config, res, sessionResponse, outputTokenTransferMethod)
+		attachCreateOrRefreshSessionResponseToRes(config, req, res, sessionResponse, outputTokenTransferMethod, userContext)
 
 		sessionContainerInput := makeSessionContainerInput(sessionResponse.AccessToken.Token, sessionResponse.Session.Handle, sessionResponse.Session.UserID, sessionResponse.Session.UserDataInAccessToken, res, req, outputTokenTransferMethod, result)
+		sessionContainerInput.setCreatedOrRefreshedTokens(sessionResponse)
+		return newSessionContainer(config, &sessionContainerInput), nil
+	}
+
+	createNewSessionWithoutRequestResponse := func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		supertokens.LogDebugMessage("createNewSessionWithoutRequestResponse: Started")
+
+		sessionResponse, err := createNewSessionHelper(
+			recipeImplHandshakeInfo, config, querier, keySet, userID, disableAntiCsrf, accessTokenPayload, sessionData, userContext,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sessionContainerInput := makeSessionContainerInput(sessionResponse.AccessToken.Token, sessionResponse.Session.Handle, sessionResponse.Session.UserID, sessionResponse.Session.UserDataInAccessToken, nil, nil, sessmodels.HeaderTransferMethod, result)
+		sessionContainerInput.setCreatedOrRefreshedTokens(sessionResponse)
 		return newSessionContainer(config, &sessionContainerInput), nil
 	}
 
 	// In all cases if sIdRefreshToken token exists (so it's a legacy session) we return TRY_REFRESH_TOKEN. The refresh endpoint will clear this cookie and try to upgrade the session.
 	// Check https://supertokens.com/docs/contribute/decisions/session/0007 for further details and a table of expected behaviours
-	getSession := func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	getSession := func(req *http.Request, res http.ResponseWriter, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (session sessmodels.SessionContainer, err error) {
+		endSpan := supertokens.StartSpanInUserContext(userContext, supertokens.SpanNameGetSession, supertokens.RecipeIDLogField(RECIPE_ID))
+		defer func() { endSpan(err) }()
+
 		idRefreshToken := getCookieValue(req, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME)
 		if idRefreshToken != nil {
 			return nil, errors.TryRefreshTokenError{
@@ -115,7 +136,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 
 		// We check all token transfer methods for available access tokens
 		for _, tokenTransferMethod := range availableTokenTransferMethods {
-			token, err := getToken(req, sessmodels.AccessToken, tokenTransferMethod)
+			token, err := getToken(config, req, sessmodels.AccessToken, tokenTransferMethod)
 			if err != nil {
 				return nil, err
 			}
@@ -183,17 +204,20 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 
 		supertokens.LogDebugMessage("getSession: Value of doAntiCsrfCheck is: " + strconv.FormatBool(*doAntiCsrfCheck))
 
-		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil)
+		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, keySet, *accessToken, antiCsrfToken, *doAntiCsrfCheck, getRidFromHeader(req) != nil, userContext)
 		if err != nil {
 			return nil, err
 		}
 
 		accessTokenStr := accessToken.RawTokenString
+		accessTokenExpiry := uint64(accessToken.Payload["expiryTime"].(float64))
+		accessTokenUpdated := !reflect.DeepEqual(response.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{})
 
-		if !reflect.DeepEqual(response.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
+		if accessTokenUpdated {
 			setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInAccessToken)
 			setToken(
 				config,
+				req,
 				res,
 				sessmodels.AccessToken,
 				response.AccessToken.Token,
@@ -203,29 +227,82 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 				// Setting them to infinity would require special case handling on the frontend and just adding 10 years seems enough.
 				getCurrTimeInMS()+3153600000000,
 				requestTokenTransferMethod,
+				userContext,
 			)
 			accessTokenStr = response.AccessToken.Token
+			accessTokenExpiry = response.AccessToken.Expiry
 		}
 
-		supertokens.LogDebugMessage("getSession: Success!")
+		supertokens.LogDebugMessage("getSession: Success!", supertokens.SessionHandleLogField(response.Session.Handle), supertokens.UserIDLogField(response.Session.UserID))
 		sessionContainerInput := makeSessionContainerInput(accessTokenStr, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, req, requestTokenTransferMethod, result)
+		sessionContainerInput.accessTokenExpiry = accessTokenExpiry
+		sessionContainerInput.accessAndFrontTokenUpdated = accessTokenUpdated
 		sessionContainer := newSessionContainer(config, &sessionContainerInput)
 
 		return sessionContainer, nil
 	}
 
+	getSessionWithoutRequestResponse := func(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (session sessmodels.SessionContainer, err error) {
+		endSpan := supertokens.StartSpanInUserContext(userContext, supertokens.SpanNameGetSession, supertokens.RecipeIDLogField(RECIPE_ID))
+		defer func() { endSpan(err) }()
+
+		sessionOptional := options != nil && options.SessionRequired != nil && !*options.SessionRequired
+		supertokens.LogDebugMessage(fmt.Sprintf("getSessionWithoutRequestResponse: optional validation %v", sessionOptional))
+
+		parsedAccessToken, err := parseJWTWithoutSignatureVerification(accessToken)
+		if err == nil {
+			err = validateAccessTokenStructure(parsedAccessToken.Payload)
+		}
+		if err != nil {
+			if sessionOptional {
+				supertokens.LogDebugMessage("getSessionWithoutRequestResponse: returning undefined because accessToken is invalid and sessionRequired is false")
+				return nil, nil
+			}
+			supertokens.LogDebugMessage("getSessionWithoutRequestResponse: UNAUTHORISED because accessToken is invalid")
+			False := false
+			return nil, errors.UnauthorizedError{
+				Msg:         "Token parsing failed",
+				ClearTokens: &False,
+			}
+		}
+
+		// without a request, the anti-csrf token is only checked if asked to, since the tokens
+		// are not sent automatically by browsers like cookies are
+		doAntiCsrfCheck := options != nil && options.AntiCsrfCheck != nil && *options.AntiCsrfCheck
+		supertokens.LogDebugMessage("getSessionWithoutRequestResponse: Value of doAntiCsrfCheck is: " + strconv.FormatBool(doAntiCsrfCheck))
+
+		response, err := getSessionHelper(recipeImplHandshakeInfo, config, querier, keySet, parsedAccessToken, antiCsrfToken, doAntiCsrfCheck, true, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		sessionContainerInput := makeSessionContainerInput(accessToken, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, nil, nil, sessmodels.HeaderTransferMethod, result)
+		sessionContainerInput.accessTokenExpiry = uint64(parsedAccessToken.Payload["expiryTime"].(float64))
+		if !reflect.DeepEqual(response.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
+			sessionContainerInput.accessToken = response.AccessToken.Token
+			sessionContainerInput.accessTokenExpiry = response.AccessToken.Expiry
+			sessionContainerInput.accessAndFrontTokenUpdated = true
+		}
+
+		supertokens.LogDebugMessage("getSessionWithoutRequestResponse: Success!", supertokens.SessionHandleLogField(response.Session.Handle), supertokens.UserIDLogField(response.Session.UserID))
+		return newSessionContainer(config, &sessionContainerInput), nil
+	}
+
 	getSessionInformation := func(sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
-		return getSessionInformationHelper(querier, sessionHandle)
+		return getSessionInformationHelper(querier, sessionHandle, userContext)
 	}
 
-	refreshSession := func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+	refreshSession := func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (session sessmodels.SessionContainer, err error) {
+		endSpan := supertokens.StartSpanInUserContext(userContext, supertokens.SpanNameRefreshSession, supertokens.RecipeIDLogField(RECIPE_ID))
+		defer func() { endSpan(err) }()
+
 		supertokens.LogDebugMessage("refreshSession: Started")
 
 		refreshTokens := map[sessmodels.TokenTransferMethod]*string{}
 		// We check all token transfer methods for available refresh tokens
 		// We do this so that we can later clear all we are not overwriting
 		for _, tokenTransferMethod := range availableTokenTransferMethods {
-			token, err := getToken(req, sessmodels.RefreshToken, tokenTransferMethod)
+			token, err := getToken(config, req, sessmodels.RefreshToken, tokenTransferMethod)
 			if err != nil {
 				return nil, err
 			}
@@ -252,7 +329,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		} else {
 			if getCookieValue(req, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME) != nil {
 				supertokens.LogDebugMessage("refreshSession: cleared legacy id refresh token because refresh token was not found")
-				setCookie(config, res, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME, "", 0, "accessTokenPath")
+				clearCookie(config, req, res, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME, "accessTokenPath", userContext)
 			}
 
 			supertokens.LogDebugMessage("refreshSession: UNAUTHORISED because refresh token in request is undefined")
@@ -264,7 +341,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		}
 
 		antiCsrfToken := getAntiCsrfTokenFromHeaders(req)
-		response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, *refreshToken, antiCsrfToken, getRidFromHeader(req) != nil, requestTokenTransferMethod)
+		response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, coalescer, req, *refreshToken, antiCsrfToken, getRidFromHeader(req) != nil, requestTokenTransferMethod, userContext)
 		if err != nil {
 			unauthorisedErr := errors.UnauthorizedError{}
 			isUnauthorisedErr := defaultErrors.As(err, &unauthorisedErr)
@@ -274,7 +351,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			if (isTokenTheftDetectedErr) || (isUnauthorisedErr && unauthorisedErr.ClearTokens != nil && *unauthorisedErr.ClearTokens) {
 				if getCookieValue(req, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME) != nil {
 					supertokens.LogDebugMessage("refreshSession: cleared legacy id refresh token because refresh is clearing other tokens")
-					setCookie(config, res, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME, "", 0, "accessTokenPath")
+					clearCookie(config, req, res, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME, "accessTokenPath", userContext)
 				}
 			}
 			return nil, err
@@ -285,50 +362,77 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		// We clear the tokens in all token transfer methods we are not going to overwrite
 		for _, tokenTransferMethod := range availableTokenTransferMethods {
 			if tokenTransferMethod != requestTokenTransferMethod && refreshTokens[tokenTransferMethod] != nil {
-				clearSession(config, res, tokenTransferMethod)
+				clearSession(config, req, res, tokenTransferMethod, userContext)
 			}
 		}
-		attachCreateOrRefreshSessionResponseToRes(config, res, response, requestTokenTransferMethod)
-		supertokens.LogDebugMessage("refreshSession: Success!")
+		attachCreateOrRefreshSessionResponseToRes(config, req, res, response, requestTokenTransferMethod, userContext)
+		// the cookies of older domains are cleared, since the browser could send them instead of the new ones
+		if requestTokenTransferMethod == sessmodels.CookieTransferMethod {
+			clearCookiesOfOlderDomains(config, req, res, userContext)
+		}
+		supertokens.LogDebugMessage("refreshSession: Success!", supertokens.SessionHandleLogField(response.Session.Handle), supertokens.UserIDLogField(response.Session.UserID))
 
 		// This token isn't handled by getToken/setToken to limit the scope of this legacy/migration code
 		if getCookieValue(req, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME) != nil {
 			supertokens.LogDebugMessage("refreshSession: cleared legacy id refresh token after successfull refresh")
-			setCookie(config, res, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME, "", 0, "accessTokenPath")
+			clearCookie(config, req, res, LEGACY_ID_REFRESH_TOKEN_COOKIE_NAME, "accessTokenPath", userContext)
 		}
 
 		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, res, req, requestTokenTransferMethod, result)
+		sessionContainerInput.setCreatedOrRefreshedTokens(response)
 		sessionContainer := newSessionContainer(config, &sessionContainerInput)
 
 		return sessionContainer, nil
 	}
 
+	refreshSessionWithoutRequestResponse := func(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (session sessmodels.SessionContainer, err error) {
+		endSpan := supertokens.StartSpanInUserContext(userContext, supertokens.SpanNameRefreshSession, supertokens.RecipeIDLogField(RECIPE_ID))
+		defer func() { endSpan(err) }()
+
+		supertokens.LogDebugMessage("refreshSessionWithoutRequestResponse: Started")
+
+		// anti-csrf tokens are only used by the core for the cookie transfer method
+		tokenTransferMethod := sessmodels.CookieTransferMethod
+		if disableAntiCsrf {
+			tokenTransferMethod = sessmodels.HeaderTransferMethod
+		}
+		response, err := refreshSessionHelper(recipeImplHandshakeInfo, config, querier, coalescer, nil, refreshToken, antiCsrfToken, true, tokenTransferMethod, userContext)
+		if err != nil {
+			return nil, err
+		}
+		supertokens.LogDebugMessage("refreshSessionWithoutRequestResponse: Success!", supertokens.SessionHandleLogField(response.Session.Handle), supertokens.UserIDLogField(response.Session.UserID))
+
+		sessionContainerInput := makeSessionContainerInput(response.AccessToken.Token, response.Session.Handle, response.Session.UserID, response.Session.UserDataInAccessToken, nil, nil, sessmodels.HeaderTransferMethod, result)
+		sessionContainerInput.setCreatedOrRefreshedTokens(response)
+		return newSessionContainer(config, &sessionContainerInput), nil
+	}
+
 	revokeAllSessionsForUser := func(userID string, userContext supertokens.UserContext) ([]string, error) {
-		return revokeAllSessionsForUserHelper(querier, userID)
+		return revokeAllSessionsForUserHelper(querier, userID, userContext)
 	}
 
 	getAllSessionHandlesForUser := func(userID string, userContext supertokens.UserContext) ([]string, error) {
-		return getAllSessionHandlesForUserHelper(querier, userID)
+		return getAllSessionHandlesForUserHelper(querier, userID, userContext)
 	}
 
 	revokeSession := func(sessionHandle string, userContext supertokens.UserContext) (bool, error) {
-		return revokeSessionHelper(querier, sessionHandle)
+		return revokeSessionHelper(querier, sessionHandle, userContext)
 	}
 
 	revokeMultipleSessions := func(sessionHandles []string, userContext supertokens.UserContext) ([]string, error) {
-		return revokeMultipleSessionsHelper(querier, sessionHandles)
+		return revokeMultipleSessionsHelper(querier, sessionHandles, userContext)
 	}
 
 	updateSessionData := func(sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-		return updateSessionDataHelper(querier, sessionHandle, newSessionData)
+		return updateSessionDataHelper(querier, sessionHandle, newSessionData, userContext)
 	}
 
 	updateAccessTokenPayload := func(sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
-		return updateAccessTokenPayloadHelper(querier, sessionHandle, newAccessTokenPayload)
+		return updateAccessTokenPayloadHelper(querier, sessionHandle, newAccessTokenPayload, userContext)
 	}
 
 	getAccessTokenLifeTimeMS := func(userContext supertokens.UserContext) (uint64, error) {
-		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 		if err != nil {
 			return 0, err
 		}
@@ -336,7 +440,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	}
 
 	getRefreshTokenLifeTimeMS := func(userContext supertokens.UserContext) (uint64, error) {
-		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+		err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 		if err != nil {
 			return 0, err
 		}
@@ -344,7 +448,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 	}
 
 	regenerateAccessToken := func(accessToken string, newAccessTokenPayload *map[string]interface{}, userContext supertokens.UserContext) (*sessmodels.RegenerateAccessTokenResponse, error) {
-		return regenerateAccessTokenHelper(querier, newAccessTokenPayload, accessToken)
+		return regenerateAccessTokenHelper(querier, newAccessTokenPayload, accessToken, userContext)
 	}
 
 	mergeIntoAccessTokenPayload := func(sessionHandle string, accessTokenPayloadUpdate map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
@@ -380,22 +484,38 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 			return sessmodels.ValidateClaimsResult{}, err
 		}
 
-		for _, validator := range claimValidators {
-			supertokens.LogDebugMessage("updateClaimsInPayloadIfNeeded checking shouldRefetch for " + validator.ID)
-			claim := validator.Claim
-			if claim != nil && validator.ShouldRefetch != nil {
-				if validator.ShouldRefetch(accessTokenPayload, userContext) {
-					supertokens.LogDebugMessage("updateClaimsInPayloadIfNeeded refetching " + validator.ID)
-					value, err := claim.FetchValue(userId, userContext)
-					if err != nil {
-						return sessmodels.ValidateClaimsResult{}, err
+		var refetchClaims func(validators []claims.SessionClaimValidator) error
+		refetchClaims = func(validators []claims.SessionClaimValidator) error {
+			for _, validator := range validators {
+				supertokens.LogDebugMessage("updateClaimsInPayloadIfNeeded checking shouldRefetch for " + validator.ID)
+				claim := validator.Claim
+				if claim != nil && validator.ShouldRefetch != nil {
+					if validator.ShouldRefetch(accessTokenPayload, userContext) {
+						supertokens.LogDebugMessage("updateClaimsInPayloadIfNeeded refetching " + validator.ID)
+						value, err := claim.FetchValue(userId, userContext)
+						if err != nil {
+							return err
+						}
+						supertokens.LogDebugMessage(fmt.Sprint("updateClaimsInPayloadIfNeeded ", validator.ID, " refetch result ", value))
+						if value != nil {
+							accessTokenPayload = claim.AddToPayload_internal(accessTokenPayload, value, userContext)
+						}
 					}
-					supertokens.LogDebugMessage(fmt.Sprint("updateClaimsInPayloadIfNeeded ", validator.ID, " refetch result ", value))
-					if value != nil {
-						accessTokenPayload = claim.AddToPayload_internal(accessTokenPayload, value, userContext)
+				} else if len(validator.Validators) > 0 && validator.ShouldRefetch != nil {
+					// validators combined by AnyOf, AllOf and Not have no claim, so the claims of their validators are refetched
+					if validator.ShouldRefetch(accessTokenPayload, userContext) {
+						err := refetchClaims(validator.Validators)
+						if err != nil {
+							return err
+						}
 					}
 				}
 			}
+			return nil
+		}
+		err = refetchClaims(claimValidators)
+		if err != nil {
+			return sessmodels.ValidateClaimsResult{}, err
 		}
 
 		newSessionClaimPayloadJSON, err := json.Marshal(accessTokenPayload)
@@ -480,6 +600,10 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 		GetRefreshTokenLifeTimeMS:   &getRefreshTokenLifeTimeMS,
 		RegenerateAccessToken:       &regenerateAccessToken,
 
+		CreateNewSessionWithoutRequestResponse: &createNewSessionWithoutRequestResponse,
+		GetSessionWithoutRequestResponse:       &getSessionWithoutRequestResponse,
+		RefreshSessionWithoutRequestResponse:   &refreshSessionWithoutRequestResponse,
+
 		MergeIntoAccessTokenPayload: &mergeIntoAccessTokenPayload,
 		GetGlobalClaimValidators:    &getGlobalClaimValidators,
 		ValidateClaims:              &validateClaims,
@@ -494,13 +618,13 @@ func makeRecipeImplementation(querier supertokens.Querier, config sessmodels.Typ
 }
 
 // updates recipeImplHandshakeInfo in place.
-func getHandshakeInfo(recipeImplHandshakeInfo **sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, forceFetch bool) error {
+func getHandshakeInfo(recipeImplHandshakeInfo **sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, forceFetch bool, userContext supertokens.UserContext) error {
 	handshakeInfoLock.Lock()
 	defer handshakeInfoLock.Unlock()
 	if *recipeImplHandshakeInfo == nil ||
 		len((*recipeImplHandshakeInfo).GetJwtSigningPublicKeyList()) == 0 ||
 		forceFetch {
-		response, err := querier.SendPostRequest("/recipe/handshake", nil)
+		response, err := querier.SendPostRequest("/recipe/handshake", nil, userContext)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,74 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestRefreshClaimForAllSessionsOfUserAgainstFakeCore(t *testing.T) {
+	startFakeCoreForTest(t, nil)
+
+	var plan interface{} = "free"
+	planClaim, _ := claims.PrimitiveClaim("plan", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return plan, nil
+	}, nil)
+	sessionHandles := []string{}
+	for i := 0; i < 2; i++ {
+		newSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+		assert.NoError(t, err)
+		sessionHandles = append(sessionHandles, newSession.GetHandle())
+	}
+	getClaimValues := func() []interface{} {
+		values := []interface{}{}
+		for _, sessionHandle := range sessionHandles {
+			sessionInformation, err := GetSessionInformation(sessionHandle)
+			assert.NoError(t, err)
+			values = append(values, planClaim.GetValueFromPayload(sessionInformation.AccessTokenPayload, &map[string]interface{}{}))
+		}
+		return values
+	}
+
+	updatedSessionHandles, err := RefreshClaimForAllSessionsOfUser("userId", planClaim)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, sessionHandles, updatedSessionHandles)
+	assert.Equal(t, []interface{}{"free", "free"}, getClaimValues())
+
+	// the revoked sessions are not updated
+	_, err = RevokeSession(sessionHandles[1])
+	assert.NoError(t, err)
+	sessionHandles = sessionHandles[:1]
+	plan = "pro"
+	updatedSessionHandles, err = RefreshClaimForAllSessionsOfUser("userId", planClaim)
+	assert.NoError(t, err)
+	assert.Equal(t, sessionHandles, updatedSessionHandles)
+	assert.Equal(t, []interface{}{"pro"}, getClaimValues())
+
+	// the claim is removed when it has no value anymore
+	plan = nil
+	updatedSessionHandles, err = RefreshClaimForAllSessionsOfUser("userId", planClaim)
+	assert.NoError(t, err)
+	assert.Equal(t, sessionHandles, updatedSessionHandles)
+	assert.Equal(t, []interface{}{nil}, getClaimValues())
+
+	updatedSessionHandles, err = RefreshClaimForAllSessionsOfUser("otherUserId", planClaim)
+	assert.NoError(t, err)
+	assert.Empty(t, updatedSessionHandles)
+}
@@ -0,0 +1,200 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+var errRefreshNotCompleted = errors.New("the refresh with the same refresh token did not complete")
+
+// refreshCoalescer shares the result of a refresh between the concurrent refreshes using the same refresh
+// token, and keeps it for the grace period. A nil refreshCoalescer does not coalesce anything.
+type refreshCoalescer struct {
+	gracePeriod time.Duration
+	mutex       sync.Mutex
+	inProgress  map[string]*coalescedRefresh
+	recent      map[string]recentRefresh
+}
+
+type coalescedRefresh struct {
+	done     chan struct{}
+	response sessmodels.CreateOrRefreshAPIResponse
+	err      error
+}
+
+type recentRefresh struct {
+	response  sessmodels.CreateOrRefreshAPIResponse
+	expiresAt time.Time
+}
+
+func newRefreshCoalescer(config *sessmodels.RefreshCoalescingNormalisedConfig) *refreshCoalescer {
+	if config == nil {
+		return nil
+	}
+	return &refreshCoalescer{
+		gracePeriod: config.GracePeriod,
+		inProgress:  map[string]*coalescedRefresh{},
+		recent:      map[string]recentRefresh{},
+	}
+}
+
+// getRefreshCoalescingKey hashes everything the core checks during a refresh, so that a request can only reuse
+// the result of a refresh it would have been allowed to do itself. The refresh token is hashed so that it is
+// not kept in memory after the refresh.
+func getRefreshCoalescingKey(refreshToken string, antiCsrfToken *string, tokenTransferMethod sessmodels.TokenTransferMethod) string {
+	hash := sha256.New()
+	hash.Write([]byte(refreshToken))
+	hash.Write([]byte{0})
+	if antiCsrfToken != nil {
+		hash.Write([]byte(*antiCsrfToken))
+	}
+	hash.Write([]byte{0})
+	hash.Write([]byte(tokenTransferMethod))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// refresh calls doRefresh, unless a refresh with the same key is in progress or was done during the grace period,
+// in which case its result is returned instead. Errors are shared with the refreshes in progress but not kept,
+// except the cancellation of the request of the refresh in progress, after which the waiting refreshes try again.
+func (c *refreshCoalescer) refresh(key string, doRefresh func() (sessmodels.CreateOrRefreshAPIResponse, error)) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	if c == nil {
+		return doRefresh()
+	}
+
+	for {
+		c.mutex.Lock()
+		if recent, ok := c.recent[key]; ok && time.Now().Before(recent.expiresAt) {
+			c.mutex.Unlock()
+			supertokens.LogDebugMessage("refreshSession: reusing the result of a refresh done with the same refresh token during the grace period")
+			return copyRefreshResponse(recent.response)
+		}
+		call, ok := c.inProgress[key]
+		if !ok {
+			call = &coalescedRefresh{done: make(chan struct{})}
+			c.inProgress[key] = call
+			c.mutex.Unlock()
+			return c.runRefresh(key, call, doRefresh)
+		}
+		c.mutex.Unlock()
+		supertokens.LogDebugMessage("refreshSession: waiting for a refresh in progress with the same refresh token")
+		<-call.done
+		// the refresh in progress uses the context of its own request, whose cancellation says nothing about this one
+		if errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded) {
+			supertokens.LogDebugMessage("refreshSession: retrying because the refresh in progress with the same refresh token was cancelled")
+			continue
+		}
+		if call.err != nil {
+			return sessmodels.CreateOrRefreshAPIResponse{}, call.err
+		}
+		return copyRefreshResponse(call.response)
+	}
+}
+
+// runRefresh calls doRefresh for call, which was added to inProgress, and shares its result with the waiting refreshes
+func (c *refreshCoalescer) runRefresh(key string, call *coalescedRefresh, doRefresh func() (sessmodels.CreateOrRefreshAPIResponse, error)) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	// the refresh is removed from inProgress even if doRefresh panics, so that the waiting refreshes do not block forever
+	call.err = errRefreshNotCompleted
+	defer func() {
+		c.mutex.Lock()
+		delete(c.inProgress, key)
+		if call.err == nil && c.gracePeriod > 0 {
+			now := time.Now()
+			for otherKey, recent := range c.recent {
+				if !now.Before(recent.expiresAt) {
+					delete(c.recent, otherKey)
+				}
+			}
+			c.recent[key] = recentRefresh{
+				response:  call.response,
+				expiresAt: now.Add(c.gracePeriod),
+			}
+		}
+		c.mutex.Unlock()
+		close(call.done)
+	}()
+
+	call.response, call.err = doRefresh()
+	if call.err != nil {
+		return sessmodels.CreateOrRefreshAPIResponse{}, call.err
+	}
+	return copyRefreshResponse(call.response)
+}
+
+// copyRefreshResponse returns a deep copy of response, since the access token payload of a session is changed in place
+func copyRefreshResponse(response sessmodels.CreateOrRefreshAPIResponse) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	responseByte, err := json.Marshal(response)
+	if err != nil {
+		return sessmodels.CreateOrRefreshAPIResponse{}, err
+	}
+	var result sessmodels.CreateOrRefreshAPIResponse
+	err = json.Unmarshal(responseByte, &result)
+	if err != nil {
+		return sessmodels.CreateOrRefreshAPIResponse{}, err
+	}
+	return result, nil
+}
+
+// getTokenTheftEvent returns the details of a token theft, including the request it was detected in if there is one
+func getTokenTheftEvent(config sessmodels.TypeNormalisedInput, sessionHandle string, userID string, tokenTransferMethod sessmodels.TokenTransferMethod, req *http.Request, userContext supertokens.UserContext) sessmodels.TokenTheftEvent {
+	event := sessmodels.TokenTheftEvent{
+		SessionHandle:       sessionHandle,
+		UserID:              userID,
+		DetectedAt:          time.Now(),
+		TokenTransferMethod: tokenTransferMethod,
+	}
+	if req == nil {
+		req = getRequestFromUserContext(userContext)
+	}
+	if req == nil {
+		return event
+	}
+	if config.DeviceInfo != nil {
+		event.IPAddress = config.DeviceInfo.GetIPAddress(req, userContext)
+	} else {
+		event.IPAddress = defaultGetIPAddress(req, userContext)
+	}
+	event.UserAgent = req.Header.Get("User-Agent")
+	event.Method = req.Method
+	if req.URL != nil {
+		event.Path = req.URL.Path
+	}
+	event.Origin = req.Header.Get("Origin")
+	return event
+}
+
+func getTokenTheftEventLogFields(event sessmodels.TokenTheftEvent) []supertokens.LogField {
+	return []supertokens.LogField{
+		supertokens.RecipeIDLogField(RECIPE_ID),
+		supertokens.SessionHandleLogField(event.SessionHandle),
+		supertokens.UserIDLogField(event.UserID),
+		supertokens.HTTPMethodLogField(event.Method),
+		{Key: LogKeyRequestPath, Value: event.Path},
+		{Key: LogKeyIPAddress, Value: event.IPAddress},
+		{Key: LogKeyUserAgent, Value: event.UserAgent},
+		{Key: LogKeyOrigin, Value: event.Origin},
+	}
+}
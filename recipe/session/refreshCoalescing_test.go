@@ -0,0 +1,313 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	defaultErrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
+)
+
+func TestRefreshCoalescingConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "api.supertokens.io",
+		WebsiteDomain: "supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, config.RefreshCoalescing)
+
+	config, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{RefreshCoalescing: &sessmodels.RefreshCoalescingConfig{}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRefreshGracePeriod, config.RefreshCoalescing.GracePeriod)
+
+	gracePeriod := time.Duration(0)
+	config, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{RefreshCoalescing: &sessmodels.RefreshCoalescingConfig{GracePeriod: &gracePeriod}})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), config.RefreshCoalescing.GracePeriod)
+
+	gracePeriod = -time.Second
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{RefreshCoalescing: &sessmodels.RefreshCoalescingConfig{GracePeriod: &gracePeriod}})
+	assert.EqualError(t, err, "RefreshCoalescing.GracePeriod cannot be negative")
+}
+
+func TestRefreshCoalescerSharesConcurrentRefreshes(t *testing.T) {
+	// the grace period covers the refreshes that would only start after the first one is done
+	coalescer := newRefreshCoalescer(&sessmodels.RefreshCoalescingNormalisedConfig{GracePeriod: time.Minute})
+	key := getRefreshCoalescingKey("refreshToken", nil, sessmodels.CookieTransferMethod)
+
+	var calls int32
+	release := make(chan struct{})
+	doRefresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return sessmodels.CreateOrRefreshAPIResponse{
+			Session: sessmodels.SessionStruct{Handle: "handle", UserDataInAccessToken: map[string]interface{}{"a": "b"}},
+		}, nil
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]sessmodels.CreateOrRefreshAPIResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := coalescer.refresh(key, doRefresh)
+			assert.NoError(t, err)
+			responses[i] = response
+		}(i)
+	}
+	// the refreshes wait for the first one, which is released once they were all started
+	for {
+		coalescer.mutex.Lock()
+		_, started := coalescer.inProgress[key]
+		coalescer.mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, response := range responses {
+		assert.Equal(t, "handle", response.Session.Handle)
+	}
+	// each refresh gets its own access token payload, since it is changed in place
+	responses[0].Session.UserDataInAccessToken["a"] = "c"
+	assert.Equal(t, "b", responses[1].Session.UserDataInAccessToken["a"])
+}
+
+func TestRefreshCoalescerRetriesAfterCancelledRefresh(t *testing.T) {
+	coalescer := newRefreshCoalescer(&sessmodels.RefreshCoalescingNormalisedConfig{GracePeriod: time.Minute})
+	key := getRefreshCoalescingKey("refreshToken", nil, sessmodels.CookieTransferMethod)
+
+	// the first refresh is cancelled with its request, after the others started waiting for it
+	release := make(chan struct{})
+	cancelledRefresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		<-release
+		return sessmodels.CreateOrRefreshAPIResponse{}, &url.Error{Op: "Post", URL: "http://localhost:3567/recipe/session/refresh", Err: context.Canceled}
+	}
+	var calls int32
+	doRefresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		atomic.AddInt32(&calls, 1)
+		return sessmodels.CreateOrRefreshAPIResponse{Session: sessmodels.SessionStruct{Handle: "handle"}}, nil
+	}
+
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := coalescer.refresh(key, cancelledRefresh)
+		cancelledErr <- err
+	}()
+	for {
+		coalescer.mutex.Lock()
+		_, started := coalescer.inProgress[key]
+		coalescer.mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]sessmodels.CreateOrRefreshAPIResponse, 3)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := coalescer.refresh(key, doRefresh)
+			assert.NoError(t, err)
+			responses[i] = response
+		}(i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.True(t, defaultErrors.Is(<-cancelledErr, context.Canceled))
+	// one of the waiting refreshes did the refresh again, and shared its result with the others
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, response := range responses {
+		assert.Equal(t, "handle", response.Session.Handle)
+	}
+}
+
+func TestRefreshCoalescerGracePeriod(t *testing.T) {
+	coalescer := newRefreshCoalescer(&sessmodels.RefreshCoalescingNormalisedConfig{GracePeriod: 50 * time.Millisecond})
+	antiCsrfToken := "antiCsrf"
+	key := getRefreshCoalescingKey("refreshToken", &antiCsrfToken, sessmodels.CookieTransferMethod)
+
+	calls := 0
+	doRefresh := func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		calls++
+		return sessmodels.CreateOrRefreshAPIResponse{Session: sessmodels.SessionStruct{Handle: "handle"}}, nil
+	}
+
+	_, err := coalescer.refresh(key, doRefresh)
+	assert.NoError(t, err)
+	response, err := coalescer.refresh(key, doRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, "handle", response.Session.Handle)
+	assert.Equal(t, 1, calls)
+
+	// a different anti-csrf token or token transfer method does not reuse the result
+	_, err = coalescer.refresh(getRefreshCoalescingKey("refreshToken", nil, sessmodels.CookieTransferMethod), doRefresh)
+	assert.NoError(t, err)
+	_, err = coalescer.refresh(getRefreshCoalescingKey("refreshToken", &antiCsrfToken, sessmodels.HeaderTransferMethod), doRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	time.Sleep(60 * time.Millisecond)
+	_, err = coalescer.refresh(key, doRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+	// the expired results are removed when a new one is kept
+	assert.Len(t, coalescer.recent, 1)
+
+	// errors are not kept
+	failingKey := getRefreshCoalescingKey("otherRefreshToken", nil, sessmodels.HeaderTransferMethod)
+	_, err = coalescer.refresh(failingKey, func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		return sessmodels.CreateOrRefreshAPIResponse{}, defaultErrors.New("core error")
+	})
+	assert.EqualError(t, err, "core error")
+	_, err = coalescer.refresh(failingKey, doRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, calls)
+}
+
+func TestRefreshCoalescerDisabled(t *testing.T) {
+	coalescer := newRefreshCoalescer(nil)
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, err := coalescer.refresh("key", func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+			calls++
+			return sessmodels.CreateOrRefreshAPIResponse{}, nil
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, calls)
+
+	// without a grace period, the results are not kept
+	coalescer = newRefreshCoalescer(&sessmodels.RefreshCoalescingNormalisedConfig{GracePeriod: 0})
+	for i := 0; i < 2; i++ {
+		_, err := coalescer.refresh("key", func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+			calls++
+			return sessmodels.CreateOrRefreshAPIResponse{}, nil
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 4, calls)
+	assert.Empty(t, coalescer.recent)
+}
+
+func TestGetTokenTheftEvent(t *testing.T) {
+	config := sessmodels.TypeNormalisedInput{}
+	event := getTokenTheftEvent(config, "handle", "userId", sessmodels.HeaderTransferMethod, nil, &map[string]interface{}{})
+	assert.Equal(t, "handle", event.SessionHandle)
+	assert.Equal(t, "userId", event.UserID)
+	assert.Equal(t, sessmodels.HeaderTransferMethod, event.TokenTransferMethod)
+	assert.False(t, event.DetectedAt.IsZero())
+	assert.Empty(t, event.IPAddress)
+
+	req := httptest.NewRequest("POST", "/auth/session/refresh", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Origin", "https://supertokens.io")
+	event = getTokenTheftEvent(config, "handle", "userId", sessmodels.CookieTransferMethod, nil, supertokens.MakeDefaultUserContextFromAPI(req))
+	assert.Equal(t, "1.2.3.4", event.IPAddress)
+	assert.Equal(t, "Mozilla/5.0", event.UserAgent)
+	assert.Equal(t, "POST", event.Method)
+	assert.Equal(t, "/auth/session/refresh", event.Path)
+	assert.Equal(t, "https://supertokens.io", event.Origin)
+
+	config.DeviceInfo = &sessmodels.DeviceInfoNormalisedConfig{
+		GetIPAddress: func(req *http.Request, userContext supertokens.UserContext) string {
+			return req.Header.Get("X-Forwarded-For")
+		},
+	}
+	req.Header.Set("X-Forwarded-For", "5.6.7.8")
+	event = getTokenTheftEvent(config, "handle", "userId", sessmodels.CookieTransferMethod, req, &map[string]interface{}{})
+	assert.Equal(t, "5.6.7.8", event.IPAddress)
+}
+
+func TestRefreshCoalescingAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+	var theftEvents []sessmodels.TokenTheftEvent
+	initWithGracePeriod := func(gracePeriod time.Duration) {
+		initWithFakeCoreForTest(t, core, &sessmodels.TypeInput{
+			RefreshCoalescing: &sessmodels.RefreshCoalescingConfig{GracePeriod: &gracePeriod},
+			OnTokenTheftEvent: func(event sessmodels.TokenTheftEvent, userContext supertokens.UserContext) {
+				theftEvents = append(theftEvents, event)
+			},
+		})
+	}
+	initWithGracePeriod(time.Minute)
+
+	newSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	refreshToken := *newSession.GetAllSessionTokensDangerously().RefreshToken
+
+	// the first tab refreshes the session and uses the new refresh token before the second tab refreshes it
+	firstRefresh, err := RefreshSessionWithoutRequestResponse(refreshToken, true, nil)
+	assert.NoError(t, err)
+	_, err = RefreshSessionWithoutRequestResponse(*firstRefresh.GetAllSessionTokensDangerously().RefreshToken, true, nil)
+	assert.NoError(t, err)
+	secondRefresh, err := RefreshSessionWithoutRequestResponse(refreshToken, true, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, *firstRefresh.GetAllSessionTokensDangerously().RefreshToken, *secondRefresh.GetAllSessionTokensDangerously().RefreshToken)
+	assert.Empty(t, theftEvents)
+
+	// without a grace period, the second tab makes the core detect token theft
+	initWithGracePeriod(0)
+	newSession, err = CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	refreshToken = *newSession.GetAllSessionTokensDangerously().RefreshToken
+	firstRefresh, err = RefreshSessionWithoutRequestResponse(refreshToken, true, nil)
+	assert.NoError(t, err)
+	_, err = RefreshSessionWithoutRequestResponse(*firstRefresh.GetAllSessionTokensDangerously().RefreshToken, true, nil)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/session/refresh", nil)
+	req.RemoteAddr = "1.2.3.4:5678"
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	_, err = RefreshSessionWithoutRequestResponseWithContext(refreshToken, true, nil, supertokens.MakeDefaultUserContextFromAPI(req))
+	assert.True(t, defaultErrors.As(err, &errors.TokenTheftDetectedError{}))
+	assert.Len(t, theftEvents, 1)
+	assert.Equal(t, newSession.GetHandle(), theftEvents[0].SessionHandle)
+	assert.Equal(t, "userId", theftEvents[0].UserID)
+	assert.Equal(t, "1.2.3.4", theftEvents[0].IPAddress)
+	assert.Equal(t, "Mozilla/5.0", theftEvents[0].UserAgent)
+	assert.Equal(t, "/auth/session/refresh", theftEvents[0].Path)
+	assert.Equal(t, sessmodels.HeaderTransferMethod, theftEvents[0].TokenTransferMethod)
+}
@@ -34,6 +34,12 @@ type SessionContainerInput struct {
 	recipeImpl            sessmodels.RecipeInterface
 	req                   *http.Request
 	tokenTransferMethod   sessmodels.TokenTransferMethod
+
+	// the tokens returned by GetAllSessionTokensDangerously, along with accessToken
+	accessTokenExpiry          uint64
+	refreshToken               *string
+	antiCsrfToken              *string
+	accessAndFrontTokenUpdated bool
 }
 
 func makeSessionContainerInput(accessToken string, sessionHandle string, userID string, userDataInAccessToken map[string]interface{}, res http.ResponseWriter, req *http.Request, tokenTransferMethod sessmodels.TokenTransferMethod, recipeImpl sessmodels.RecipeInterface) SessionContainerInput {
@@ -49,6 +55,15 @@ func makeSessionContainerInput(accessToken string, sessionHandle string, userID
 	}
 }
 
+// setCreatedOrRefreshedTokens sets the tokens of a session that was just created or refreshed
+func (s *SessionContainerInput) setCreatedOrRefreshedTokens(response sessmodels.CreateOrRefreshAPIResponse) {
+	refreshToken := response.RefreshToken.Token
+	s.accessTokenExpiry = response.AccessToken.Expiry
+	s.refreshToken = &refreshToken
+	s.antiCsrfToken = response.AntiCsrfToken
+	s.accessAndFrontTokenUpdated = true
+}
+
 func newSessionContainer(config sessmodels.TypeNormalisedInput, session *SessionContainerInput) sessmodels.SessionContainer {
 
 	sessionContainer := &sessmodels.TypeSessionContainer{}
@@ -57,7 +72,10 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		if err != nil {
 			return err
 		}
-		clearSession(config, session.res, session.tokenTransferMethod)
+		// sessions created without a response have no tokens to clear, since the caller sent them to the client
+		if session.res != nil {
+			clearSession(config, session.req, session.res, session.tokenTransferMethod, userContext)
+		}
 		return nil
 	}
 
@@ -102,9 +120,15 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 
 		if !reflect.DeepEqual(resp.AccessToken, sessmodels.CreateOrRefreshAPIResponseToken{}) {
 			session.accessToken = resp.AccessToken.Token
+			session.accessTokenExpiry = resp.AccessToken.Expiry
+			session.accessAndFrontTokenUpdated = true
+			if session.res == nil {
+				return nil
+			}
 			setFrontTokenInHeaders(session.res, resp.Session.UserID, resp.AccessToken.Expiry, resp.Session.UserDataInAccessToken)
 			setToken(
 				config,
+				session.req,
 				session.res,
 				sessmodels.AccessToken,
 				resp.AccessToken.Token,
@@ -114,6 +138,7 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 				// Setting them to infinity would require special case handling on the frontend and just adding 100 years seems enough.
 				getCurrTimeInMS()+3153600000000,
 				session.tokenTransferMethod,
+				userContext,
 			)
 		}
 		return nil
@@ -167,6 +192,17 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 		return session.accessToken
 	}
 
+	sessionContainer.GetAllSessionTokensDangerouslyWithContext = func(userContext supertokens.UserContext) sessmodels.SessionTokens {
+		return sessmodels.SessionTokens{
+			AccessToken:                session.accessToken,
+			AccessTokenExpiry:          session.accessTokenExpiry,
+			RefreshToken:               session.refreshToken,
+			AntiCsrfToken:              session.antiCsrfToken,
+			FrontToken:                 buildFrontToken(session.userID, session.accessTokenExpiry, session.userDataInAccessToken),
+			AccessAndFrontTokenUpdated: session.accessAndFrontTokenUpdated,
+		}
+	}
+
 	sessionContainer.AssertClaimsWithContext = func(claimValidators []claims.SessionClaimValidator, userContext supertokens.UserContext) error {
 		validateClaimResponse, err := (*session.recipeImpl.ValidateClaims)(session.userID, sessionContainer.GetAccessTokenPayloadWithContext(userContext), claimValidators, userContext)
 		if err != nil {
@@ -242,6 +278,9 @@ func newSessionContainer(config sessmodels.TypeNormalisedInput, session *Session
 	sessionContainer.GetExpiry = func() (uint64, error) {
 		return sessionContainer.GetExpiryWithContext(&map[string]interface{}{})
 	}
+	sessionContainer.GetAllSessionTokensDangerously = func() sessmodels.SessionTokens {
+		return sessionContainer.GetAllSessionTokensDangerouslyWithContext(&map[string]interface{}{})
+	}
 
 	sessionContainer.MergeIntoAccessTokenPayload = func(accessTokenPayloadUpdate map[string]interface{}) error {
 		return sessionContainer.MergeIntoAccessTokenPayloadWithContext(accessTokenPayloadUpdate, &map[string]interface{}{})
@@ -18,13 +18,14 @@ package session
 import (
 	"encoding/json"
 	defaultErrors "errors"
+	"net/http"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
 
-func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, userID string, disableAntiCsrf bool, AccessTokenPayload, sessionData map[string]interface{}) (sessmodels.CreateOrRefreshAPIResponse, error) {
+func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, keySet *signingKeySet, userID string, disableAntiCsrf bool, AccessTokenPayload, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.CreateOrRefreshAPIResponse, error) {
 	if AccessTokenPayload == nil {
 		AccessTokenPayload = map[string]interface{}{}
 	}
@@ -36,16 +37,19 @@ func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, c
 		"userDataInJWT":      AccessTokenPayload,
 		"userDataInDatabase": sessionData,
 	}
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
 	requestBody["enableAntiCsrf"] = !disableAntiCsrf && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN
-	response, err := querier.SendPostRequest("/recipe/session", requestBody)
+	response, err := querier.SendPostRequest("/recipe/session", requestBody, userContext)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
 	updateJwtSigningPublicKeyInfo(&recipeImplHandshakeInfo, getKeyInfoFromJson(response), response["jwtSigningPublicKey"].(string), uint64(response["jwtSigningPublicKeyExpiryTime"].(float64)))
+	if keySet != nil {
+		keySet.addKeys(getKeyInfoFromJson(response))
+	}
 
 	delete(response, "status")
 	delete(response, "jwtSigningPublicKey")
@@ -64,8 +68,12 @@ func createNewSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, c
 	return resp, nil
 }
 
-func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, parsedAccessToken ParsedJWTInfo, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool) (sessmodels.GetSessionResponse, error) {
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, keySet *signingKeySet, parsedAccessToken ParsedJWTInfo, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool, userContext supertokens.UserContext) (sessmodels.GetSessionResponse, error) {
+	if keySet != nil {
+		return getSessionWithoutNetworkHelper(config, keySet, parsedAccessToken, antiCsrfToken, doAntiCsrfCheck, containsCustomHeader, userContext)
+	}
+
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 	if err != nil {
 		return sessmodels.GetSessionResponse{}, err
 	}
@@ -105,23 +113,9 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 
 	if doAntiCsrfCheck {
-		if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN {
-			if accessTokenInfo != nil {
-				if antiCsrfToken == nil || *antiCsrfToken != *accessTokenInfo.antiCsrfToken {
-					if antiCsrfToken == nil {
-						supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because antiCsrfToken is missing from request")
-						return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "Provided antiCsrfToken is undefined. If you do not want anti-csrf check for this API, please set doAntiCsrfCheck to false for this API"}
-					} else {
-						supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because the passed antiCsrfToken is not the same as in the access token")
-						return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "anti-csrf check failed"}
-					}
-				}
-			}
-		} else if recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
-			if !containsCustomHeader {
-				supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because custom header (rid) was not passed")
-				return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{Msg: "anti-csrf check failed. Please pass 'rid: \"session\"' header in the request, or set doAntiCsrfCheck to false for this API"}
-			}
+		err = checkAntiCsrf(recipeImplHandshakeInfo.AntiCsrf, accessTokenInfo, antiCsrfToken, containsCustomHeader)
+		if err != nil {
+			return sessmodels.GetSessionResponse{}, err
 		}
 	}
 
@@ -145,7 +139,7 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 		requestBody["antiCsrfToken"] = *antiCsrfToken
 	}
 
-	response, err := querier.SendPostRequest("/recipe/session/verify", requestBody)
+	response, err := querier.SendPostRequest("/recipe/session/verify", requestBody, userContext)
 	if err != nil {
 		return sessmodels.GetSessionResponse{}, err
 	}
@@ -177,29 +171,101 @@ func getSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config
 	}
 }
 
-func getSessionInformationHelper(querier supertokens.Querier, sessionHandle string) (*sessmodels.SessionInformation, error) {
+// getSessionWithoutNetworkHelper verifies the access token using the keys of keySet, and never queries the
+// core to do so, even if the access token was created by a refresh, or if access token blacklisting is enabled
+func getSessionWithoutNetworkHelper(config sessmodels.TypeNormalisedInput, keySet *signingKeySet, parsedAccessToken ParsedJWTInfo, antiCsrfToken *string, doAntiCsrfCheck, containsCustomHeader bool, userContext supertokens.UserContext) (sessmodels.GetSessionResponse, error) {
+	doAntiCsrfCheckUsingToken := config.AntiCsrf == antiCSRF_VIA_TOKEN && doAntiCsrfCheck
+	keys, err := keySet.getKeys(userContext)
+	if err != nil {
+		return sessmodels.GetSessionResponse{}, err
+	}
+	accessTokenInfo, err := getInfoFromAccessTokenUsingAnyKey(parsedAccessToken, keys, doAntiCsrfCheckUsingToken)
+	if err == nil && accessTokenInfo == nil && keySet.refreshIfOlderThan(signingKeysMinRefreshInterval, userContext) {
+		// the access token may be signed with a key that was added after the keys were fetched
+		keys, err = keySet.getKeys(userContext)
+		if err != nil {
+			return sessmodels.GetSessionResponse{}, err
+		}
+		accessTokenInfo, err = getInfoFromAccessTokenUsingAnyKey(parsedAccessToken, keys, doAntiCsrfCheckUsingToken)
+	}
+	if err != nil {
+		return sessmodels.GetSessionResponse{}, err
+	}
+	if accessTokenInfo == nil {
+		supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because the access token is not signed by any known key")
+		return sessmodels.GetSessionResponse{}, errors.TryRefreshTokenError{
+			Msg: "Access token is not signed by any of the known signing keys",
+		}
+	}
+
+	if doAntiCsrfCheck {
+		err = checkAntiCsrf(config.AntiCsrf, accessTokenInfo, antiCsrfToken, containsCustomHeader)
+		if err != nil {
+			return sessmodels.GetSessionResponse{}, err
+		}
+	}
+
+	return sessmodels.GetSessionResponse{
+		Session: sessmodels.SessionStruct{
+			Handle:                accessTokenInfo.sessionHandle,
+			UserID:                accessTokenInfo.userID,
+			UserDataInAccessToken: accessTokenInfo.userData,
+		},
+	}, nil
+}
+
+func checkAntiCsrf(antiCsrf string, accessTokenInfo *accessTokenInfoStruct, antiCsrfToken *string, containsCustomHeader bool) error {
+	if antiCsrf == antiCSRF_VIA_TOKEN {
+		if accessTokenInfo != nil {
+			if antiCsrfToken == nil || *antiCsrfToken != *accessTokenInfo.antiCsrfToken {
+				if antiCsrfToken == nil {
+					supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because antiCsrfToken is missing from request")
+					return errors.TryRefreshTokenError{Msg: "Provided antiCsrfToken is undefined. If you do not want anti-csrf check for this API, please set doAntiCsrfCheck to false for this API"}
+				} else {
+					supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because the passed antiCsrfToken is not the same as in the access token")
+					return errors.TryRefreshTokenError{Msg: "anti-csrf check failed"}
+				}
+			}
+		}
+	} else if antiCsrf == antiCSRF_VIA_CUSTOM_HEADER {
+		if !containsCustomHeader {
+			supertokens.LogDebugMessage("getSession: Returning TRY_REFRESH_TOKEN because custom header (rid) was not passed")
+			return errors.TryRefreshTokenError{Msg: "anti-csrf check failed. Please pass 'rid: \"session\"' header in the request, or set doAntiCsrfCheck to false for this API"}
+		}
+	}
+	return nil
+}
+
+func getSessionInformationHelper(querier supertokens.Querier, sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
 	response, err := querier.SendGetRequest("/recipe/session",
 		map[string]string{
 			"sessionHandle": sessionHandle,
-		})
+		}, userContext)
 	if err != nil {
 		return nil, err
 	}
 	if response["status"] == "OK" {
+		sessionData := response["userDataInDatabase"].(map[string]interface{})
+		deviceInfo := getDeviceInfoFromSessionData(sessionData)
+		// the device info is stored in the session data by the recipe, but is not part of the data of the user
+		delete(sessionData, deviceInfoSessionDataKey)
 		return &sessmodels.SessionInformation{
 			SessionHandle:      response["sessionHandle"].(string),
 			UserId:             response["userId"].(string),
-			SessionData:        response["userDataInDatabase"].(map[string]interface{}),
+			SessionData:        sessionData,
 			Expiry:             uint64(response["expiry"].(float64)),
 			TimeCreated:        uint64(response["timeCreated"].(float64)),
 			AccessTokenPayload: response["userDataInJWT"].(map[string]interface{}),
+			DeviceInfo:         deviceInfo,
 		}, nil
 	}
 	return nil, nil
 }
 
-func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, refreshToken string, antiCsrfToken *string, containsCustomHeader bool, tokenTransferMethod sessmodels.TokenTransferMethod) (sessmodels.CreateOrRefreshAPIResponse, error) {
-	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false)
+// refreshSessionHelper refreshes the session in the core, through coalescer so that concurrent refreshes using
+// the same refresh token are only done once. req is only used to describe token thefts, and can be nil.
+func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, coalescer *refreshCoalescer, req *http.Request, refreshToken string, antiCsrfToken *string, containsCustomHeader bool, tokenTransferMethod sessmodels.TokenTransferMethod, userContext supertokens.UserContext) (sessmodels.CreateOrRefreshAPIResponse, error) {
+	err := getHandshakeInfo(&recipeImplHandshakeInfo, config, querier, false, userContext)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
@@ -215,6 +281,12 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 		}
 	}
 
+	return coalescer.refresh(getRefreshCoalescingKey(refreshToken, antiCsrfToken, tokenTransferMethod), func() (sessmodels.CreateOrRefreshAPIResponse, error) {
+		return refreshSessionInCore(recipeImplHandshakeInfo, config, querier, req, refreshToken, antiCsrfToken, tokenTransferMethod, userContext)
+	})
+}
+
+func refreshSessionInCore(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, config sessmodels.TypeNormalisedInput, querier supertokens.Querier, req *http.Request, refreshToken string, antiCsrfToken *string, tokenTransferMethod sessmodels.TokenTransferMethod, userContext supertokens.UserContext) (sessmodels.CreateOrRefreshAPIResponse, error) {
 	requestBody := map[string]interface{}{
 		"refreshToken":   refreshToken,
 		"enableAntiCsrf": tokenTransferMethod == sessmodels.CookieTransferMethod && recipeImplHandshakeInfo.AntiCsrf == antiCSRF_VIA_TOKEN,
@@ -222,7 +294,7 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 	if antiCsrfToken != nil {
 		requestBody["antiCsrfToken"] = *antiCsrfToken
 	}
-	response, err := querier.SendPostRequest("/recipe/session/refresh", requestBody)
+	response, err := querier.SendPostRequest("/recipe/session/refresh", requestBody, userContext)
 	if err != nil {
 		return sessmodels.CreateOrRefreshAPIResponse{}, err
 	}
@@ -237,6 +309,7 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 		if err != nil {
 			return sessmodels.CreateOrRefreshAPIResponse{}, err
 		}
+		supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameRefreshes, supertokens.RecipeIDLogField(RECIPE_ID))
 		return result, nil
 	} else if response["status"].(string) == errors.UnauthorizedErrorStr {
 		supertokens.LogDebugMessage("refreshSession: Returning UNAUTHORISED because of core response")
@@ -247,7 +320,12 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 			UserID:        (response["session"].(map[string]interface{}))["userId"].(string),
 		}
 
-		supertokens.LogDebugMessage("refreshSession: Returning TOKEN_THEFT_DETECTED because of core response")
+		event := getTokenTheftEvent(config, sessionInfo.SessionHandle, sessionInfo.UserID, tokenTransferMethod, req, userContext)
+		supertokens.LogWarnMessage("refreshSession: Returning TOKEN_THEFT_DETECTED because of core response", getTokenTheftEventLogFields(event)...)
+		if config.OnTokenTheftEvent != nil {
+			config.OnTokenTheftEvent(event, userContext)
+		}
+		supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameTokenThefts, supertokens.RecipeIDLogField(RECIPE_ID))
 		return sessmodels.CreateOrRefreshAPIResponse{}, errors.TokenTheftDetectedError{
 			Msg:     "Token theft detected",
 			Payload: sessionInfo,
@@ -255,10 +333,10 @@ func refreshSessionHelper(recipeImplHandshakeInfo *sessmodels.HandshakeInfo, con
 	}
 }
 
-func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string) ([]string, error) {
+func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string, userContext supertokens.UserContext) ([]string, error) {
 	response, err := querier.SendPostRequest("/recipe/session/remove", map[string]interface{}{
 		"userId": userID,
-	})
+	}, userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -274,10 +352,10 @@ func revokeAllSessionsForUserHelper(querier supertokens.Querier, userID string)
 	return result, nil
 }
 
-func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID string) ([]string, error) {
+func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID string, userContext supertokens.UserContext) ([]string, error) {
 	response, err := querier.SendGetRequest("/recipe/session/user", map[string]string{
 		"userId": userID,
-	})
+	}, userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -293,22 +371,22 @@ func getAllSessionHandlesForUserHelper(querier supertokens.Querier, userID strin
 	return result, nil
 }
 
-func revokeSessionHelper(querier supertokens.Querier, sessionHandle string) (bool, error) {
+func revokeSessionHelper(querier supertokens.Querier, sessionHandle string, userContext supertokens.UserContext) (bool, error) {
 	response, err := querier.SendPostRequest("/recipe/session/remove",
 		map[string]interface{}{
 			"sessionHandles": [1]string{sessionHandle},
-		})
+		}, userContext)
 	if err != nil {
 		return false, err
 	}
 	return len(response["sessionHandlesRevoked"].([]interface{})) == 1, nil
 }
 
-func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []string) ([]string, error) {
+func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []string, userContext supertokens.UserContext) ([]string, error) {
 	response, err := querier.SendPostRequest("/recipe/session/remove",
 		map[string]interface{}{
 			"sessionHandles": sessionHandles,
-		})
+		}, userContext)
 	if err != nil {
 		return nil, err
 	}
@@ -323,7 +401,7 @@ func revokeMultipleSessionsHelper(querier supertokens.Querier, sessionHandles []
 	return result, nil
 }
 
-func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string, newSessionData map[string]interface{}) (bool, error) {
+func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string, newSessionData map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
 	if newSessionData == nil {
 		newSessionData = map[string]interface{}{}
 	}
@@ -331,7 +409,7 @@ func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string,
 		map[string]interface{}{
 			"sessionHandle":      sessionHandle,
 			"userDataInDatabase": newSessionData,
-		})
+		}, userContext)
 	if err != nil {
 		return false, err
 	}
@@ -341,14 +419,14 @@ func updateSessionDataHelper(querier supertokens.Querier, sessionHandle string,
 	return true, nil
 }
 
-func updateAccessTokenPayloadHelper(querier supertokens.Querier, sessionHandle string, newAccessTokenPayload map[string]interface{}) (bool, error) {
+func updateAccessTokenPayloadHelper(querier supertokens.Querier, sessionHandle string, newAccessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (bool, error) {
 	if newAccessTokenPayload == nil {
 		newAccessTokenPayload = map[string]interface{}{}
 	}
 	response, err := querier.SendPutRequest("/recipe/jwt/data", map[string]interface{}{
 		"sessionHandle": sessionHandle,
 		"userDataInJWT": newAccessTokenPayload,
-	})
+	}, userContext)
 	if err != nil {
 		return false, err
 	}
@@ -358,14 +436,14 @@ func updateAccessTokenPayloadHelper(querier supertokens.Querier, sessionHandle s
 	return true, nil
 }
 
-func regenerateAccessTokenHelper(querier supertokens.Querier, newAccessTokenPayload *map[string]interface{}, accessToken string) (*sessmodels.RegenerateAccessTokenResponse, error) {
+func regenerateAccessTokenHelper(querier supertokens.Querier, newAccessTokenPayload *map[string]interface{}, accessToken string, userContext supertokens.UserContext) (*sessmodels.RegenerateAccessTokenResponse, error) {
 	if newAccessTokenPayload == nil {
 		newAccessTokenPayload = &map[string]interface{}{}
 	}
 	response, err := querier.SendPostRequest("/recipe/session/regenerate", map[string]interface{}{
 		"accessToken":   accessToken,
 		"userDataInJWT": newAccessTokenPayload,
-	})
+	}, userContext)
 	if err != nil {
 		return nil, err
 	}
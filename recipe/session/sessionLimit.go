@@ -0,0 +1,141 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// makeRecipeImplementationWithSessionLimit changes the functions creating sessions of originalImplementation
+// so that a user cannot have more than config.MaxSessions sessions
+func makeRecipeImplementationWithSessionLimit(originalImplementation sessmodels.RecipeInterface, config sessmodels.SessionLimitConfig) sessmodels.RecipeInterface {
+	originalGetAllSessionHandlesForUser := *originalImplementation.GetAllSessionHandlesForUser
+	originalGetSessionInformation := *originalImplementation.GetSessionInformation
+	originalRevokeMultipleSessions := *originalImplementation.RevokeMultipleSessions
+
+	// checkSessionLimit is called before creating a session, and returns an error if it cannot be created
+	checkSessionLimit := func(userID string, userContext supertokens.UserContext) error {
+		if config.Policy != sessmodels.RejectNewSessionPolicy {
+			return nil
+		}
+		sessionHandles, err := originalGetAllSessionHandlesForUser(userID, userContext)
+		if err != nil {
+			return err
+		}
+		if len(sessionHandles) >= config.MaxSessions {
+			supertokens.LogDebugMessage("createNewSession: rejecting new session because the user has " + strconv.Itoa(len(sessionHandles)) + " sessions")
+			return errors.SessionLimitReachedError{
+				Msg:    "the user already has the maximum number of sessions",
+				UserID: userID,
+			}
+		}
+		return nil
+	}
+
+	// revokeOldestSessions revokes the oldest sessions of the user above the limit, except the new one
+	revokeOldestSessions := func(userID string, newSessionHandle string, userContext supertokens.UserContext) error {
+		if config.Policy != sessmodels.EvictOldestSessionPolicy {
+			return nil
+		}
+		sessionHandles, err := originalGetAllSessionHandlesForUser(userID, userContext)
+		if err != nil {
+			return err
+		}
+		if len(sessionHandles) <= config.MaxSessions {
+			return nil
+		}
+
+		otherSessions := []*sessmodels.SessionInformation{}
+		for _, sessionHandle := range sessionHandles {
+			if sessionHandle == newSessionHandle {
+				continue
+			}
+			sessionInformation, err := originalGetSessionInformation(sessionHandle, userContext)
+			if err != nil {
+				return err
+			}
+			if sessionInformation != nil {
+				otherSessions = append(otherSessions, sessionInformation)
+			}
+		}
+		sort.Slice(otherSessions, func(i, j int) bool {
+			return otherSessions[i].TimeCreated < otherSessions[j].TimeCreated
+		})
+
+		sessionHandlesToRevoke := []string{}
+		for i := 0; i < len(otherSessions)+1-config.MaxSessions; i++ {
+			sessionHandlesToRevoke = append(sessionHandlesToRevoke, otherSessions[i].SessionHandle)
+		}
+		if len(sessionHandlesToRevoke) == 0 {
+			return nil
+		}
+		supertokens.LogDebugMessage("createNewSession: revoking the " + strconv.Itoa(len(sessionHandlesToRevoke)) + " oldest sessions of the user")
+		_, err = originalRevokeMultipleSessions(sessionHandlesToRevoke, userContext)
+		return err
+	}
+
+	// evictOldestSessions is called after creating a session, and revokes the oldest sessions of the user above the
+	// limit. The new session is already created, and its tokens can be attached to the response, so the failures
+	// are only logged.
+	evictOldestSessions := func(userID string, newSessionHandle string, userContext supertokens.UserContext) {
+		err := revokeOldestSessions(userID, newSessionHandle, userContext)
+		if err != nil {
+			supertokens.LogWarnMessage("createNewSession: could not revoke the oldest sessions of the user", supertokens.RecipeIDLogField(RECIPE_ID), supertokens.UserIDLogField(userID), supertokens.ErrorLogField(err))
+		}
+	}
+
+	{
+		originalCreateNewSession := *originalImplementation.CreateNewSession
+
+		(*originalImplementation.CreateNewSession) = func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			err := checkSessionLimit(userID, userContext)
+			if err != nil {
+				return nil, err
+			}
+			sessionContainer, err := originalCreateNewSession(req, res, userID, accessTokenPayload, sessionData, userContext)
+			if err != nil {
+				return nil, err
+			}
+			evictOldestSessions(userID, sessionContainer.GetHandleWithContext(userContext), userContext)
+			return sessionContainer, nil
+		}
+	}
+
+	{
+		originalCreateNewSessionWithoutRequestResponse := *originalImplementation.CreateNewSessionWithoutRequestResponse
+
+		(*originalImplementation.CreateNewSessionWithoutRequestResponse) = func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			err := checkSessionLimit(userID, userContext)
+			if err != nil {
+				return nil, err
+			}
+			sessionContainer, err := originalCreateNewSessionWithoutRequestResponse(userID, accessTokenPayload, sessionData, disableAntiCsrf, userContext)
+			if err != nil {
+				return nil, err
+			}
+			evictOldestSessions(userID, sessionContainer.GetHandleWithContext(userContext), userContext)
+			return sessionContainer, nil
+		}
+	}
+
+	return originalImplementation
+}
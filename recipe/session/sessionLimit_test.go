@@ -0,0 +1,105 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func TestSessionLimitConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "api.supertokens.io",
+		WebsiteDomain: "supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{SessionLimit: &sessmodels.SessionLimitConfig{MaxSessions: 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, sessmodels.RejectNewSessionPolicy, config.SessionLimit.Policy)
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{SessionLimit: &sessmodels.SessionLimitConfig{}})
+	assert.EqualError(t, err, "SessionLimit.MaxSessions must be positive")
+
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{SessionLimit: &sessmodels.SessionLimitConfig{MaxSessions: 3, Policy: "EVICT_NEWEST"}})
+	assert.EqualError(t, err, "SessionLimit.Policy must be either REJECT_NEW_SESSION or EVICT_OLDEST_SESSION")
+}
+
+func TestSessionIsCreatedWhenTheOldestSessionsCannotBeEvicted(t *testing.T) {
+	newSession := &sessmodels.TypeSessionContainer{
+		GetHandleWithContext: func(userContext supertokens.UserContext) string {
+			return "newSessionHandle"
+		},
+	}
+	createNewSession := func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		return newSession, nil
+	}
+	createNewSessionWithoutRequestResponse := func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		return newSession, nil
+	}
+	getAllSessionHandlesForUser := func(userID string, userContext supertokens.UserContext) ([]string, error) {
+		return []string{"oldSessionHandle", "newSessionHandle"}, nil
+	}
+	getSessionInformation := func(sessionHandle string, userContext supertokens.UserContext) (*sessmodels.SessionInformation, error) {
+		return &sessmodels.SessionInformation{SessionHandle: sessionHandle}, nil
+	}
+	revokeMultipleSessions := func(sessionHandles []string, userContext supertokens.UserContext) ([]string, error) {
+		return nil, errors.New("core error")
+	}
+	recipeImplementation := makeRecipeImplementationWithSessionLimit(sessmodels.RecipeInterface{
+		CreateNewSession:                       &createNewSession,
+		CreateNewSessionWithoutRequestResponse: &createNewSessionWithoutRequestResponse,
+		GetAllSessionHandlesForUser:            &getAllSessionHandlesForUser,
+		GetSessionInformation:                  &getSessionInformation,
+		RevokeMultipleSessions:                 &revokeMultipleSessions,
+	}, sessmodels.SessionLimitConfig{MaxSessions: 1, Policy: sessmodels.EvictOldestSessionPolicy})
+
+	// the failure is only logged, since the new session is already created
+	sessionContainer, err := (*recipeImplementation.CreateNewSession)(httptest.NewRequest(http.MethodPost, "/signin", nil), httptest.NewRecorder(), "userId", nil, nil, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, newSession, sessionContainer)
+	sessionContainer, err = (*recipeImplementation.CreateNewSessionWithoutRequestResponse)("userId", nil, nil, true, &map[string]interface{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, newSession, sessionContainer)
+}
+
+func TestSessionLimitEvictsOldestSessionsAgainstFakeCore(t *testing.T) {
+	startFakeCoreForTest(t, &sessmodels.TypeInput{
+		SessionLimit: &sessmodels.SessionLimitConfig{MaxSessions: 2, Policy: sessmodels.EvictOldestSessionPolicy},
+	})
+
+	sessionHandles := []string{}
+	for i := 0; i < 4; i++ {
+		newSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+		assert.NoError(t, err)
+		sessionHandles = append(sessionHandles, newSession.GetHandle())
+		// the sessions are ordered by creation time, which has a millisecond precision
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	remainingSessionHandles, err := GetAllSessionHandlesForUser("userId")
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, sessionHandles[2:], remainingSessionHandles)
+}
@@ -67,21 +67,53 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 		return originalUpdateAccessTokenPayload(sessionInformation.SessionHandle, newAccessTokenPayload, userContext)
 	}
 
+	addJWTToNewAccessTokenPayload := func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+		if accessTokenPayload == nil {
+			accessTokenPayload = map[string]interface{}{}
+		}
+		accessTokenValidityInSeconds, err := (*originalImplementation.GetAccessTokenLifeTimeMS)(userContext)
+		if err != nil {
+			return nil, err
+		}
+		accessTokenValidityInSeconds = uint64(math.Ceil(float64(accessTokenValidityInSeconds) / 1000))
+
+		return addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, userID, config.Jwt.PropertyNameInAccessTokenPayload, openidRecipeImplementation, userContext)
+	}
+
+	// refreshSessionWithJWT refreshes the session using refreshSession, and then updates the JWT in the new access token
+	refreshSessionWithJWT := func(refreshSession func() (sessmodels.SessionContainer, error), userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+		accessTokenValidityInSeconds, err := (*originalImplementation.GetAccessTokenLifeTimeMS)(userContext)
+		if err != nil {
+			return nil, err
+		}
+		accessTokenValidityInSeconds = uint64(math.Ceil(float64(accessTokenValidityInSeconds) / 1000))
+
+		// Refresh session first because this will create a new access token
+		newSession, err := refreshSession()
+		if err != nil {
+			return nil, err
+		}
+		accessTokenPayload := newSession.GetAccessTokenPayloadWithContext(userContext)
+
+		accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, newSession.GetUserIDWithContext(userContext), config.Jwt.PropertyNameInAccessTokenPayload, openidRecipeImplementation, userContext)
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = (newSession.UpdateAccessTokenPayloadWithContext)(accessTokenPayload, userContext)
+		if err != nil {
+			return nil, err
+		}
+
+		return newSessionWithJWTContainer(newSession, openidRecipeImplementation), nil
+	}
+
 	{
 		originalCreateNewSession := *originalImplementation.CreateNewSession
 
 		(*originalImplementation.CreateNewSession) = func(req *http.Request, res http.ResponseWriter, userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-			if accessTokenPayload == nil {
-				accessTokenPayload = map[string]interface{}{}
-			}
-			accessTokenValidityInSeconds, err := (*originalImplementation.GetAccessTokenLifeTimeMS)(userContext)
-			if err != nil {
-				return nil, err
-			}
-			accessTokenValidityInSeconds = uint64(math.Ceil(float64(accessTokenValidityInSeconds) / 1000))
-
-			accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, userID, config.Jwt.PropertyNameInAccessTokenPayload, openidRecipeImplementation, userContext)
-
+			accessTokenPayload, err := addJWTToNewAccessTokenPayload(userID, accessTokenPayload, userContext)
 			if err != nil {
 				return nil, err
 			}
@@ -120,31 +152,52 @@ func MakeRecipeImplementation(originalImplementation sessmodels.RecipeInterface,
 		originalRefreshSession := *originalImplementation.RefreshSession
 
 		(*originalImplementation.RefreshSession) = func(req *http.Request, res http.ResponseWriter, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
-			accessTokenValidityInSeconds, err := (*originalImplementation.GetAccessTokenLifeTimeMS)(userContext)
+			return refreshSessionWithJWT(func() (sessmodels.SessionContainer, error) {
+				return originalRefreshSession(req, res, userContext)
+			}, userContext)
+		}
+	}
+
+	{
+		originalCreateNewSessionWithoutRequestResponse := *originalImplementation.CreateNewSessionWithoutRequestResponse
+
+		(*originalImplementation.CreateNewSessionWithoutRequestResponse) = func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			accessTokenPayload, err := addJWTToNewAccessTokenPayload(userID, accessTokenPayload, userContext)
 			if err != nil {
 				return nil, err
 			}
-			accessTokenValidityInSeconds = uint64(math.Ceil(float64(accessTokenValidityInSeconds) / 1000))
 
-			// Refresh session first because this will create a new access token
-			newSession, err := originalRefreshSession(req, res, userContext)
+			sessionContainer, err := originalCreateNewSessionWithoutRequestResponse(userID, accessTokenPayload, sessionData, disableAntiCsrf, userContext)
 			if err != nil {
-				return nil, err
+				return sessionContainer, err
 			}
-			accessTokenPayload := newSession.GetAccessTokenPayloadWithContext(userContext)
 
-			accessTokenPayload, err = addJWTToAccessTokenPayload(accessTokenPayload, accessTokenValidityInSeconds+EXPIRY_OFFSET_SECONDS, newSession.GetUserIDWithContext(userContext), config.Jwt.PropertyNameInAccessTokenPayload, openidRecipeImplementation, userContext)
+			return newSessionWithJWTContainer(sessionContainer, openidRecipeImplementation), nil
+		}
+	}
+
+	{
+		originalGetSessionWithoutRequestResponse := *originalImplementation.GetSessionWithoutRequestResponse
 
+		(*originalImplementation.GetSessionWithoutRequestResponse) = func(accessToken string, antiCsrfToken *string, options *sessmodels.VerifySessionOptions, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			sessionContainer, err := originalGetSessionWithoutRequestResponse(accessToken, antiCsrfToken, options, userContext)
 			if err != nil {
 				return nil, err
 			}
-
-			err = (newSession.UpdateAccessTokenPayloadWithContext)(accessTokenPayload, userContext)
-			if err != nil {
-				return nil, err
+			if sessionContainer == nil {
+				return nil, nil
 			}
+			return newSessionWithJWTContainer(sessionContainer, openidRecipeImplementation), nil
+		}
+	}
+
+	{
+		originalRefreshSessionWithoutRequestResponse := *originalImplementation.RefreshSessionWithoutRequestResponse
 
-			return newSessionWithJWTContainer(newSession, openidRecipeImplementation), nil
+		(*originalImplementation.RefreshSessionWithoutRequestResponse) = func(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (sessmodels.SessionContainer, error) {
+			return refreshSessionWithJWT(func() (sessmodels.SessionContainer, error) {
+				return originalRefreshSessionWithoutRequestResponse(refreshToken, disableAntiCsrf, antiCsrfToken, userContext)
+			}, userContext)
 		}
 	}
 
@@ -26,9 +26,34 @@ type APIInterface struct {
 	RefreshPOST   *func(options APIOptions, userContext supertokens.UserContext) (SessionContainer, error)
 	SignOutPOST   *func(sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (SignOutPOSTResponse, error)
 	VerifySession *func(verifySessionOptions *VerifySessionOptions, options APIOptions, userContext supertokens.UserContext) (SessionContainer, error)
+
+	ActiveSessionsGET       *func(sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (ActiveSessionsGETResponse, error)
+	RevokeActiveSessionPOST *func(sessionHandle string, sessionContainer SessionContainer, options APIOptions, userContext supertokens.UserContext) (RevokeActiveSessionPOSTResponse, error)
 }
 
 type SignOutPOSTResponse struct {
 	OK           *struct{}
 	GeneralError *supertokens.GeneralErrorResponse
 }
+
+type ActiveSessionsGETResponse struct {
+	OK *struct {
+		Sessions []ActiveSession
+	}
+	GeneralError *supertokens.GeneralErrorResponse
+}
+
+type ActiveSession struct {
+	SessionHandle    string      `json:"sessionHandle"`
+	IsCurrentSession bool        `json:"isCurrentSession"`
+	TimeCreated      uint64      `json:"timeCreated"`
+	Expiry           uint64      `json:"expiry"`
+	DeviceInfo       *DeviceInfo `json:"deviceInfo,omitempty"`
+}
+
+type RevokeActiveSessionPOSTResponse struct {
+	OK *struct{}
+	// UnknownSessionError is returned if the session does not exist or belongs to another user
+	UnknownSessionError *struct{}
+	GeneralError        *supertokens.GeneralErrorResponse
+}
@@ -96,6 +96,21 @@ type GetSessionResponse struct {
 	AccessToken CreateOrRefreshAPIResponseToken `json:"accessToken"`
 }
 
+// SessionTokens are the tokens of a session. When a session is created, verified or refreshed without
+// a request, they have to be sent to the client instead of the response headers or cookies.
+type SessionTokens struct {
+	AccessToken string
+	// AccessTokenExpiry is the time the access token expires at, in milliseconds
+	AccessTokenExpiry uint64
+	// RefreshToken is only set if the session was created or refreshed
+	RefreshToken  *string
+	AntiCsrfToken *string
+	FrontToken    string
+	// AccessAndFrontTokenUpdated is true if the access and front tokens were created or changed,
+	// and have to be sent to the client
+	AccessAndFrontTokenUpdated bool
+}
+
 type RegenerateAccessTokenResponse struct {
 	Status      string                          `json:"status"`
 	Session     SessionStruct                   `json:"session"`
@@ -113,6 +128,206 @@ type TypeInput struct {
 	ErrorHandlers            *ErrorHandlers
 	Jwt                      *JWTInputConfig
 	GetTokenTransferMethod   func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) TokenTransferMethod
+	NetworklessVerification  *NetworklessVerificationConfig
+	IdleTimeout              *IdleTimeoutConfig
+	DeviceInfo               *DeviceInfoConfig
+	SessionLimit             *SessionLimitConfig
+	Cookies                  *CookiesConfig
+	// OlderCookieDomains are the domains the cookies were set for before CookieDomain was changed. The cookies
+	// set for these domains are cleared when the session is refreshed or cleared. Use "" for the cookies that
+	// were set without a domain.
+	OlderCookieDomains []string
+	// GetCookieDomain and GetCookieSameSite choose the domain and SameSite attribute of the cookies for each request,
+	// so that a backend can serve several website domains. They override CookieDomain and CookieSameSite.
+	// GetCookieDomain returning nil sets the cookies without a domain.
+	GetCookieDomain   func(req *http.Request, userContext supertokens.UserContext) *string
+	GetCookieSameSite func(req *http.Request, userContext supertokens.UserContext) string
+	RefreshCoalescing *RefreshCoalescingConfig
+	// OnTokenTheftEvent is called with the details of the request whenever token theft is detected, before the
+	// OnTokenTheftDetected error handler. It should not write to the response.
+	OnTokenTheftEvent func(event TokenTheftEvent, userContext supertokens.UserContext)
+	Fingerprint       *FingerprintConfig
+	DPoP              *DPoPConfig
+}
+
+type CookieNamePrefix string
+
+const (
+	// HostCookieNamePrefix requires the cookie to be secure, to have no domain and to have the path /
+	HostCookieNamePrefix CookieNamePrefix = "__Host-"
+	// SecureCookieNamePrefix requires the cookie to be secure
+	SecureCookieNamePrefix CookieNamePrefix = "__Secure-"
+)
+
+// CookiesConfig changes the names and attributes of the cookies holding the access and refresh tokens
+type CookiesConfig struct {
+	// AccessTokenName and RefreshTokenName default to "sAccessToken" and "sRefreshToken"
+	AccessTokenName  *string
+	RefreshTokenName *string
+	// AccessTokenNamePrefix and RefreshTokenNamePrefix are added before the names of the cookies, so that browsers
+	// enforce their attributes. HostCookieNamePrefix cannot be used for the refresh token, whose path is the one of the refresh API.
+	AccessTokenNamePrefix  CookieNamePrefix
+	RefreshTokenNamePrefix CookieNamePrefix
+	// Partitioned adds the Partitioned attribute (CHIPS) to the cookies, so that they can be used when the
+	// website is embedded in an iframe of another site. It requires CookieSecure. Since a partitioned cookie does not
+	// replace an unpartitioned one, the unpartitioned cookies with the same names are cleared when the tokens are set.
+	Partitioned bool
+	// OldAccessTokenNames and OldRefreshTokenNames are the full names the cookies had before they were changed.
+	// The tokens are read from these cookies if they are not found under the new names, and these cookies are
+	// cleared whenever the new ones are set, which happens at the latest when the session is refreshed.
+	OldAccessTokenNames  []string
+	OldRefreshTokenNames []string
+}
+
+// NetworklessVerificationConfig enables verifying access tokens without querying the core.
+// The signing keys are fetched once, and then refreshed in the background. Since the core is
+// not queried, revoked sessions stay valid until their access token expires, even if access
+// token blacklisting is enabled in the core.
+type NetworklessVerificationConfig struct {
+	// JWKSEndpoint is the URL of a JSON Web Key Set with the keys the access tokens are signed
+	// with. If not set, the keys are fetched from the core. In both cases, the requests are sent
+	// with the HTTPClient (or Transport) of the supertokens.ConnectionInfo.
+	JWKSEndpoint *string
+	// RefreshInterval is how long the keys are used before they are refreshed in the background.
+	// Defaults to 1 minute. The max-age of the JWKS response's Cache-Control header takes precedence.
+	RefreshInterval *time.Duration
+	// MaxStaleness is how long the keys can still be used if they cannot be refreshed, for example
+	// because the core is down. Defaults to 24 hours.
+	MaxStaleness *time.Duration
+}
+
+// IdleTimeoutConfig revokes sessions that were not verified or refreshed for longer than their idle timeout.
+// The time of the last activity is stored in the access token payload, and is only updated once per
+// ActivityUpdateInterval, so a session can be revoked up to ActivityUpdateInterval before its idle timeout.
+type IdleTimeoutConfig struct {
+	// Timeout is the idle timeout of the sessions for which GetTimeout is not set
+	Timeout *time.Duration
+	// GetTimeout returns the idle timeout of a session when it is created. The userContext is the one passed
+	// to CreateNewSession, so it can be used to pass a different timeout for each session. Returning 0
+	// disables the idle timeout for the session.
+	GetTimeout func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) time.Duration
+	// ActivityUpdateInterval is how often the time of the last activity is updated. Defaults to 1 minute,
+	// or half of the session's idle timeout if it is shorter.
+	ActivityUpdateInterval *time.Duration
+}
+
+// DeviceInfoConfig enables storing the user agent, IP address, device name and last seen time of
+// sessions when they are created or refreshed, and the APIs listing and revoking the active sessions of
+// the user. The device info is kept in the session data under a reserved key, which is not returned with
+// the session data, and is updated on a best-effort basis when the sessions are refreshed.
+type DeviceInfoConfig struct {
+	// GetIPAddress returns the IP address of the client. Defaults to the host of the request's RemoteAddr,
+	// so it has to be set to read the X-Forwarded-For header (or similar) if the API is behind a proxy.
+	GetIPAddress func(req *http.Request, userContext supertokens.UserContext) string
+	// GetDeviceName returns a human readable name for the device, like "Chrome on Windows". Defaults
+	// to a guess based on the user agent.
+	GetDeviceName func(userAgent string, userContext supertokens.UserContext) string
+}
+
+type SessionLimitPolicy string
+
+const (
+	// RejectNewSessionPolicy makes CreateNewSession fail with a SessionLimitReachedError once the limit is reached
+	RejectNewSessionPolicy SessionLimitPolicy = "REJECT_NEW_SESSION"
+	// EvictOldestSessionPolicy makes CreateNewSession revoke the oldest sessions of the user to stay within the limit.
+	// If they cannot be revoked, the failure is logged and the new session is still created.
+	EvictOldestSessionPolicy SessionLimitPolicy = "EVICT_OLDEST_SESSION"
+)
+
+// SessionLimitConfig limits how many sessions a user can have at the same time. With RejectNewSessionPolicy,
+// the sessions of the user are counted before the new one is created, without any lock, so concurrent sign ins
+// of the same user can exceed MaxSessions.
+type SessionLimitConfig struct {
+	MaxSessions int
+	// Policy defaults to RejectNewSessionPolicy
+	Policy SessionLimitPolicy
+}
+
+// RefreshCoalescingConfig makes concurrent refreshes using the same refresh token in this process share a
+// single call to the core, so that browser tabs or retried requests racing to refresh a session do not
+// trigger token theft detection. The tokens returned by a refresh are also reused for requests with the
+// same refresh token for GracePeriod. If the request whose refresh is shared is cancelled, the waiting
+// requests call the core again. Refreshes handled by other processes are not coalesced.
+type RefreshCoalescingConfig struct {
+	// GracePeriod defaults to 5 seconds. Setting it to 0 only coalesces the refreshes that are in progress.
+	GracePeriod *time.Duration
+}
+
+// TokenTheftEvent describes a refresh that made the core detect token theft, for investigation. The request
+// fields are empty if the refresh was not done while handling a request.
+type TokenTheftEvent struct {
+	SessionHandle       string
+	UserID              string
+	DetectedAt          time.Time
+	TokenTransferMethod TokenTransferMethod
+	IPAddress           string
+	UserAgent           string
+	Method              string
+	Path                string
+	Origin              string
+}
+
+type FingerprintMismatchPolicy string
+
+const (
+	// IgnoreFingerprintMismatchPolicy only stores the fingerprint in the sessions
+	IgnoreFingerprintMismatchPolicy FingerprintMismatchPolicy = "IGNORE"
+	// LogFingerprintMismatchPolicy logs a warning when a session is used by a client that does not match its fingerprint
+	LogFingerprintMismatchPolicy FingerprintMismatchPolicy = "LOG"
+	// InvalidClaimFingerprintMismatchPolicy adds the validator of the fingerprint claim to the global claim validators,
+	// so that GetSession fails with an InvalidClaimError, and the frontend can ask the user to authenticate again
+	InvalidClaimFingerprintMismatchPolicy FingerprintMismatchPolicy = "INVALID_CLAIM"
+	// RevokeFingerprintMismatchPolicy revokes the session, and makes GetSession fail with an UnauthorizedError
+	// with the FINGERPRINT_MISMATCH reason
+	RevokeFingerprintMismatchPolicy FingerprintMismatchPolicy = "REVOKE"
+)
+
+// FingerprintConfig binds sessions to the client characteristics of the request creating them, stored as hashes
+// in the "st-fp" claim. When a session is verified, the characteristics of the request are compared with them, and
+// mismatches are handled by MismatchPolicy. Sessions created without a request (or before the fingerprint was
+// enabled) are not bound.
+type FingerprintConfig struct {
+	// BindIPSubnet binds the sessions to the subnet of the client's IP address, so that clients can change
+	// their address within their network
+	BindIPSubnet bool
+	// IPv4SubnetPrefixLength and IPv6SubnetPrefixLength default to 24 and 64
+	IPv4SubnetPrefixLength *int
+	IPv6SubnetPrefixLength *int
+	// GetIPAddress returns the IP address of the client. Defaults to DeviceInfo.GetIPAddress if DeviceInfo is
+	// enabled, and to the host of the request's RemoteAddr otherwise.
+	GetIPAddress func(req *http.Request, userContext supertokens.UserContext) string
+	// BindUserAgent binds the sessions to the User-Agent header of the client
+	BindUserAgent bool
+	// HeaderName binds the sessions to the value of a custom header, like a device ID sent by an app
+	HeaderName *string
+	// MismatchPolicy defaults to LogFingerprintMismatchPolicy
+	MismatchPolicy FingerprintMismatchPolicy
+}
+
+// DPoPConfig enables sender-constrained access tokens (RFC 9449) for the header transfer method. When a session is
+// created with the header transfer method and a DPoP proof, the thumbprint of the proof's key is stored in the "cnf"
+// claim of the access token payload. The access token can then only be used with a DPoP proof signed by the same key,
+// for the method and URL of the request, which is checked by GetSession and RefreshSession.
+type DPoPConfig struct {
+	// Required makes CreateNewSession fail without a DPoP proof when it uses the header transfer method
+	Required bool
+	// MaxProofAge is how long a proof can be used after it was issued, which is also allowed as clock skew for
+	// the proofs issued in the future. Defaults to 1 minute.
+	MaxProofAge *time.Duration
+	// AllowedAlgorithms defaults to ES256, ES384, RS256, PS256 and EdDSA
+	AllowedAlgorithms []string
+	// GetRequestURL returns the URL the proof of a request must be for, without its query and fragment. Defaults to
+	// the API domain followed by the path of the request, so it has to be set if a proxy changes the path.
+	GetRequestURL func(req *http.Request, userContext supertokens.UserContext) string
+	// ReplayCache stores the IDs of the proofs, so that each proof can only be used once. Defaults to an in memory
+	// cache, which does not detect proofs replayed to other instances of the API.
+	ReplayCache DPoPReplayCache
+}
+
+// DPoPReplayCache stores the IDs (jti) of the DPoP proofs that were used
+type DPoPReplayCache interface {
+	// Add stores jti until expiresAt, and returns false if it is already stored
+	Add(jti string, expiresAt time.Time, userContext supertokens.UserContext) (bool, error)
 }
 
 type JWTInputConfig struct {
@@ -131,6 +346,8 @@ type ErrorHandlers struct {
 	OnUnauthorised       func(message string, req *http.Request, res http.ResponseWriter) error
 	OnTokenTheftDetected func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
 	OnInvalidClaim       func(validationErrors []claims.ClaimValidationError, req *http.Request, res http.ResponseWriter) error
+	// OnIdleTimeout is called instead of OnUnauthorised if the session was revoked because of its idle timeout
+	OnIdleTimeout func(message string, req *http.Request, res http.ResponseWriter) error
 }
 
 type TypeNormalisedInput struct {
@@ -138,6 +355,9 @@ type TypeNormalisedInput struct {
 	CookieDomain             *string
 	CookieSameSite           string
 	CookieSecure             bool
+	OlderCookieDomains       []string
+	GetCookieDomain          func(req *http.Request, userContext supertokens.UserContext) *string
+	GetCookieSameSite        func(req *http.Request, userContext supertokens.UserContext) string
 	SessionExpiredStatusCode int
 	InvalidClaimStatusCode   int
 	AntiCsrf                 string
@@ -145,6 +365,72 @@ type TypeNormalisedInput struct {
 	ErrorHandlers            NormalisedErrorHandlers
 	Jwt                      JWTNormalisedConfig
 	GetTokenTransferMethod   func(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) TokenTransferMethod
+	NetworklessVerification  *NetworklessVerificationNormalisedConfig
+	IdleTimeout              *IdleTimeoutNormalisedConfig
+	DeviceInfo               *DeviceInfoNormalisedConfig
+	SessionLimit             *SessionLimitConfig
+	Cookies                  CookiesNormalisedConfig
+	RefreshCoalescing        *RefreshCoalescingNormalisedConfig
+	OnTokenTheftEvent        func(event TokenTheftEvent, userContext supertokens.UserContext)
+	Fingerprint              *FingerprintNormalisedConfig
+	DPoP                     *DPoPNormalisedConfig
+}
+
+type RefreshCoalescingNormalisedConfig struct {
+	GracePeriod time.Duration
+}
+
+type DPoPNormalisedConfig struct {
+	Required          bool
+	MaxProofAge       time.Duration
+	AllowedAlgorithms []string
+	GetRequestURL     func(req *http.Request, userContext supertokens.UserContext) string
+	ReplayCache       DPoPReplayCache
+}
+
+type FingerprintNormalisedConfig struct {
+	BindIPSubnet           bool
+	IPv4SubnetPrefixLength int
+	IPv6SubnetPrefixLength int
+	GetIPAddress           func(req *http.Request, userContext supertokens.UserContext) string
+	BindUserAgent          bool
+	HeaderName             *string
+	MismatchPolicy         FingerprintMismatchPolicy
+}
+
+type CookiesNormalisedConfig struct {
+	// AccessTokenName and RefreshTokenName include their prefix
+	AccessTokenName      string
+	RefreshTokenName     string
+	Partitioned          bool
+	OldAccessTokenNames  []string
+	OldRefreshTokenNames []string
+}
+
+type NetworklessVerificationNormalisedConfig struct {
+	JWKSEndpoint    *string
+	RefreshInterval time.Duration
+	MaxStaleness    time.Duration
+}
+
+type IdleTimeoutNormalisedConfig struct {
+	GetTimeout             func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) time.Duration
+	ActivityUpdateInterval time.Duration
+}
+
+type DeviceInfoNormalisedConfig struct {
+	GetIPAddress  func(req *http.Request, userContext supertokens.UserContext) string
+	GetDeviceName func(userAgent string, userContext supertokens.UserContext) string
+}
+
+type SigningKeySetStatus struct {
+	KeyCount int
+	// FetchedAt is when the keys were last fetched successfully, or the zero time if they never were
+	FetchedAt time.Time
+	Age       time.Duration
+	// IsStale is true if the keys should have been refreshed, but the last refresh failed or is still in progress
+	IsStale          bool
+	LastRefreshError error
 }
 
 type JWTNormalisedConfig struct {
@@ -175,6 +461,7 @@ type NormalisedErrorHandlers struct {
 	OnTryRefreshToken    func(message string, req *http.Request, res http.ResponseWriter) error
 	OnTokenTheftDetected func(sessionHandle string, userID string, req *http.Request, res http.ResponseWriter) error
 	OnInvalidClaim       func(validationErrors []claims.ClaimValidationError, req *http.Request, res http.ResponseWriter) error
+	OnIdleTimeout        func(message string, req *http.Request, res http.ResponseWriter) error
 }
 
 type TypeSessionContainer struct {
@@ -188,6 +475,9 @@ type TypeSessionContainer struct {
 	UpdateAccessTokenPayload func(newAccessTokenPayload map[string]interface{}) error // Deprecated: use MergeIntoAccessTokenPayload instead
 	GetTimeCreated           func() (uint64, error)
 	GetExpiry                func() (uint64, error)
+	// GetAllSessionTokensDangerously returns the tokens of the session, including the ones
+	// that are usually only sent to the client as cookies
+	GetAllSessionTokensDangerously func() SessionTokens
 
 	RevokeSessionWithContext            func(userContext supertokens.UserContext) error
 	GetSessionDataWithContext           func(userContext supertokens.UserContext) (map[string]interface{}, error)
@@ -200,6 +490,8 @@ type TypeSessionContainer struct {
 	GetTimeCreatedWithContext           func(userContext supertokens.UserContext) (uint64, error)
 	GetExpiryWithContext                func(userContext supertokens.UserContext) (uint64, error)
 
+	GetAllSessionTokensDangerouslyWithContext func(userContext supertokens.UserContext) SessionTokens
+
 	MergeIntoAccessTokenPayloadWithContext func(accessTokenPayloadUpdate map[string]interface{}, userContext supertokens.UserContext) error
 
 	AssertClaimsWithContext     func(claimValidators []claims.SessionClaimValidator, userContext supertokens.UserContext) error
@@ -226,6 +518,17 @@ type SessionInformation struct {
 	Expiry             uint64
 	AccessTokenPayload map[string]interface{}
 	TimeCreated        uint64
+	// DeviceInfo is nil if the session was not created or refreshed with DeviceInfo enabled
+	DeviceInfo *DeviceInfo
+}
+
+// DeviceInfo is the client a session was last created or refreshed from
+type DeviceInfo struct {
+	UserAgent  string `json:"userAgent"`
+	IPAddress  string `json:"ipAddress"`
+	DeviceName string `json:"deviceName"`
+	// LastSeen is the time of the last creation or refresh of the session, in milliseconds
+	LastSeen uint64 `json:"lastSeen"`
 }
 
 const SessionContext int = iota
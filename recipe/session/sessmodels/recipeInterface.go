@@ -38,6 +38,12 @@ type RecipeInterface struct {
 	GetRefreshTokenLifeTimeMS   *func(userContext supertokens.UserContext) (uint64, error)
 	RegenerateAccessToken       *func(accessToken string, newAccessTokenPayload *map[string]interface{}, userContext supertokens.UserContext) (*RegenerateAccessTokenResponse, error)
 
+	// The WithoutRequestResponse variants take the tokens as arguments instead of reading them from a request, and
+	// return a session whose tokens are read with GetAllSessionTokensDangerously instead of being set in a response
+	CreateNewSessionWithoutRequestResponse *func(userID string, accessTokenPayload map[string]interface{}, sessionData map[string]interface{}, disableAntiCsrf bool, userContext supertokens.UserContext) (SessionContainer, error)
+	GetSessionWithoutRequestResponse       *func(accessToken string, antiCsrfToken *string, options *VerifySessionOptions, userContext supertokens.UserContext) (SessionContainer, error)
+	RefreshSessionWithoutRequestResponse   *func(refreshToken string, disableAntiCsrf bool, antiCsrfToken *string, userContext supertokens.UserContext) (SessionContainer, error)
+
 	GetGlobalClaimValidators   *func(userId string, claimValidatorsAddedByOtherRecipes []claims.SessionClaimValidator, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error)
 	ValidateClaims             *func(userId string, accessTokenPayload map[string]interface{}, claimValidators []claims.SessionClaimValidator, userContext supertokens.UserContext) (ValidateClaimsResult, error)
 	ValidateClaimsInJWTPayload *func(userId string, jwtPayload map[string]interface{}, claimValidators []claims.SessionClaimValidator, userContext supertokens.UserContext) ([]claims.ClaimValidationError, error)
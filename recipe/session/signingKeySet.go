@@ -0,0 +1,313 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	b64 "encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+type signingKeysFetchResult struct {
+	keys        []sessmodels.KeyInfo
+	etag        string
+	notModified bool
+	maxAge      *time.Duration
+}
+
+// signingKeySet holds the keys used to verify access tokens without querying the core.
+// The keys are refreshed in the background once RefreshInterval has passed, and keep
+// being used while they are refreshed, or if refreshing them fails, until MaxStaleness.
+type signingKeySet struct {
+	config sessmodels.NetworklessVerificationNormalisedConfig
+	fetch  func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error)
+
+	lock           sync.Mutex
+	keys           []sessmodels.KeyInfo
+	etag           string
+	fetchedAt      time.Time
+	freshUntil     time.Time
+	refreshAfter   time.Time
+	lastRefreshErr error
+	// refreshDone is closed once the ongoing refresh is done, and is nil if there is none
+	refreshDone chan struct{}
+}
+
+func newSigningKeySet(config sessmodels.NetworklessVerificationNormalisedConfig, querier supertokens.Querier) *signingKeySet {
+	keySet := &signingKeySet{config: config}
+	if config.JWKSEndpoint != nil {
+		jwksEndpoint := *config.JWKSEndpoint
+		keySet.fetch = func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+			return fetchSigningKeysFromJWKSEndpoint(querier.GetHTTPClient(), jwksEndpoint, etag, userContext)
+		}
+	} else {
+		keySet.fetch = func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+			return fetchSigningKeysFromCore(querier, userContext)
+		}
+	}
+	return keySet
+}
+
+func (k *signingKeySet) getKeys(userContext supertokens.UserContext) ([]sessmodels.KeyInfo, error) {
+	k.lock.Lock()
+	if k.fetchedAt.IsZero() || time.Since(k.fetchedAt) > k.config.MaxStaleness {
+		k.lock.Unlock()
+		err := k.refresh(userContext)
+		if err != nil {
+			return nil, err
+		}
+		k.lock.Lock()
+	} else if time.Now().After(k.refreshAfter) && k.refreshDone == nil {
+		supertokens.LogDebugMessage("getSession: refreshing signing keys in the background")
+		go k.refresh(&map[string]interface{}{})
+	}
+	defer k.lock.Unlock()
+	return getUnexpiredKeys(k.keys), nil
+}
+
+// refreshIfOlderThan refreshes the keys if they were fetched more than age ago, and returns true if they were refreshed
+func (k *signingKeySet) refreshIfOlderThan(age time.Duration, userContext supertokens.UserContext) bool {
+	k.lock.Lock()
+	isOld := time.Since(k.fetchedAt) > age
+	k.lock.Unlock()
+	if !isOld {
+		return false
+	}
+	return k.refresh(userContext) == nil
+}
+
+func (k *signingKeySet) refresh(userContext supertokens.UserContext) error {
+	k.lock.Lock()
+	if k.refreshDone != nil {
+		// another refresh is in progress, so we wait for its result instead of fetching the keys again
+		refreshDone := k.refreshDone
+		k.lock.Unlock()
+		<-refreshDone
+		k.lock.Lock()
+		defer k.lock.Unlock()
+		return k.lastRefreshErr
+	}
+	refreshDone := make(chan struct{})
+	k.refreshDone = refreshDone
+	etag := k.etag
+	k.lock.Unlock()
+
+	result, err := k.fetch(etag, userContext)
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	defer close(refreshDone)
+	k.refreshDone = nil
+	k.lastRefreshErr = err
+	now := time.Now()
+	if err != nil {
+		k.refreshAfter = now.Add(signingKeysMinRefreshInterval)
+		supertokens.LogWarnMessage("getSession: could not refresh the access token signing keys", supertokens.RecipeIDLogField(RECIPE_ID), supertokens.ErrorLogField(err))
+		return err
+	}
+	if !result.notModified {
+		k.keys = result.keys
+		k.etag = result.etag
+	}
+	k.fetchedAt = now
+	refreshInterval := k.config.RefreshInterval
+	if result.maxAge != nil {
+		refreshInterval = *result.maxAge
+		if refreshInterval < signingKeysMinRefreshInterval {
+			refreshInterval = signingKeysMinRefreshInterval
+		}
+	}
+	k.freshUntil = now.Add(refreshInterval)
+	k.refreshAfter = k.freshUntil
+	return nil
+}
+
+// addKeys adds keys returned by the core when creating a session, so that the
+// access tokens signed with a new key can be verified before the next refresh
+func (k *signingKeySet) addKeys(keys []sessmodels.KeyInfo) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	newKeys := []sessmodels.KeyInfo{}
+	for _, key := range keys {
+		isKnown := false
+		for _, knownKey := range k.keys {
+			if knownKey.PublicKey == key.PublicKey {
+				isKnown = true
+				break
+			}
+		}
+		if !isKnown {
+			newKeys = append(newKeys, key)
+		}
+	}
+	if len(newKeys) > 0 {
+		k.keys = append(newKeys, k.keys...)
+	}
+}
+
+func (k *signingKeySet) getStatus() sessmodels.SigningKeySetStatus {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	status := sessmodels.SigningKeySetStatus{
+		KeyCount:         len(getUnexpiredKeys(k.keys)),
+		FetchedAt:        k.fetchedAt,
+		LastRefreshError: k.lastRefreshErr,
+	}
+	if !k.fetchedAt.IsZero() {
+		status.Age = time.Since(k.fetchedAt)
+		status.IsStale = time.Now().After(k.freshUntil)
+	}
+	return status
+}
+
+func getUnexpiredKeys(keys []sessmodels.KeyInfo) []sessmodels.KeyInfo {
+	result := []sessmodels.KeyInfo{}
+	now := getCurrTimeInMS()
+	for _, key := range keys {
+		if key.ExpiryTime > now {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+func fetchSigningKeysFromCore(querier supertokens.Querier, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+	response, err := querier.SendPostRequest("/recipe/handshake", nil, userContext)
+	if err != nil {
+		return signingKeysFetchResult{}, err
+	}
+	keys := getKeyInfoFromJson(response)
+	if len(keys) == 0 {
+		// means we are using an older CDI version
+		keys = []sessmodels.KeyInfo{
+			{
+				PublicKey:  response["jwtSigningPublicKey"].(string),
+				ExpiryTime: uint64(response["jwtSigningPublicKeyExpiryTime"].(float64)),
+				CreatedAt:  getCurrTimeInMS(),
+			},
+		}
+	}
+	return signingKeysFetchResult{keys: keys}, nil
+}
+
+type jsonWebKeySet struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+func fetchSigningKeysFromJWKSEndpoint(httpClient *http.Client, jwksEndpoint string, etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+	ctx, cancel := context.WithTimeout(supertokens.GetContextFromUserContext(userContext), jwksRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksEndpoint, nil)
+	if err != nil {
+		return signingKeysFetchResult{}, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return signingKeysFetchResult{}, err
+	}
+	defer resp.Body.Close()
+
+	maxAge := getMaxAgeFromCacheControl(resp.Header.Get("Cache-Control"))
+	if resp.StatusCode == http.StatusNotModified {
+		return signingKeysFetchResult{notModified: true, maxAge: maxAge}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return signingKeysFetchResult{}, errors.New("JWKS endpoint returned status code " + strconv.Itoa(resp.StatusCode))
+	}
+
+	var jwks jsonWebKeySet
+	err = json.NewDecoder(resp.Body).Decode(&jwks)
+	if err != nil {
+		return signingKeysFetchResult{}, err
+	}
+	keys := []sessmodels.KeyInfo{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		publicKey, err := getPublicKeyFromJWK(jwk.N, jwk.E)
+		if err != nil {
+			return signingKeysFetchResult{}, err
+		}
+		keys = append(keys, sessmodels.KeyInfo{
+			PublicKey: publicKey,
+			// the keys of a JWKS do not expire, they are removed from the set instead
+			ExpiryTime: math.MaxUint64,
+			CreatedAt:  0,
+		})
+	}
+	if len(keys) == 0 {
+		return signingKeysFetchResult{}, errors.New("JWKS endpoint did not return any RSA signing key")
+	}
+	return signingKeysFetchResult{keys: keys, etag: resp.Header.Get("ETag"), maxAge: maxAge}, nil
+}
+
+// getPublicKeyFromJWK returns the key in the format used by the core, which is a base64 encoded DER public key
+func getPublicKeyFromJWK(n string, e string) (string, error) {
+	nBytes, err := b64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return "", err
+	}
+	eBytes, err := b64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return "", err
+	}
+	publicKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return b64.StdEncoding.EncodeToString(der), nil
+}
+
+func getMaxAgeFromCacheControl(cacheControl string) *time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if strings.HasPrefix(directive, "max-age=") {
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil || seconds < 0 {
+				return nil
+			}
+			maxAge := time.Duration(seconds) * time.Second
+			return &maxAge
+		}
+	}
+	return nil
+}
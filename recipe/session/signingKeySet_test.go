@@ -0,0 +1,182 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func makeSigningKeySetForTest(refreshInterval time.Duration, maxStaleness time.Duration, fetch func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error)) *signingKeySet {
+	return &signingKeySet{
+		config: sessmodels.NetworklessVerificationNormalisedConfig{
+			RefreshInterval: refreshInterval,
+			MaxStaleness:    maxStaleness,
+		},
+		fetch: fetch,
+	}
+}
+
+func makeKeysForTest(publicKeys ...string) []sessmodels.KeyInfo {
+	keys := []sessmodels.KeyInfo{}
+	for _, publicKey := range publicKeys {
+		keys = append(keys, sessmodels.KeyInfo{PublicKey: publicKey, ExpiryTime: math.MaxUint64})
+	}
+	return keys
+}
+
+func TestSigningKeySetServesStaleKeysWhileRefreshingInBackground(t *testing.T) {
+	var calls int32
+	keySet := makeSigningKeySetForTest(time.Millisecond, time.Hour, func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return signingKeysFetchResult{keys: makeKeysForTest("key1")}, nil
+		}
+		return signingKeysFetchResult{}, errors.New("core is down")
+	})
+
+	keys, err := keySet.getKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, makeKeysForTest("key1"), keys)
+
+	time.Sleep(5 * time.Millisecond)
+	keys, err = keySet.getKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, makeKeysForTest("key1"), keys)
+
+	assert.Eventually(t, func() bool {
+		return keySet.getStatus().LastRefreshError != nil
+	}, time.Second, time.Millisecond)
+	status := keySet.getStatus()
+	assert.Equal(t, 1, status.KeyCount)
+	assert.True(t, status.IsStale)
+	assert.EqualError(t, status.LastRefreshError, "core is down")
+
+	keys, err = keySet.getKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, makeKeysForTest("key1"), keys)
+	// a failed refresh is not retried before signingKeysMinRefreshInterval
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestSigningKeySetFailsOnceKeysAreOlderThanMaxStaleness(t *testing.T) {
+	var calls int32
+	keySet := makeSigningKeySetForTest(time.Millisecond, 10*time.Millisecond, func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return signingKeysFetchResult{keys: makeKeysForTest("key1")}, nil
+		}
+		return signingKeysFetchResult{}, errors.New("core is down")
+	})
+
+	_, err := keySet.getKeys(nil)
+	assert.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+	_, err = keySet.getKeys(nil)
+	assert.EqualError(t, err, "core is down")
+}
+
+func TestSigningKeySetUsesETagAndKeepsKeysIfNotModified(t *testing.T) {
+	etags := []string{}
+	keySet := makeSigningKeySetForTest(time.Hour, time.Hour, func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+		etags = append(etags, etag)
+		if etag == "" {
+			return signingKeysFetchResult{keys: makeKeysForTest("key1"), etag: "v1"}, nil
+		}
+		return signingKeysFetchResult{notModified: true}, nil
+	})
+
+	assert.NoError(t, keySet.refresh(nil))
+	assert.NoError(t, keySet.refresh(nil))
+	assert.Equal(t, []string{"", "v1"}, etags)
+	keys, err := keySet.getKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, makeKeysForTest("key1"), keys)
+}
+
+func TestSigningKeySetAddsNewKeysAndSkipsExpiredKeys(t *testing.T) {
+	keySet := makeSigningKeySetForTest(time.Hour, time.Hour, func(etag string, userContext supertokens.UserContext) (signingKeysFetchResult, error) {
+		keys := makeKeysForTest("key1")
+		keys = append(keys, sessmodels.KeyInfo{PublicKey: "expiredKey", ExpiryTime: 1})
+		return signingKeysFetchResult{keys: keys}, nil
+	})
+	assert.NoError(t, keySet.refresh(nil))
+
+	keySet.addKeys(makeKeysForTest("key2", "key1"))
+	keys, err := keySet.getKeys(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, makeKeysForTest("key2", "key1"), keys)
+}
+
+func TestFetchSigningKeysFromJWKSEndpoint(t *testing.T) {
+	jwks := `{"keys":[{"kty":"RSA","use":"sig","n":"sXchDaQebHnPiGvyDOAT4saGEUetSyo9MKLOoWFsueri23bOdgWp4Dy1WlUzewbgBHod5pcM9H95GQRV3JDXboIRROSBigeC5yjU1hGzHHyXss8UDprecbAYxknTcQkhslANGRUZmdTOQ5qTRsLAt6BTYuyvVRdhS8exSZEy_c4gs_7svlJJQ4H9_NxsiIoLwAEk7-Q3UXERGYw_75IDrGA84-lA_-Ct4eTlXHBIY2EaV7t7LjJaynVJCpkv4LKjTTAumiGUIuQhrNhZLuF_RJLqHpM2kgWFLU7-VTdL1VbC2tejvcI2BlMkEpk1BzBZI0KQB0GaDWFLN-aEAw3vRw","e":"AQAB"},{"kty":"EC","crv":"P-256"}]}`
+	var conditionalRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Cache-Control", "public, max-age=600")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&conditionalRequests, 1)
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", `"v1"`)
+		rw.Write([]byte(jwks))
+	}))
+	defer server.Close()
+
+	result, err := fetchSigningKeysFromJWKSEndpoint(server.Client(), server.URL, "", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(result.keys))
+	_, err = getPublicKeyFromStr("-----BEGIN PUBLIC KEY-----\n" + result.keys[0].PublicKey + "\n-----END PUBLIC KEY-----")
+	assert.NoError(t, err)
+	assert.Equal(t, `"v1"`, result.etag)
+	assert.Equal(t, 10*time.Minute, *result.maxAge)
+
+	result, err = fetchSigningKeysFromJWKSEndpoint(server.Client(), server.URL, `"v1"`, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.notModified)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&conditionalRequests))
+}
+
+func TestNetworklessVerificationConfigValidation(t *testing.T) {
+	appInfo := supertokens.AppInfo{
+		AppName:       "SuperTokens",
+		APIDomain:     "api.supertokens.io",
+		WebsiteDomain: "supertokens.io",
+	}
+	normalisedAppInfo, err := supertokens.NormaliseInputAppInfoOrThrowError(appInfo)
+	assert.NoError(t, err)
+
+	config, err := validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{NetworklessVerification: &sessmodels.NetworklessVerificationConfig{}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultSigningKeysRefreshInterval, config.NetworklessVerification.RefreshInterval)
+	assert.Equal(t, defaultSigningKeysMaxStaleness, config.NetworklessVerification.MaxStaleness)
+
+	refreshInterval := time.Hour
+	maxStaleness := time.Minute
+	_, err = validateAndNormaliseUserInput(normalisedAppInfo, &sessmodels.TypeInput{NetworklessVerification: &sessmodels.NetworklessVerificationConfig{
+		RefreshInterval: &refreshInterval,
+		MaxStaleness:    &maxStaleness,
+	}})
+	assert.EqualError(t, err, "NetworklessVerification.MaxStaleness cannot be less than RefreshInterval")
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	sessErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessionwithjwt"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -70,6 +71,56 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		cookieSecure = *config.CookieSecure
 	}
 
+	olderCookieDomains := []string{}
+	if config != nil {
+		for _, olderCookieDomain := range config.OlderCookieDomains {
+			// an empty domain is kept, to clear the cookies that were set without a domain
+			if olderCookieDomain != "" {
+				normalisedDomain, err := normaliseSessionScopeOrThrowError(olderCookieDomain)
+				if err != nil {
+					return sessmodels.TypeNormalisedInput{}, err
+				}
+				olderCookieDomain = *normalisedDomain
+			}
+			if cookieDomain == nil && olderCookieDomain == "" || cookieDomain != nil && olderCookieDomain == *cookieDomain {
+				return sessmodels.TypeNormalisedInput{}, errors.New("OlderCookieDomains cannot include CookieDomain")
+			}
+			olderCookieDomains = append(olderCookieDomains, olderCookieDomain)
+		}
+	}
+
+	getCookieDomain := func(req *http.Request, userContext supertokens.UserContext) *string {
+		return cookieDomain
+	}
+	if config != nil && config.GetCookieDomain != nil {
+		getCookieDomain = func(req *http.Request, userContext supertokens.UserContext) *string {
+			domain := config.GetCookieDomain(req, userContext)
+			if domain == nil {
+				return nil
+			}
+			normalisedDomain, err := normaliseSessionScopeOrThrowError(*domain)
+			if err != nil {
+				supertokens.LogWarnMessage("GetCookieDomain returned an invalid domain, so CookieDomain is used instead: "+*domain, supertokens.ErrorLogField(err))
+				return cookieDomain
+			}
+			return normalisedDomain
+		}
+	}
+
+	getCookieSameSite := func(req *http.Request, userContext supertokens.UserContext) string {
+		return cookieSameSite
+	}
+	if config != nil && config.GetCookieSameSite != nil {
+		getCookieSameSite = func(req *http.Request, userContext supertokens.UserContext) string {
+			sameSite, err := normaliseSameSiteOrThrowError(config.GetCookieSameSite(req, userContext))
+			if err != nil {
+				supertokens.LogWarnMessage("GetCookieSameSite returned an invalid value, so CookieSameSite is used instead", supertokens.ErrorLogField(err))
+				return cookieSameSite
+			}
+			return sameSite
+		}
+	}
+
 	sessionExpiredStatusCode := 401
 	if config != nil && config.SessionExpiredStatusCode != nil {
 		sessionExpiredStatusCode = *config.SessionExpiredStatusCode
@@ -92,7 +143,8 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	antiCsrf := antiCSRF_NONE
 	if config == nil || config.AntiCsrf == nil {
-		if cookieSameSite == cookieSameSite_NONE {
+		// the SameSite attribute returned by GetCookieSameSite can be none for any request
+		if cookieSameSite == cookieSameSite_NONE || config != nil && config.GetCookieSameSite != nil {
 			antiCsrf = antiCSRF_VIA_CUSTOM_HEADER
 		} else {
 			antiCsrf = antiCSRF_NONE
@@ -130,6 +182,13 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 			}
 			return sendInvalidClaimResponse(*recipeInstance, validationErrors, req, res)
 		},
+		OnIdleTimeout: func(message string, req *http.Request, res http.ResponseWriter) error {
+			recipeInstance, err := getRecipeInstanceOrThrowError()
+			if err != nil {
+				return err
+			}
+			return sendIdleTimeoutResponse(*recipeInstance, message, req, res)
+		},
 	}
 
 	if config != nil && config.ErrorHandlers != nil {
@@ -142,6 +201,9 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		if config.ErrorHandlers.OnInvalidClaim != nil {
 			errorHandlers.OnInvalidClaim = config.ErrorHandlers.OnInvalidClaim
 		}
+		if config.ErrorHandlers.OnIdleTimeout != nil {
+			errorHandlers.OnIdleTimeout = config.ErrorHandlers.OnIdleTimeout
+		}
 	}
 
 	refreshAPIPath, err := supertokens.NewNormalisedURLPath(refreshAPIPath)
@@ -169,17 +231,210 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 		config.GetTokenTransferMethod = defaultGetTokenTransferMethod
 	}
 
+	var networklessVerification *sessmodels.NetworklessVerificationNormalisedConfig = nil
+	if config.NetworklessVerification != nil {
+		networklessVerification = &sessmodels.NetworklessVerificationNormalisedConfig{
+			JWKSEndpoint:    config.NetworklessVerification.JWKSEndpoint,
+			RefreshInterval: defaultSigningKeysRefreshInterval,
+			MaxStaleness:    defaultSigningKeysMaxStaleness,
+		}
+		if config.NetworklessVerification.RefreshInterval != nil {
+			if *config.NetworklessVerification.RefreshInterval <= 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("NetworklessVerification.RefreshInterval must be positive")
+			}
+			networklessVerification.RefreshInterval = *config.NetworklessVerification.RefreshInterval
+		}
+		if config.NetworklessVerification.MaxStaleness != nil {
+			networklessVerification.MaxStaleness = *config.NetworklessVerification.MaxStaleness
+		}
+		if networklessVerification.MaxStaleness < networklessVerification.RefreshInterval {
+			return sessmodels.TypeNormalisedInput{}, errors.New("NetworklessVerification.MaxStaleness cannot be less than RefreshInterval")
+		}
+	}
+
+	var idleTimeout *sessmodels.IdleTimeoutNormalisedConfig = nil
+	if config.IdleTimeout != nil {
+		if config.IdleTimeout.Timeout == nil && config.IdleTimeout.GetTimeout == nil {
+			return sessmodels.TypeNormalisedInput{}, errors.New("IdleTimeout.Timeout or IdleTimeout.GetTimeout must be set")
+		}
+		idleTimeout = &sessmodels.IdleTimeoutNormalisedConfig{
+			GetTimeout:             config.IdleTimeout.GetTimeout,
+			ActivityUpdateInterval: defaultIdleTimeoutActivityUpdateInterval,
+		}
+		if idleTimeout.GetTimeout == nil {
+			timeout := *config.IdleTimeout.Timeout
+			if timeout <= 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("IdleTimeout.Timeout must be positive")
+			}
+			idleTimeout.GetTimeout = func(userID string, accessTokenPayload map[string]interface{}, userContext supertokens.UserContext) time.Duration {
+				return timeout
+			}
+		}
+		if config.IdleTimeout.ActivityUpdateInterval != nil {
+			if *config.IdleTimeout.ActivityUpdateInterval <= 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("IdleTimeout.ActivityUpdateInterval must be positive")
+			}
+			idleTimeout.ActivityUpdateInterval = *config.IdleTimeout.ActivityUpdateInterval
+		}
+	}
+
+	var deviceInfo *sessmodels.DeviceInfoNormalisedConfig = nil
+	if config.DeviceInfo != nil {
+		deviceInfo = &sessmodels.DeviceInfoNormalisedConfig{
+			GetIPAddress:  defaultGetIPAddress,
+			GetDeviceName: defaultGetDeviceName,
+		}
+		if config.DeviceInfo.GetIPAddress != nil {
+			deviceInfo.GetIPAddress = config.DeviceInfo.GetIPAddress
+		}
+		if config.DeviceInfo.GetDeviceName != nil {
+			deviceInfo.GetDeviceName = config.DeviceInfo.GetDeviceName
+		}
+	}
+
+	var sessionLimit *sessmodels.SessionLimitConfig = nil
+	if config.SessionLimit != nil {
+		if config.SessionLimit.MaxSessions <= 0 {
+			return sessmodels.TypeNormalisedInput{}, errors.New("SessionLimit.MaxSessions must be positive")
+		}
+		sessionLimit = &sessmodels.SessionLimitConfig{
+			MaxSessions: config.SessionLimit.MaxSessions,
+			Policy:      config.SessionLimit.Policy,
+		}
+		if sessionLimit.Policy == "" {
+			sessionLimit.Policy = sessmodels.RejectNewSessionPolicy
+		} else if sessionLimit.Policy != sessmodels.RejectNewSessionPolicy && sessionLimit.Policy != sessmodels.EvictOldestSessionPolicy {
+			return sessmodels.TypeNormalisedInput{}, errors.New("SessionLimit.Policy must be either REJECT_NEW_SESSION or EVICT_OLDEST_SESSION")
+		}
+	}
+
+	var refreshCoalescing *sessmodels.RefreshCoalescingNormalisedConfig = nil
+	if config.RefreshCoalescing != nil {
+		refreshCoalescing = &sessmodels.RefreshCoalescingNormalisedConfig{
+			GracePeriod: defaultRefreshGracePeriod,
+		}
+		if config.RefreshCoalescing.GracePeriod != nil {
+			if *config.RefreshCoalescing.GracePeriod < 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("RefreshCoalescing.GracePeriod cannot be negative")
+			}
+			refreshCoalescing.GracePeriod = *config.RefreshCoalescing.GracePeriod
+		}
+	}
+
+	var fingerprint *sessmodels.FingerprintNormalisedConfig = nil
+	if config.Fingerprint != nil {
+		if !config.Fingerprint.BindIPSubnet && !config.Fingerprint.BindUserAgent && config.Fingerprint.HeaderName == nil {
+			return sessmodels.TypeNormalisedInput{}, errors.New("Fingerprint must bind at least one of the IP subnet, user agent or a header")
+		}
+		fingerprint = &sessmodels.FingerprintNormalisedConfig{
+			BindIPSubnet:           config.Fingerprint.BindIPSubnet,
+			IPv4SubnetPrefixLength: defaultFingerprintIPv4SubnetPrefixLength,
+			IPv6SubnetPrefixLength: defaultFingerprintIPv6SubnetPrefixLength,
+			GetIPAddress:           defaultGetIPAddress,
+			BindUserAgent:          config.Fingerprint.BindUserAgent,
+			HeaderName:             config.Fingerprint.HeaderName,
+			MismatchPolicy:         config.Fingerprint.MismatchPolicy,
+		}
+		if config.Fingerprint.IPv4SubnetPrefixLength != nil {
+			if *config.Fingerprint.IPv4SubnetPrefixLength < 0 || *config.Fingerprint.IPv4SubnetPrefixLength > 32 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("Fingerprint.IPv4SubnetPrefixLength must be between 0 and 32")
+			}
+			fingerprint.IPv4SubnetPrefixLength = *config.Fingerprint.IPv4SubnetPrefixLength
+		}
+		if config.Fingerprint.IPv6SubnetPrefixLength != nil {
+			if *config.Fingerprint.IPv6SubnetPrefixLength < 0 || *config.Fingerprint.IPv6SubnetPrefixLength > 128 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("Fingerprint.IPv6SubnetPrefixLength must be between 0 and 128")
+			}
+			fingerprint.IPv6SubnetPrefixLength = *config.Fingerprint.IPv6SubnetPrefixLength
+		}
+		if config.Fingerprint.GetIPAddress != nil {
+			fingerprint.GetIPAddress = config.Fingerprint.GetIPAddress
+		} else if deviceInfo != nil {
+			fingerprint.GetIPAddress = deviceInfo.GetIPAddress
+		}
+		if config.Fingerprint.HeaderName != nil && *config.Fingerprint.HeaderName == "" {
+			return sessmodels.TypeNormalisedInput{}, errors.New("Fingerprint.HeaderName cannot be empty")
+		}
+		switch fingerprint.MismatchPolicy {
+		case "":
+			fingerprint.MismatchPolicy = sessmodels.LogFingerprintMismatchPolicy
+		case sessmodels.IgnoreFingerprintMismatchPolicy, sessmodels.LogFingerprintMismatchPolicy, sessmodels.InvalidClaimFingerprintMismatchPolicy, sessmodels.RevokeFingerprintMismatchPolicy:
+		default:
+			return sessmodels.TypeNormalisedInput{}, errors.New("Fingerprint.MismatchPolicy must be one of IGNORE, LOG, INVALID_CLAIM or REVOKE")
+		}
+	}
+
+	var dpop *sessmodels.DPoPNormalisedConfig = nil
+	if config.DPoP != nil {
+		dpop = &sessmodels.DPoPNormalisedConfig{
+			Required:          config.DPoP.Required,
+			MaxProofAge:       defaultDPoPMaxProofAge,
+			AllowedAlgorithms: supportedDPoPAlgorithms,
+			GetRequestURL:     config.DPoP.GetRequestURL,
+			ReplayCache:       config.DPoP.ReplayCache,
+		}
+		if config.DPoP.MaxProofAge != nil {
+			if *config.DPoP.MaxProofAge <= 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("DPoP.MaxProofAge must be positive")
+			}
+			dpop.MaxProofAge = *config.DPoP.MaxProofAge
+		}
+		if config.DPoP.AllowedAlgorithms != nil {
+			if len(config.DPoP.AllowedAlgorithms) == 0 {
+				return sessmodels.TypeNormalisedInput{}, errors.New("DPoP.AllowedAlgorithms cannot be empty")
+			}
+			for _, algorithm := range config.DPoP.AllowedAlgorithms {
+				if !isSupportedDPoPAlgorithm(algorithm) {
+					return sessmodels.TypeNormalisedInput{}, errors.New("DPoP.AllowedAlgorithms must only contain " + strings.Join(supportedDPoPAlgorithms, ", ") + ", got: " + algorithm)
+				}
+			}
+			dpop.AllowedAlgorithms = config.DPoP.AllowedAlgorithms
+		}
+		if dpop.GetRequestURL == nil {
+			apiDomain := appInfo.APIDomain.GetAsStringDangerous()
+			dpop.GetRequestURL = func(req *http.Request, userContext supertokens.UserContext) string {
+				return apiDomain + req.URL.Path
+			}
+		}
+		if dpop.ReplayCache == nil {
+			dpop.ReplayCache = newMemoryDPoPReplayCache()
+		}
+	}
+
+	cookies := sessmodels.CookiesNormalisedConfig{
+		AccessTokenName:  accessTokenCookieKey,
+		RefreshTokenName: refreshTokenCookieKey,
+	}
+	if config.Cookies != nil {
+		cookies, err = normaliseCookiesConfig(*config.Cookies, cookieDomain != nil || config.GetCookieDomain != nil, cookieSecure)
+		if err != nil {
+			return sessmodels.TypeNormalisedInput{}, err
+		}
+	}
+
 	typeNormalisedInput := sessmodels.TypeNormalisedInput{
 		RefreshTokenPath:         appInfo.APIBasePath.AppendPath(refreshAPIPath),
 		CookieDomain:             cookieDomain,
 		CookieSameSite:           cookieSameSite,
 		CookieSecure:             cookieSecure,
+		OlderCookieDomains:       olderCookieDomains,
+		GetCookieDomain:          getCookieDomain,
+		GetCookieSameSite:        getCookieSameSite,
 		SessionExpiredStatusCode: sessionExpiredStatusCode,
 		InvalidClaimStatusCode:   invalidClaimStatusCode,
 		AntiCsrf:                 antiCsrf,
 		ErrorHandlers:            errorHandlers,
 		Jwt:                      Jwt,
 		GetTokenTransferMethod:   config.GetTokenTransferMethod,
+		NetworklessVerification:  networklessVerification,
+		IdleTimeout:              idleTimeout,
+		DeviceInfo:               deviceInfo,
+		SessionLimit:             sessionLimit,
+		Cookies:                  cookies,
+		RefreshCoalescing:        refreshCoalescing,
+		OnTokenTheftEvent:        config.OnTokenTheftEvent,
+		Fingerprint:              fingerprint,
+		DPoP:                     dpop,
 		Override: sessmodels.OverrideStruct{
 			Functions: func(originalImplementation sessmodels.RecipeInterface) sessmodels.RecipeInterface {
 				return originalImplementation
@@ -201,6 +456,79 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	return typeNormalisedInput, nil
 }
+
+func normaliseCookiesConfig(config sessmodels.CookiesConfig, hasCookieDomain bool, cookieSecure bool) (sessmodels.CookiesNormalisedConfig, error) {
+	getName := func(name *string, defaultName string, prefix sessmodels.CookieNamePrefix) (string, error) {
+		if name == nil {
+			name = &defaultName
+		}
+		if !isValidCookieName(*name) {
+			return "", errors.New("invalid cookie name: " + *name)
+		}
+		if prefix != "" {
+			if prefix != sessmodels.HostCookieNamePrefix && prefix != sessmodels.SecureCookieNamePrefix {
+				return "", errors.New("cookie name prefix must be either __Host- or __Secure-")
+			}
+			if !cookieSecure {
+				return "", errors.New("cookie name prefixes require CookieSecure to be true")
+			}
+			if prefix == sessmodels.HostCookieNamePrefix && hasCookieDomain {
+				return "", errors.New("the __Host- cookie name prefix cannot be used with CookieDomain or GetCookieDomain")
+			}
+		}
+		return string(prefix) + *name, nil
+	}
+
+	if config.RefreshTokenNamePrefix == sessmodels.HostCookieNamePrefix {
+		return sessmodels.CookiesNormalisedConfig{}, errors.New("the __Host- cookie name prefix cannot be used for the refresh token, since its path is not /. Please use __Secure- instead")
+	}
+	accessTokenName, err := getName(config.AccessTokenName, accessTokenCookieKey, config.AccessTokenNamePrefix)
+	if err != nil {
+		return sessmodels.CookiesNormalisedConfig{}, err
+	}
+	refreshTokenName, err := getName(config.RefreshTokenName, refreshTokenCookieKey, config.RefreshTokenNamePrefix)
+	if err != nil {
+		return sessmodels.CookiesNormalisedConfig{}, err
+	}
+	if accessTokenName == refreshTokenName {
+		return sessmodels.CookiesNormalisedConfig{}, errors.New("the access and refresh token cookies cannot have the same name")
+	}
+
+	if config.Partitioned && !cookieSecure {
+		return sessmodels.CookiesNormalisedConfig{}, errors.New("partitioned cookies require CookieSecure to be true")
+	}
+
+	for _, oldName := range append(append([]string{}, config.OldAccessTokenNames...), config.OldRefreshTokenNames...) {
+		if oldName == accessTokenName || oldName == refreshTokenName {
+			return sessmodels.CookiesNormalisedConfig{}, errors.New("the old cookie names cannot include the new ones, got: " + oldName)
+		}
+		if !isValidCookieName(oldName) {
+			return sessmodels.CookiesNormalisedConfig{}, errors.New("invalid cookie name: " + oldName)
+		}
+	}
+
+	return sessmodels.CookiesNormalisedConfig{
+		AccessTokenName:      accessTokenName,
+		RefreshTokenName:     refreshTokenName,
+		Partitioned:          config.Partitioned,
+		OldAccessTokenNames:  config.OldAccessTokenNames,
+		OldRefreshTokenNames: config.OldRefreshTokenNames,
+	}, nil
+}
+
+// isValidCookieName checks that name is a token, as defined by RFC 6265
+func isValidCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, char := range name {
+		if char <= ' ' || char >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, char) {
+			return false
+		}
+	}
+	return true
+}
+
 func normaliseSameSiteOrThrowError(sameSite string) (string, error) {
 	sameSite = strings.TrimSpace(sameSite)
 	sameSite = strings.ToLower(sameSite)
@@ -255,12 +583,13 @@ func getCurrTimeInMS() uint64 {
 	return uint64(time.Now().UnixNano() / 1000000)
 }
 
-func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedInput, res http.ResponseWriter, response sessmodels.CreateOrRefreshAPIResponse, tokenTransferMethod sessmodels.TokenTransferMethod) {
+func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedInput, req *http.Request, res http.ResponseWriter, response sessmodels.CreateOrRefreshAPIResponse, tokenTransferMethod sessmodels.TokenTransferMethod, userContext supertokens.UserContext) {
 	accessToken := response.AccessToken
 	refreshToken := response.RefreshToken
 	setFrontTokenInHeaders(res, response.Session.UserID, response.AccessToken.Expiry, response.Session.UserDataInAccessToken)
 	setToken(
 		config,
+		req,
 		res,
 		sessmodels.AccessToken,
 		accessToken.Token,
@@ -270,8 +599,9 @@ func attachCreateOrRefreshSessionResponseToRes(config sessmodels.TypeNormalisedI
 		// Setting them to infinity would require special case handling on the frontend and just adding 10 years seems enough.
 		getCurrTimeInMS()+3153600000000,
 		tokenTransferMethod,
+		userContext,
 	)
-	setToken(config, res, sessmodels.RefreshToken, refreshToken.Token, refreshToken.Expiry, tokenTransferMethod)
+	setToken(config, req, res, sessmodels.RefreshToken, refreshToken.Token, refreshToken.Expiry, tokenTransferMethod, userContext)
 
 	if response.AntiCsrfToken != nil {
 		setAntiCsrfTokenInHeaders(res, *response.AntiCsrfToken)
@@ -286,6 +616,13 @@ func sendUnauthorisedResponse(recipeInstance Recipe, _ string, _ *http.Request,
 	return supertokens.SendNon200ResponseWithMessage(response, "unauthorised", recipeInstance.Config.SessionExpiredStatusCode)
 }
 
+func sendIdleTimeoutResponse(recipeInstance Recipe, _ string, _ *http.Request, response http.ResponseWriter) error {
+	return supertokens.SendNon200Response(response, recipeInstance.Config.SessionExpiredStatusCode, map[string]interface{}{
+		"message": "unauthorised",
+		"reason":  sessErrors.IdleTimeoutReason,
+	})
+}
+
 func sendInvalidClaimResponse(recipeInstance Recipe, claimValidationErrors []claims.ClaimValidationError, _ *http.Request, response http.ResponseWriter) error {
 	return supertokens.SendNon200Response(response, recipeInstance.Config.InvalidClaimStatusCode, map[string]interface{}{
 		"message":               "invalid claim",
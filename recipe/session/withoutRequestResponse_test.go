@@ -0,0 +1,97 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package session
+
+import (
+	"encoding/base64"
+	defaultErrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session/errors"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+)
+
+func TestSessionWithoutRequestResponseAgainstFakeCore(t *testing.T) {
+	startFakeCoreForTest(t, nil)
+
+	newSession, err := CreateNewSessionWithoutRequestResponse("userId", map[string]interface{}{"a": "b"}, nil, true)
+	assert.NoError(t, err)
+	tokens := newSession.GetAllSessionTokensDangerously()
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotNil(t, tokens.RefreshToken)
+	assert.Nil(t, tokens.AntiCsrfToken)
+	assert.True(t, tokens.AccessAndFrontTokenUpdated)
+	frontToken, err := base64.StdEncoding.DecodeString(tokens.FrontToken)
+	assert.NoError(t, err)
+	assert.Contains(t, string(frontToken), `"uid":"userId"`)
+
+	verifiedSession, err := GetSessionWithoutRequestResponse(tokens.AccessToken, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", verifiedSession.GetUserID())
+	assert.Equal(t, "b", verifiedSession.GetAccessTokenPayload()["a"])
+	assert.False(t, verifiedSession.GetAllSessionTokensDangerously().AccessAndFrontTokenUpdated)
+	assert.Nil(t, verifiedSession.GetAllSessionTokensDangerously().RefreshToken)
+
+	err = verifiedSession.MergeIntoAccessTokenPayload(map[string]interface{}{"c": "d"})
+	assert.NoError(t, err)
+	updatedTokens := verifiedSession.GetAllSessionTokensDangerously()
+	assert.True(t, updatedTokens.AccessAndFrontTokenUpdated)
+	assert.NotEqual(t, tokens.AccessToken, updatedTokens.AccessToken)
+	verifiedSession, err = GetSessionWithoutRequestResponse(updatedTokens.AccessToken, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "d", verifiedSession.GetAccessTokenPayload()["c"])
+
+	_, err = GetSessionWithoutRequestResponse("invalid", nil, nil)
+	assert.True(t, defaultErrors.As(err, &errors.UnauthorizedError{}))
+	False := false
+	verifiedSession, err = GetSessionWithoutRequestResponse("invalid", nil, &sessmodels.VerifySessionOptions{SessionRequired: &False})
+	assert.NoError(t, err)
+	assert.Nil(t, verifiedSession)
+
+	refreshedSession, err := RefreshSessionWithoutRequestResponse(*tokens.RefreshToken, true, nil)
+	assert.NoError(t, err)
+	refreshedTokens := refreshedSession.GetAllSessionTokensDangerously()
+	assert.True(t, refreshedTokens.AccessAndFrontTokenUpdated)
+	assert.NotEqual(t, *tokens.RefreshToken, *refreshedTokens.RefreshToken)
+
+	err = refreshedSession.RevokeSession()
+	assert.NoError(t, err)
+	_, err = RefreshSessionWithoutRequestResponse(*refreshedTokens.RefreshToken, true, nil)
+	assert.True(t, defaultErrors.As(err, &errors.UnauthorizedError{}))
+}
+
+func TestSessionWithoutRequestResponseChecksAntiCsrfTokenAgainstFakeCore(t *testing.T) {
+	antiCsrf := "VIA_TOKEN"
+	startFakeCoreForTest(t, &sessmodels.TypeInput{AntiCsrf: &antiCsrf})
+
+	newSession, err := CreateNewSessionWithoutRequestResponse("userId", nil, nil, false)
+	assert.NoError(t, err)
+	tokens := newSession.GetAllSessionTokensDangerously()
+	assert.NotNil(t, tokens.AntiCsrfToken)
+
+	True := true
+	wrongAntiCsrfToken := "wrong"
+	_, err = GetSessionWithoutRequestResponse(tokens.AccessToken, &wrongAntiCsrfToken, &sessmodels.VerifySessionOptions{AntiCsrfCheck: &True})
+	assert.True(t, defaultErrors.As(err, &errors.TryRefreshTokenError{}))
+	verifiedSession, err := GetSessionWithoutRequestResponse(tokens.AccessToken, tokens.AntiCsrfToken, &sessmodels.VerifySessionOptions{AntiCsrfCheck: &True})
+	assert.NoError(t, err)
+	assert.Equal(t, "userId", verifiedSession.GetUserID())
+
+	refreshedSession, err := RefreshSessionWithoutRequestResponse(*tokens.RefreshToken, false, tokens.AntiCsrfToken)
+	assert.NoError(t, err)
+	assert.NotNil(t, refreshedSession.GetAllSessionTokensDangerously().AntiCsrfToken)
+}
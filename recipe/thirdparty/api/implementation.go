@@ -28,6 +28,8 @@ import (
 	"github.com/derekstavis/go-qs"
 	"github.com/supertokens/supertokens-golang/recipe/emailverification"
 	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	sessErrors "github.com/supertokens/supertokens-golang/recipe/session/errors"
 	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
 	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
@@ -94,45 +96,7 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 	}
 
 	signInUpPOST := func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.SignInUpPOSTResponse, error) {
-		{
-			providerInfo := provider.Get(nil, nil, userContext)
-			if isUsingDevelopmentClientId(providerInfo.GetClientId(userContext)) {
-				redirectURI = DevOauthRedirectUrl
-			} else if providerInfo.GetRedirectURI != nil {
-				// we overwrite the redirectURI provided by the frontend
-				// since the backend wants to take charge of setting this.
-				rU, err := providerInfo.GetRedirectURI(userContext)
-				if err != nil {
-					return tpmodels.SignInUpPOSTResponse{}, err
-				}
-				redirectURI = rU
-			}
-		}
-
-		providerInfo := provider.Get(&redirectURI, &code, userContext)
-
-		var accessTokenAPIResponse map[string]interface{} = nil
-
-		if authCodeResponse != nil && len(authCodeResponse.(map[string]interface{})) != 0 {
-			accessTokenAPIResponse = authCodeResponse.(map[string]interface{})
-		} else {
-			if isUsingDevelopmentClientId(providerInfo.GetClientId(userContext)) {
-
-				for key, value := range providerInfo.AccessTokenAPI.Params {
-					if value == providerInfo.GetClientId(userContext) {
-						providerInfo.AccessTokenAPI.Params[key] = GetActualClientIdFromDevelopmentClientId(providerInfo.GetClientId(userContext))
-					}
-				}
-			}
-
-			accessTokenAPIResponseTemp, err := postRequest(providerInfo, userContext)
-			if err != nil {
-				return tpmodels.SignInUpPOSTResponse{}, err
-			}
-			accessTokenAPIResponse = accessTokenAPIResponseTemp
-		}
-
-		userInfo, err := providerInfo.GetProfileInfo(accessTokenAPIResponse, userContext)
+		userInfo, accessTokenAPIResponse, err := getUserInfoFromCode(provider, code, authCodeResponse, redirectURI, userContext)
 		if err != nil {
 			return tpmodels.SignInUpPOSTResponse{}, err
 		}
@@ -167,6 +131,11 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 
 		session, err := session.CreateNewSessionWithContext(options.Req, options.Res, response.OK.User.ID, nil, nil, userContext)
 		if err != nil {
+			if errors.As(err, &sessErrors.SessionLimitReachedError{}) {
+				return tpmodels.SignInUpPOSTResponse{
+					SessionLimitReachedError: &struct{}{},
+				}, nil
+			}
 			return tpmodels.SignInUpPOSTResponse{}, err
 		}
 		return tpmodels.SignInUpPOSTResponse{
@@ -195,11 +164,96 @@ func MakeAPIImplementation() tpmodels.APIInterface {
 		return nil
 	}
 
+	reauthenticatePOST := func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, sessionContainer sessmodels.SessionContainer, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.ReauthenticatePOSTResponse, error) {
+		userInfo, accessTokenAPIResponse, err := getUserInfoFromCode(provider, code, authCodeResponse, redirectURI, userContext)
+		if err != nil {
+			return tpmodels.ReauthenticatePOSTResponse{}, err
+		}
+
+		// the user is looked up instead of being signed in, since signing in
+		// with another account of the provider would sign up a new user
+		user, err := (*options.RecipeImplementation.GetUserByThirdPartyInfo)(provider.ID, userInfo.ID, userContext)
+		if err != nil {
+			return tpmodels.ReauthenticatePOSTResponse{}, err
+		}
+		if user == nil || user.ID != sessionContainer.GetUserIDWithContext(userContext) {
+			return tpmodels.ReauthenticatePOSTResponse{
+				UserMismatchError: &struct{}{},
+			}, nil
+		}
+
+		err = sessionContainer.FetchAndSetClaimWithContext(claims.AuthFreshnessClaim, userContext)
+		if err != nil {
+			return tpmodels.ReauthenticatePOSTResponse{}, err
+		}
+
+		return tpmodels.ReauthenticatePOSTResponse{
+			OK: &struct {
+				User             tpmodels.User
+				Session          sessmodels.SessionContainer
+				AuthCodeResponse interface{}
+			}{
+				User:             *user,
+				Session:          sessionContainer,
+				AuthCodeResponse: accessTokenAPIResponse,
+			},
+		}, nil
+	}
+
 	return tpmodels.APIInterface{
 		AuthorisationUrlGET:      &authorisationUrlGET,
 		SignInUpPOST:             &signInUpPOST,
 		AppleRedirectHandlerPOST: &appleRedirectHandlerPOST,
+		ReauthenticatePOST:       &reauthenticatePOST,
+	}
+}
+
+// getUserInfoFromCode exchanges the code given by the provider for an access token if needed, and uses
+// it to get the info of the user from the provider. It also returns the response of the provider.
+func getUserInfoFromCode(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, userContext supertokens.UserContext) (tpmodels.UserInfo, map[string]interface{}, error) {
+	{
+		providerInfo := provider.Get(nil, nil, userContext)
+		if isUsingDevelopmentClientId(providerInfo.GetClientId(userContext)) {
+			redirectURI = DevOauthRedirectUrl
+		} else if providerInfo.GetRedirectURI != nil {
+			// we overwrite the redirectURI provided by the frontend
+			// since the backend wants to take charge of setting this.
+			rU, err := providerInfo.GetRedirectURI(userContext)
+			if err != nil {
+				return tpmodels.UserInfo{}, nil, err
+			}
+			redirectURI = rU
+		}
+	}
+
+	providerInfo := provider.Get(&redirectURI, &code, userContext)
+
+	var accessTokenAPIResponse map[string]interface{} = nil
+
+	if authCodeResponse != nil && len(authCodeResponse.(map[string]interface{})) != 0 {
+		accessTokenAPIResponse = authCodeResponse.(map[string]interface{})
+	} else {
+		if isUsingDevelopmentClientId(providerInfo.GetClientId(userContext)) {
+
+			for key, value := range providerInfo.AccessTokenAPI.Params {
+				if value == providerInfo.GetClientId(userContext) {
+					providerInfo.AccessTokenAPI.Params[key] = GetActualClientIdFromDevelopmentClientId(providerInfo.GetClientId(userContext))
+				}
+			}
+		}
+
+		accessTokenAPIResponseTemp, err := postRequest(providerInfo, userContext)
+		if err != nil {
+			return tpmodels.UserInfo{}, nil, err
+		}
+		accessTokenAPIResponse = accessTokenAPIResponseTemp
+	}
+
+	userInfo, err := providerInfo.GetProfileInfo(accessTokenAPIResponse, userContext)
+	if err != nil {
+		return tpmodels.UserInfo{}, nil, err
 	}
+	return userInfo, accessTokenAPIResponse, nil
 }
 
 func postRequest(providerInfo tpmodels.TypeProviderGetResponse, userContext supertokens.UserContext) (map[string]interface{}, error) {
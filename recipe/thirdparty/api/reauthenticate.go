@@ -0,0 +1,71 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package api
+
+import (
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/thirdparty/tpmodels"
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+func ReauthenticateAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOptions) error {
+	if apiImplementation.ReauthenticatePOST == nil || (*apiImplementation.ReauthenticatePOST) == nil {
+		options.OtherHandler(options.Res, options.Req)
+		return nil
+	}
+
+	userContext := supertokens.MakeDefaultUserContextFromAPI(options.Req)
+
+	// the global claim validators are not checked, since the user may be re-authenticating to satisfy one of them
+	sessionContainer, err := session.GetSessionWithContext(
+		options.Req, options.Res,
+		&sessmodels.VerifySessionOptions{
+			OverrideGlobalClaimValidators: func(globalClaimValidators []claims.SessionClaimValidator, sessionContainer sessmodels.SessionContainer, userContext supertokens.UserContext) ([]claims.SessionClaimValidator, error) {
+				return []claims.SessionClaimValidator{}, nil
+			},
+		},
+		userContext,
+	)
+	if err != nil {
+		return err
+	}
+
+	provider, bodyParams, err := getSignInUpInput(options)
+	if err != nil {
+		return err
+	}
+
+	result, err := (*apiImplementation.ReauthenticatePOST)(*provider, bodyParams.Code, bodyParams.AuthCodeResponse, bodyParams.RedirectURI, sessionContainer, options, userContext)
+	if err != nil {
+		return err
+	}
+
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "OK",
+			"user":   result.OK.User,
+		})
+	} else if result.UserMismatchError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "USER_MISMATCH_ERROR",
+		})
+	} else if result.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*result.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
@@ -36,14 +36,47 @@ func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOp
 		return nil
 	}
 
-	body, err := supertokens.ReadFromRequest(options.Req)
+	provider, bodyParams, err := getSignInUpInput(options)
+	if err != nil {
+		return err
+	}
+
+	result, err := (*apiImplementation.SignInUpPOST)(*provider, bodyParams.Code, bodyParams.AuthCodeResponse, bodyParams.RedirectURI, options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
+
 	if err != nil {
 		return err
 	}
+
+	if result.OK != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status":         "OK",
+			"user":           result.OK.User,
+			"createdNewUser": result.OK.CreatedNewUser,
+		})
+	} else if result.NoEmailGivenByProviderError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "NO_EMAIL_GIVEN_BY_PROVIDER",
+		})
+	} else if result.SessionLimitReachedError != nil {
+		return supertokens.Send200Response(options.Res, map[string]interface{}{
+			"status": "SESSION_LIMIT_REACHED_ERROR",
+		})
+	} else if result.GeneralError != nil {
+		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*result.GeneralError))
+	}
+	return supertokens.ErrorIfNoResponse(options.Res)
+}
+
+// getSignInUpInput reads the code given by the provider from the body of the request, and finds the provider
+func getSignInUpInput(options tpmodels.APIOptions) (*tpmodels.TypeProvider, bodyParams, error) {
+	body, err := supertokens.ReadFromRequest(options.Req)
+	if err != nil {
+		return nil, bodyParams{}, err
+	}
 	var bodyParams bodyParams
 	err = json.Unmarshal(body, &bodyParams)
 	if err != nil {
-		return err
+		return nil, bodyParams, err
 	}
 
 	var clientId *string = nil
@@ -52,49 +85,30 @@ func SignInUpAPI(apiImplementation tpmodels.APIInterface, options tpmodels.APIOp
 	}
 
 	if bodyParams.ThirdPartyId == "" {
-		return supertokens.BadInputError{Msg: "Please provide the thirdPartyId in request body"}
+		return nil, bodyParams, supertokens.BadInputError{Msg: "Please provide the thirdPartyId in request body"}
 	}
 
 	if bodyParams.Code == "" && bodyParams.AuthCodeResponse == nil {
-		return supertokens.BadInputError{Msg: "Please provide one of code or authCodeResponse in the request body"}
+		return nil, bodyParams, supertokens.BadInputError{Msg: "Please provide one of code or authCodeResponse in the request body"}
 	}
 
 	if bodyParams.AuthCodeResponse != nil && bodyParams.AuthCodeResponse["access_token"] == nil {
-		return supertokens.BadInputError{Msg: "Please provide the access_token inside the authCodeResponse request param"}
+		return nil, bodyParams, supertokens.BadInputError{Msg: "Please provide the access_token inside the authCodeResponse request param"}
 	}
 
 	if bodyParams.RedirectURI == "" {
-		return supertokens.BadInputError{Msg: "Please provide the redirectURI in request body"}
+		return nil, bodyParams, supertokens.BadInputError{Msg: "Please provide the redirectURI in request body"}
 	}
 
 	var provider *tpmodels.TypeProvider = findRightProvider(options.Providers, bodyParams.ThirdPartyId, clientId)
 
 	if provider == nil {
 		if clientId == nil {
-			return supertokens.BadInputError{Msg: "The third party provider " + bodyParams.ThirdPartyId + " seems to be missing from the backend configs."}
+			return nil, bodyParams, supertokens.BadInputError{Msg: "The third party provider " + bodyParams.ThirdPartyId + " seems to be missing from the backend configs."}
 		} else {
-			return supertokens.BadInputError{Msg: "The third party provider " + bodyParams.ThirdPartyId + " seems to be missing from the backend configs. If it is configured, then please make sure that you are passing the correct clientId from the frontend."}
+			return nil, bodyParams, supertokens.BadInputError{Msg: "The third party provider " + bodyParams.ThirdPartyId + " seems to be missing from the backend configs. If it is configured, then please make sure that you are passing the correct clientId from the frontend."}
 		}
 	}
 
-	result, err := (*apiImplementation.SignInUpPOST)(*provider, bodyParams.Code, bodyParams.AuthCodeResponse, bodyParams.RedirectURI, options, supertokens.MakeDefaultUserContextFromAPI(options.Req))
-
-	if err != nil {
-		return err
-	}
-
-	if result.OK != nil {
-		return supertokens.Send200Response(options.Res, map[string]interface{}{
-			"status":         "OK",
-			"user":           result.OK.User,
-			"createdNewUser": result.OK.CreatedNewUser,
-		})
-	} else if result.NoEmailGivenByProviderError != nil {
-		return supertokens.Send200Response(options.Res, map[string]interface{}{
-			"status": "NO_EMAIL_GIVEN_BY_PROVIDER",
-		})
-	} else if result.GeneralError != nil {
-		return supertokens.Send200Response(options.Res, supertokens.ConvertGeneralErrorToJsonResponse(*result.GeneralError))
-	}
-	return supertokens.ErrorIfNoResponse(options.Res)
+	return provider, bodyParams, nil
 }
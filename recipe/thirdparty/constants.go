@@ -19,4 +19,5 @@ const (
 	AuthorisationAPI        = "/authorisationurl"
 	SignInUpAPI             = "/signinup"
 	AppleRedirectHandlerAPI = "/callback/apple"
+	ReauthenticateAPI       = "/signinup/reauthenticate"
 )
@@ -104,6 +104,10 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 	if err != nil {
 		return nil, err
 	}
+	reauthenticateAPI, err := supertokens.NewNormalisedURLPath(ReauthenticateAPI)
+	if err != nil {
+		return nil, err
+	}
 	return append([]supertokens.APIHandled{{
 		Method:                 http.MethodPost,
 		PathWithoutAPIBasePath: signInUpAPI,
@@ -119,6 +123,11 @@ func (r *Recipe) getAPIsHandled() ([]supertokens.APIHandled, error) {
 		PathWithoutAPIBasePath: appleRedirectHandlerAPI,
 		ID:                     AppleRedirectHandlerAPI,
 		Disabled:               r.APIImpl.AppleRedirectHandlerPOST == nil,
+	}, {
+		Method:                 http.MethodPost,
+		PathWithoutAPIBasePath: reauthenticateAPI,
+		ID:                     ReauthenticateAPI,
+		Disabled:               r.APIImpl.ReauthenticatePOST == nil,
 	}}), nil
 }
 
@@ -139,6 +148,8 @@ func (r *Recipe) handleAPIRequest(id string, req *http.Request, res http.Respons
 		return api.AuthorisationUrlAPI(r.APIImpl, options)
 	} else if id == AppleRedirectHandlerAPI {
 		return api.AppleRedirectHandler(r.APIImpl, options)
+	} else if id == ReauthenticateAPI {
+		return api.ReauthenticateAPI(r.APIImpl, options)
 	}
 	return errors.New("should never come here")
 }
@@ -26,7 +26,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 			"thirdPartyId":     thirdPartyID,
 			"thirdPartyUserId": thirdPartyUserID,
 			"email":            map[string]interface{}{"id": email},
-		})
+		}, userContext)
 		if err != nil {
 			return tpmodels.SignInUpResponse{}, err
 		}
@@ -34,12 +34,14 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 		if err != nil {
 			return tpmodels.SignInUpResponse{}, err
 		}
+		createdNewUser := response["createdNewUser"].(bool)
+		incrementSignInUpCounter(createdNewUser, userContext)
 		return tpmodels.SignInUpResponse{
 			OK: &struct {
 				CreatedNewUser bool
 				User           tpmodels.User
 			}{
-				CreatedNewUser: response["createdNewUser"].(bool),
+				CreatedNewUser: createdNewUser,
 				User:           *user,
 			},
 		}, nil
@@ -48,7 +50,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 	getUserByID := func(userID string, userContext supertokens.UserContext) (*tpmodels.User, error) {
 		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
 			"userId": userID,
-		})
+		}, userContext)
 		if err != nil {
 			return nil, err
 		}
@@ -66,7 +68,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 		response, err := querier.SendGetRequest("/recipe/user", map[string]string{
 			"thirdPartyId":     thirdPartyID,
 			"thirdPartyUserId": thirdPartyUserID,
-		})
+		}, userContext)
 		if err != nil {
 			return nil, err
 		}
@@ -83,7 +85,7 @@ func MakeRecipeImplementation(querier supertokens.Querier) tpmodels.RecipeInterf
 	getUsersByEmail := func(email string, userContext supertokens.UserContext) ([]tpmodels.User, error) {
 		response, err := querier.SendGetRequest("/recipe/users/by-email", map[string]string{
 			"email": email,
-		})
+		}, userContext)
 		if err != nil {
 			return []tpmodels.User{}, err
 		}
@@ -27,6 +27,9 @@ type APIInterface struct {
 	AuthorisationUrlGET      *func(provider TypeProvider, options APIOptions, userContext supertokens.UserContext) (AuthorisationUrlGETResponse, error)
 	SignInUpPOST             *func(provider TypeProvider, code string, authCodeResponse interface{}, redirectURI string, options APIOptions, userContext supertokens.UserContext) (SignInUpPOSTResponse, error)
 	AppleRedirectHandlerPOST *func(code string, state string, options APIOptions, userContext supertokens.UserContext) error
+	// ReauthenticatePOST checks that the code given by the provider is for the user of the current session, and
+	// updates the time of their last authentication in the session instead of creating a new one
+	ReauthenticatePOST *func(provider TypeProvider, code string, authCodeResponse interface{}, redirectURI string, sessionContainer sessmodels.SessionContainer, options APIOptions, userContext supertokens.UserContext) (ReauthenticatePOSTResponse, error)
 }
 
 type AuthorisationUrlGETResponse struct {
@@ -42,7 +45,20 @@ type SignInUpPOSTResponse struct {
 		AuthCodeResponse interface{}
 	}
 	NoEmailGivenByProviderError *struct{}
-	GeneralError                *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
+}
+
+type ReauthenticatePOSTResponse struct {
+	OK *struct {
+		User             User
+		Session          sessmodels.SessionContainer
+		AuthCodeResponse interface{}
+	}
+	// UserMismatchError is returned if the code is for another user than the user of the session
+	UserMismatchError *struct{}
+	GeneralError      *supertokens.GeneralErrorResponse
 }
 
 type APIOptions struct {
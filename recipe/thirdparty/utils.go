@@ -124,3 +124,11 @@ func parseUsers(value interface{}) ([]tpmodels.User, error) {
 	}
 	return user, nil
 }
+
+func incrementSignInUpCounter(createdNewUser bool, userContext supertokens.UserContext) {
+	if createdNewUser {
+		supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameSignUps, supertokens.RecipeIDLogField(RECIPE_ID))
+	} else {
+		supertokens.IncrementCounterWithUserContext(userContext, supertokens.CounterNameSignIns, supertokens.RecipeIDLogField(RECIPE_ID))
+	}
+}
@@ -30,6 +30,7 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 		PasswordResetPOST:              apiImplmentation.PasswordResetPOST,
 		SignInPOST:                     nil,
 		SignUpPOST:                     nil,
+		ReauthenticatePOST:             apiImplmentation.EmailPasswordReauthenticatePOST,
 	}
 
 	if apiImplmentation.EmailPasswordSignInPOST != nil && (*apiImplmentation.EmailPasswordSignInPOST) != nil {
@@ -57,6 +58,10 @@ func GetEmailPasswordIterfaceImpl(apiImplmentation tpepmodels.APIInterface) epmo
 				return epmodels.SignInPOSTResponse{
 					WrongCredentialsError: &struct{}{},
 				}, nil
+			} else if result.SessionLimitReachedError != nil {
+				return epmodels.SignInPOSTResponse{
+					SessionLimitReachedError: &struct{}{},
+				}, nil
 			} else {
 				return epmodels.SignInPOSTResponse{
 					GeneralError: result.GeneralError,
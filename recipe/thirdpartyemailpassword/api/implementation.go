@@ -70,6 +70,10 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 			return tpepmodels.SignInPOSTResponse{
 				WrongCredentialsError: &struct{}{},
 			}, nil
+		} else if response.SessionLimitReachedError != nil {
+			return tpepmodels.SignInPOSTResponse{
+				SessionLimitReachedError: &struct{}{},
+			}, nil
 		} else {
 			return tpepmodels.SignInPOSTResponse{
 				GeneralError: response.GeneralError,
@@ -123,6 +127,10 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 			return tpepmodels.ThirdPartyOutput{
 				NoEmailGivenByProviderError: &struct{}{},
 			}, nil
+		} else if response.SessionLimitReachedError != nil {
+			return tpepmodels.ThirdPartyOutput{
+				SessionLimitReachedError: &struct{}{},
+			}, nil
 		} else {
 			return tpepmodels.ThirdPartyOutput{
 				OK: &struct {
@@ -154,15 +162,28 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 	appleRedirectHandlerPOST := func(code string, state string, options tpmodels.APIOptions, userContext supertokens.UserContext) error {
 		return ogAppleRedirectHandlerPOST(code, state, options, userContext)
 	}
+
+	ogEmailPasswordReauthenticatePOST := *emailPasswordImplementation.ReauthenticatePOST
+	emailPasswordReauthenticatePOST := func(formFields []epmodels.TypeFormField, sessionContainer sessmodels.SessionContainer, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.ReauthenticatePOSTResponse, error) {
+		return ogEmailPasswordReauthenticatePOST(formFields, sessionContainer, options, userContext)
+	}
+
+	ogThirdPartyReauthenticatePOST := *thirdPartyImplementation.ReauthenticatePOST
+	thirdPartyReauthenticatePOST := func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, sessionContainer sessmodels.SessionContainer, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.ReauthenticatePOSTResponse, error) {
+		return ogThirdPartyReauthenticatePOST(provider, code, authCodeResponse, redirectURI, sessionContainer, options, userContext)
+	}
+
 	result := tpepmodels.APIInterface{
-		AuthorisationUrlGET:            &authorisationUrlGET,
-		EmailPasswordEmailExistsGET:    &emailExistsGET,
-		GeneratePasswordResetTokenPOST: &generatePasswordResetTokenPOST,
-		PasswordResetPOST:              &passwordResetPOST,
-		ThirdPartySignInUpPOST:         &thirdPartySignInUpPOST,
-		EmailPasswordSignInPOST:        &emailPasswordSignInPOST,
-		EmailPasswordSignUpPOST:        &emailPasswordSignUpPOST,
-		AppleRedirectHandlerPOST:       &appleRedirectHandlerPOST,
+		AuthorisationUrlGET:             &authorisationUrlGET,
+		EmailPasswordEmailExistsGET:     &emailExistsGET,
+		GeneratePasswordResetTokenPOST:  &generatePasswordResetTokenPOST,
+		PasswordResetPOST:               &passwordResetPOST,
+		ThirdPartySignInUpPOST:          &thirdPartySignInUpPOST,
+		EmailPasswordSignInPOST:         &emailPasswordSignInPOST,
+		EmailPasswordSignUpPOST:         &emailPasswordSignUpPOST,
+		AppleRedirectHandlerPOST:        &appleRedirectHandlerPOST,
+		EmailPasswordReauthenticatePOST: &emailPasswordReauthenticatePOST,
+		ThirdPartyReauthenticatePOST:    &thirdPartyReauthenticatePOST,
 	}
 
 	modifiedEP := GetEmailPasswordIterfaceImpl(result)
@@ -171,11 +192,13 @@ func MakeAPIImplementation() tpepmodels.APIInterface {
 	(*emailPasswordImplementation.PasswordResetPOST) = *modifiedEP.PasswordResetPOST
 	(*emailPasswordImplementation.SignInPOST) = *modifiedEP.SignInPOST
 	(*emailPasswordImplementation.SignUpPOST) = *modifiedEP.SignUpPOST
+	(*emailPasswordImplementation.ReauthenticatePOST) = *modifiedEP.ReauthenticatePOST
 
 	modifiedTP := GetThirdPartyIterfaceImpl(result)
 	(*thirdPartyImplementation.AuthorisationUrlGET) = *modifiedTP.AuthorisationUrlGET
 	(*thirdPartyImplementation.SignInUpPOST) = *modifiedTP.SignInUpPOST
 	(*thirdPartyImplementation.AppleRedirectHandlerPOST) = *modifiedTP.AppleRedirectHandlerPOST
+	(*thirdPartyImplementation.ReauthenticatePOST) = *modifiedTP.ReauthenticatePOST
 
 	return result
 }
@@ -28,6 +28,7 @@ func GetThirdPartyIterfaceImpl(apiImplmentation tpepmodels.APIInterface) tpmodel
 			AuthorisationUrlGET:      apiImplmentation.AuthorisationUrlGET,
 			AppleRedirectHandlerPOST: apiImplmentation.AppleRedirectHandlerPOST,
 			SignInUpPOST:             nil,
+			ReauthenticatePOST:       apiImplmentation.ThirdPartyReauthenticatePOST,
 		}
 	}
 
@@ -59,6 +60,10 @@ func GetThirdPartyIterfaceImpl(apiImplmentation tpepmodels.APIInterface) tpmodel
 			return tpmodels.SignInUpPOSTResponse{
 				NoEmailGivenByProviderError: &struct{}{},
 			}, nil
+		} else if result.SessionLimitReachedError != nil {
+			return tpmodels.SignInUpPOSTResponse{
+				SessionLimitReachedError: &struct{}{},
+			}, nil
 		} else {
 			return tpmodels.SignInUpPOSTResponse{
 				GeneralError: result.GeneralError,
@@ -70,5 +75,6 @@ func GetThirdPartyIterfaceImpl(apiImplmentation tpepmodels.APIInterface) tpmodel
 		AuthorisationUrlGET:      apiImplmentation.AuthorisationUrlGET,
 		AppleRedirectHandlerPOST: apiImplmentation.AppleRedirectHandlerPOST,
 		SignInUpPOST:             &signInUpPOST,
+		ReauthenticatePOST:       apiImplmentation.ThirdPartyReauthenticatePOST,
 	}
 }
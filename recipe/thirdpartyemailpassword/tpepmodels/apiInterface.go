@@ -23,14 +23,16 @@ import (
 )
 
 type APIInterface struct {
-	AuthorisationUrlGET            *func(provider tpmodels.TypeProvider, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.AuthorisationUrlGETResponse, error)
-	AppleRedirectHandlerPOST       *func(code string, state string, options tpmodels.APIOptions, userContext supertokens.UserContext) error
-	EmailPasswordEmailExistsGET    *func(email string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.EmailExistsGETResponse, error)
-	GeneratePasswordResetTokenPOST *func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.GeneratePasswordResetTokenPOSTResponse, error)
-	PasswordResetPOST              *func(formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.ResetPasswordPOSTResponse, error)
-	ThirdPartySignInUpPOST         *func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, options tpmodels.APIOptions, userContext supertokens.UserContext) (ThirdPartyOutput, error)
-	EmailPasswordSignInPOST        *func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (SignInPOSTResponse, error)
-	EmailPasswordSignUpPOST        *func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (SignUpPOSTResponse, error)
+	AuthorisationUrlGET             *func(provider tpmodels.TypeProvider, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.AuthorisationUrlGETResponse, error)
+	AppleRedirectHandlerPOST        *func(code string, state string, options tpmodels.APIOptions, userContext supertokens.UserContext) error
+	EmailPasswordEmailExistsGET     *func(email string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.EmailExistsGETResponse, error)
+	GeneratePasswordResetTokenPOST  *func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.GeneratePasswordResetTokenPOSTResponse, error)
+	PasswordResetPOST               *func(formFields []epmodels.TypeFormField, token string, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.ResetPasswordPOSTResponse, error)
+	ThirdPartySignInUpPOST          *func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, options tpmodels.APIOptions, userContext supertokens.UserContext) (ThirdPartyOutput, error)
+	EmailPasswordSignInPOST         *func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (SignInPOSTResponse, error)
+	EmailPasswordSignUpPOST         *func(formFields []epmodels.TypeFormField, options epmodels.APIOptions, userContext supertokens.UserContext) (SignUpPOSTResponse, error)
+	EmailPasswordReauthenticatePOST *func(formFields []epmodels.TypeFormField, sessionContainer sessmodels.SessionContainer, options epmodels.APIOptions, userContext supertokens.UserContext) (epmodels.ReauthenticatePOSTResponse, error)
+	ThirdPartyReauthenticatePOST    *func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, sessionContainer sessmodels.SessionContainer, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.ReauthenticatePOSTResponse, error)
 }
 
 type SignUpPOSTResponse struct {
@@ -48,7 +50,9 @@ type SignInPOSTResponse struct {
 		Session sessmodels.SessionContainer
 	}
 	WrongCredentialsError *struct{}
-	GeneralError          *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
 }
 
 type EmailpasswordInput struct {
@@ -74,5 +78,7 @@ type ThirdPartyOutput struct {
 		Session          sessmodels.SessionContainer
 	}
 	NoEmailGivenByProviderError *struct{}
-	GeneralError                *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
 }
@@ -43,6 +43,10 @@ func MakeAPIImplementation() tplmodels.APIInterface {
 			return tplmodels.ThirdPartySignInUpOutput{
 				NoEmailGivenByProviderError: &struct{}{},
 			}, nil
+		} else if response.SessionLimitReachedError != nil {
+			return tplmodels.ThirdPartySignInUpOutput{
+				SessionLimitReachedError: &struct{}{},
+			}, nil
 		} else {
 			return tplmodels.ThirdPartySignInUpOutput{
 				OK: &struct {
@@ -112,6 +116,10 @@ func MakeAPIImplementation() tplmodels.APIInterface {
 			return tplmodels.ConsumeCodePOSTResponse{
 				RestartFlowError: &struct{}{},
 			}, nil
+		} else if resp.SessionLimitReachedError != nil {
+			return tplmodels.ConsumeCodePOSTResponse{
+				SessionLimitReachedError: &struct{}{},
+			}, nil
 		} else {
 			return tplmodels.ConsumeCodePOSTResponse{
 				GeneralError: resp.GeneralError,
@@ -139,6 +147,16 @@ func MakeAPIImplementation() tplmodels.APIInterface {
 		return ogResendCodePOST(deviceID, preAuthSessionID, options, userContext)
 	}
 
+	ogPasswordlessReauthenticatePOST := *passwordlessImplementation.ReauthenticatePOST
+	passwordlessReauthenticatePOST := func(userInput *plessmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, sessionContainer sessmodels.SessionContainer, options plessmodels.APIOptions, userContext supertokens.UserContext) (plessmodels.ReauthenticatePOSTResponse, error) {
+		return ogPasswordlessReauthenticatePOST(userInput, linkCode, preAuthSessionID, sessionContainer, options, userContext)
+	}
+
+	ogThirdPartyReauthenticatePOST := *thirdPartyImplementation.ReauthenticatePOST
+	thirdPartyReauthenticatePOST := func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, sessionContainer sessmodels.SessionContainer, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.ReauthenticatePOSTResponse, error) {
+		return ogThirdPartyReauthenticatePOST(provider, code, authCodeResponse, redirectURI, sessionContainer, options, userContext)
+	}
+
 	result := tplmodels.APIInterface{
 		AuthorisationUrlGET:              &authorisationUrlGET,
 		ThirdPartySignInUpPOST:           &thirdPartySignInUpPOST,
@@ -148,6 +166,8 @@ func MakeAPIImplementation() tplmodels.APIInterface {
 		ConsumeCodePOST:                  &consumeCodePOST,
 		PasswordlessEmailExistsGET:       &passwordlessEmailExistsGET,
 		PasswordlessPhoneNumberExistsGET: &passwordlessPhoneNumberExistsGET,
+		PasswordlessReauthenticatePOST:   &passwordlessReauthenticatePOST,
+		ThirdPartyReauthenticatePOST:     &thirdPartyReauthenticatePOST,
 	}
 
 	modifiedPwdless := GetPasswordlessIterfaceImpl(result)
@@ -156,11 +176,13 @@ func MakeAPIImplementation() tplmodels.APIInterface {
 	(*passwordlessImplementation.EmailExistsGET) = *modifiedPwdless.EmailExistsGET
 	(*passwordlessImplementation.PhoneNumberExistsGET) = *modifiedPwdless.PhoneNumberExistsGET
 	(*passwordlessImplementation.ResendCodePOST) = *modifiedPwdless.ResendCodePOST
+	(*passwordlessImplementation.ReauthenticatePOST) = *modifiedPwdless.ReauthenticatePOST
 
 	modifiedTP := GetThirdPartyIterfaceImpl(result)
 	(*thirdPartyImplementation.AuthorisationUrlGET) = *modifiedTP.AuthorisationUrlGET
 	(*thirdPartyImplementation.SignInUpPOST) = *modifiedTP.SignInUpPOST
 	(*thirdPartyImplementation.AppleRedirectHandlerPOST) = *modifiedTP.AppleRedirectHandlerPOST
+	(*thirdPartyImplementation.ReauthenticatePOST) = *modifiedTP.ReauthenticatePOST
 
 	return result
 }
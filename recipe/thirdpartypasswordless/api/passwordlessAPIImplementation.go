@@ -30,6 +30,7 @@ func GetPasswordlessIterfaceImpl(apiImplmentation tplmodels.APIInterface) plessm
 		EmailExistsGET:       apiImplmentation.PasswordlessEmailExistsGET,
 		PhoneNumberExistsGET: apiImplmentation.PasswordlessPhoneNumberExistsGET,
 		ConsumeCodePOST:      nil,
+		ReauthenticatePOST:   apiImplmentation.PasswordlessReauthenticatePOST,
 	}
 
 	if apiImplmentation.ConsumeCodePOST != nil && (*apiImplmentation.ConsumeCodePOST) != nil {
@@ -65,6 +66,10 @@ func GetPasswordlessIterfaceImpl(apiImplmentation tplmodels.APIInterface) plessm
 				return plessmodels.ConsumeCodePOSTResponse{
 					RestartFlowError: &struct{}{},
 				}, nil
+			} else if result.SessionLimitReachedError != nil {
+				return plessmodels.ConsumeCodePOSTResponse{
+					SessionLimitReachedError: &struct{}{},
+				}, nil
 			} else {
 				return plessmodels.ConsumeCodePOSTResponse{
 					GeneralError: result.GeneralError,
@@ -38,6 +38,10 @@ type APIInterface struct {
 	PasswordlessEmailExistsGET *func(email string, options plessmodels.APIOptions, userContext supertokens.UserContext) (plessmodels.EmailExistsGETResponse, error)
 
 	PasswordlessPhoneNumberExistsGET *func(email string, options plessmodels.APIOptions, userContext supertokens.UserContext) (plessmodels.PhoneNumberExistsGETResponse, error)
+
+	PasswordlessReauthenticatePOST *func(userInput *plessmodels.UserInputCodeWithDeviceID, linkCode *string, preAuthSessionID string, sessionContainer sessmodels.SessionContainer, options plessmodels.APIOptions, userContext supertokens.UserContext) (plessmodels.ReauthenticatePOSTResponse, error)
+
+	ThirdPartyReauthenticatePOST *func(provider tpmodels.TypeProvider, code string, authCodeResponse interface{}, redirectURI string, sessionContainer sessmodels.SessionContainer, options tpmodels.APIOptions, userContext supertokens.UserContext) (tpmodels.ReauthenticatePOSTResponse, error)
 }
 
 type ConsumeCodePOSTResponse struct {
@@ -55,7 +59,9 @@ type ConsumeCodePOSTResponse struct {
 		MaximumCodeInputAttempts    int
 	}
 	RestartFlowError *struct{}
-	GeneralError     *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
 }
 
 type ThirdPartySignInUpOutput struct {
@@ -66,5 +72,7 @@ type ThirdPartySignInUpOutput struct {
 		Session          sessmodels.SessionContainer
 	}
 	NoEmailGivenByProviderError *struct{}
-	GeneralError                *supertokens.GeneralErrorResponse
+	// SessionLimitReachedError is returned if the session recipe's SessionLimit rejected the new session
+	SessionLimitReachedError *struct{}
+	GeneralError             *supertokens.GeneralErrorResponse
 }
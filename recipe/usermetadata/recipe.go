@@ -43,6 +43,9 @@ func MakeRecipe(recipeId string, appInfo supertokens.NormalisedAppinfo, config *
 		return Recipe{}, err
 	}
 	recipeImplementation := makeRecipeImplementation(*querierInstance, verifiedConfig, appInfo)
+	if len(verifiedConfig.SessionClaimsToRefreshOnChange) > 0 {
+		recipeImplementation = makeRecipeImplementationWithClaimRefresh(recipeImplementation, verifiedConfig)
+	}
 	r.RecipeImpl = verifiedConfig.Override.Functions(recipeImplementation)
 
 	recipeModuleInstance := supertokens.MakeRecipeModule(recipeId, appInfo, r.handleAPIRequest, r.getAllCORSHeaders, r.getAPIsHandled, nil, r.handleError, onSuperTokensAPIError)
@@ -16,6 +16,7 @@
 package usermetadata
 
 import (
+	"github.com/supertokens/supertokens-golang/recipe/session"
 	"github.com/supertokens/supertokens-golang/recipe/usermetadata/usermetadatamodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -24,7 +25,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamo
 	getUserMetadata := func(userID string, userContext supertokens.UserContext) (map[string]interface{}, error) {
 		response, err := querier.SendGetRequest("/recipe/user/metadata", map[string]string{
 			"userId": userID,
-		})
+		}, userContext)
 		if err != nil {
 			return map[string]interface{}{}, err
 		}
@@ -36,7 +37,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamo
 		response, err := querier.SendPutRequest("/recipe/user/metadata", map[string]interface{}{
 			"userId":         userID,
 			"metadataUpdate": metadataUpdate,
-		})
+		}, userContext)
 		if err != nil {
 			return map[string]interface{}{}, err
 		}
@@ -47,7 +48,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamo
 	clearUserMetadata := func(userID string, userContext supertokens.UserContext) error {
 		_, err := querier.SendPostRequest("/recipe/user/metadata/remove", map[string]interface{}{
 			"userId": userID,
-		})
+		}, userContext)
 		return err
 	}
 
@@ -57,3 +58,46 @@ func makeRecipeImplementation(querier supertokens.Querier, config usermetadatamo
 		ClearUserMetadata:  &clearUserMetadata,
 	}
 }
+
+// makeRecipeImplementationWithClaimRefresh changes the functions updating and clearing the metadata of users of
+// originalImplementation so that they refresh config.SessionClaimsToRefreshOnChange in all the sessions of the user.
+// The claims are refreshed once the metadata is saved in the core, so a failed refresh is only logged, and the sessions
+// get the new values when the validators of the claims refetch them.
+func makeRecipeImplementationWithClaimRefresh(originalImplementation usermetadatamodels.RecipeInterface, config usermetadatamodels.TypeNormalisedInput) usermetadatamodels.RecipeInterface {
+	refreshClaimsOfUser := func(userID string, userContext supertokens.UserContext) {
+		for _, claim := range config.SessionClaimsToRefreshOnChange {
+			_, err := session.RefreshClaimForAllSessionsOfUserWithContext(userID, claim, userContext)
+			if err != nil {
+				supertokens.LogWarnMessage("usermetadata: could not refresh the claim "+claim.Key+" of the sessions of the user", supertokens.RecipeIDLogField(RECIPE_ID), supertokens.UserIDLogField(userID), supertokens.ErrorLogField(err))
+			}
+		}
+	}
+
+	{
+		originalUpdateUserMetadata := *originalImplementation.UpdateUserMetadata
+
+		(*originalImplementation.UpdateUserMetadata) = func(userID string, metadataUpdate map[string]interface{}, userContext supertokens.UserContext) (map[string]interface{}, error) {
+			metadata, err := originalUpdateUserMetadata(userID, metadataUpdate, userContext)
+			if err != nil {
+				return metadata, err
+			}
+			refreshClaimsOfUser(userID, userContext)
+			return metadata, nil
+		}
+	}
+
+	{
+		originalClearUserMetadata := *originalImplementation.ClearUserMetadata
+
+		(*originalImplementation.ClearUserMetadata) = func(userID string, userContext supertokens.UserContext) error {
+			err := originalClearUserMetadata(userID, userContext)
+			if err != nil {
+				return err
+			}
+			refreshClaimsOfUser(userID, userContext)
+			return nil
+		}
+	}
+
+	return originalImplementation
+}
@@ -17,11 +17,15 @@
 package usermetadata
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
 	"github.com/supertokens/supertokens-golang/recipe/usermetadata/usermetadatamodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
 	"github.com/supertokens/supertokens-golang/test/unittesting"
 )
 
@@ -406,3 +410,42 @@ func TestGetUserMetadataWithUTF8Encoding(t *testing.T) {
 		})
 	}
 }
+
+func TestSessionClaimsToRefreshOnChangeAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+	planClaim, _ := claims.PrimitiveClaim("plan", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		metadata, err := GetUserMetadataWithContext(userId, userContext)
+		if err != nil {
+			return nil, err
+		}
+		return metadata["plan"], nil
+	}, nil)
+	unavailableClaim, _ := claims.PrimitiveClaim("quota", func(userId string, userContext supertokens.UserContext) (interface{}, error) {
+		return nil, errors.New("the quota service is unavailable")
+	}, nil)
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{
+		RecipeList: []supertokens.Recipe{
+			session.Init(nil),
+			Init(&usermetadatamodels.TypeInput{SessionClaimsToRefreshOnChange: []*claims.TypeSessionClaim{unavailableClaim, planClaim}}),
+		},
+	})
+
+	newSession, err := session.CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	getPlan := func() interface{} {
+		sessionInformation, err := session.GetSessionInformation(newSession.GetHandle())
+		assert.NoError(t, err)
+		return planClaim.GetValueFromPayload(sessionInformation.AccessTokenPayload, &map[string]interface{}{})
+	}
+
+	// a claim that cannot be refreshed does not make the update fail
+	_, err = UpdateUserMetadata("userId", map[string]interface{}{"plan": "free"})
+	assert.NoError(t, err)
+	assert.Equal(t, "free", getPlan())
+
+	// the claim is removed when it has no value anymore
+	err = ClearUserMetadata("userId")
+	assert.NoError(t, err)
+	assert.Nil(t, getPlan())
+}
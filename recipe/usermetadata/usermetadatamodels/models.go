@@ -15,12 +15,19 @@
 
 package usermetadatamodels
 
+import "github.com/supertokens/supertokens-golang/recipe/session/claims"
+
 type TypeInput struct {
-	Override *OverrideStruct
+	// SessionClaimsToRefreshOnChange are the session claims whose value is built from the metadata of the user.
+	// UpdateUserMetadata and ClearUserMetadata refresh them in all the sessions of the user. They are refreshed
+	// after the metadata is saved, so the failures to refresh them are only logged.
+	SessionClaimsToRefreshOnChange []*claims.TypeSessionClaim
+	Override                       *OverrideStruct
 }
 
 type TypeNormalisedInput struct {
-	Override OverrideStruct
+	SessionClaimsToRefreshOnChange []*claims.TypeSessionClaim
+	Override                       OverrideStruct
 }
 
 type OverrideStruct struct {
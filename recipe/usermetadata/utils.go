@@ -24,6 +24,10 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 
 	typeNormalisedInput := makeTypeNormalisedInput(appInfo)
 
+	if config != nil {
+		typeNormalisedInput.SessionClaimsToRefreshOnChange = config.SessionClaimsToRefreshOnChange
+	}
+
 	if config != nil && config.Override != nil {
 		if config.Override.Functions != nil {
 			typeNormalisedInput.Override.Functions = config.Override.Functions
@@ -12,6 +12,7 @@ import (
 	"github.com/supertokens/supertokens-golang/recipe/userroles/userrolesclaims"
 	"github.com/supertokens/supertokens-golang/recipe/userroles/userrolesmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/fakecore"
 	"github.com/supertokens/supertokens-golang/test/unittesting"
 )
 
@@ -422,3 +423,41 @@ func TestShouldValidatePermissionsAfterRefetching(t *testing.T) {
 	assert.Contains(t, reason["actualValue"], "a")
 	assert.Contains(t, reason["actualValue"], "b")
 }
+
+func TestRefreshSessionClaimsOnChangeAgainstFakeCore(t *testing.T) {
+	core := fakecore.StartForTest(t, resetAll)
+	resetAll()
+	core.InitForTest(t, supertokens.TypeInput{
+		RecipeList: []supertokens.Recipe{
+			session.Init(nil),
+			Init(&userrolesmodels.TypeInput{RefreshSessionClaimsOnChange: true}),
+		},
+	})
+
+	newSession, err := session.CreateNewSessionWithoutRequestResponse("userId", nil, nil, true)
+	assert.NoError(t, err)
+	getClaimValue := func(claim *claims.TypeSessionClaim) interface{} {
+		sessionInformation, err := session.GetSessionInformation(newSession.GetHandle())
+		assert.NoError(t, err)
+		return claim.GetValueFromPayload(sessionInformation.AccessTokenPayload, &map[string]interface{}{})
+	}
+
+	_, err = CreateNewRoleOrAddPermissions("admin", []string{"write"}, nil)
+	assert.NoError(t, err)
+	_, err = AddRoleToUser("userId", "admin", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"admin"}, getClaimValue(userrolesclaims.UserRoleClaim))
+	assert.Equal(t, []interface{}{"write"}, getClaimValue(userrolesclaims.PermissionClaim))
+
+	// changing the permissions of a role refreshes the sessions of its users
+	_, err = RemovePermissionsFromRole("admin", []string{"write"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, getClaimValue(userrolesclaims.PermissionClaim))
+	_, err = CreateNewRoleOrAddPermissions("admin", []string{"read"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"read"}, getClaimValue(userrolesclaims.PermissionClaim))
+	_, err = DeleteRole("admin", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, getClaimValue(userrolesclaims.UserRoleClaim))
+	assert.Equal(t, []interface{}{}, getClaimValue(userrolesclaims.PermissionClaim))
+}
@@ -16,6 +16,9 @@
 package userroles
 
 import (
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/claims"
+	"github.com/supertokens/supertokens-golang/recipe/userroles/userrolesclaims"
 	"github.com/supertokens/supertokens-golang/recipe/userroles/userrolesmodels"
 	"github.com/supertokens/supertokens-golang/supertokens"
 )
@@ -26,7 +29,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 		response, err := querier.SendPutRequest("/recipe/user/role", map[string]interface{}{
 			"userId": userID,
 			"role":   role,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.AddRoleToUserResponse{}, err
 		}
@@ -48,7 +51,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 		response, err := querier.SendPostRequest("/recipe/user/role/remove", map[string]interface{}{
 			"userId": userID,
 			"role":   role,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.RemoveUserRoleResponse{}, err
 		}
@@ -69,7 +72,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	getRolesForUser := func(userID string, userContext supertokens.UserContext) (userrolesmodels.GetRolesForUserResponse, error) {
 		response, err := querier.SendGetRequest("/recipe/user/roles", map[string]string{
 			"userId": userID,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.GetRolesForUserResponse{}, err
 		}
@@ -85,7 +88,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	getUsersThatHaveRole := func(role string, userContext supertokens.UserContext) (userrolesmodels.GetUsersThatHaveRoleResponse, error) {
 		response, err := querier.SendGetRequest("/recipe/role/users", map[string]string{
 			"role": role,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.GetUsersThatHaveRoleResponse{}, err
 		}
@@ -107,7 +110,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 		response, err := querier.SendPutRequest("/recipe/role", map[string]interface{}{
 			"role":        role,
 			"permissions": permissions,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.CreateNewRoleOrAddPermissionsResponse{}, err
 		}
@@ -122,7 +125,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	getPermissionsForRole := func(role string, userContext supertokens.UserContext) (userrolesmodels.GetPermissionsForRoleResponse, error) {
 		response, err := querier.SendGetRequest("/recipe/role/permissions", map[string]string{
 			"role": role,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.GetPermissionsForRoleResponse{}, err
 		}
@@ -144,7 +147,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 		response, err := querier.SendPostRequest("/recipe/role/permissions/remove", map[string]interface{}{
 			"role":        role,
 			"permissions": permissions,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.RemovePermissionsFromRoleResponse{}, err
 		}
@@ -163,7 +166,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	getRolesThatHavePermission := func(permission string, userContext supertokens.UserContext) (userrolesmodels.GetRolesThatHavePermissionResponse, error) {
 		response, err := querier.SendGetRequest("/recipe/permission/roles", map[string]string{
 			"permission": permission,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.GetRolesThatHavePermissionResponse{}, err
 		}
@@ -178,7 +181,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	deleteRole := func(role string, userContext supertokens.UserContext) (userrolesmodels.DeleteRoleResponse, error) {
 		response, err := querier.SendPostRequest("/recipe/role/remove", map[string]interface{}{
 			"role": role,
-		})
+		}, userContext)
 		if err != nil {
 			return userrolesmodels.DeleteRoleResponse{}, err
 		}
@@ -191,7 +194,7 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 	}
 
 	getAllRoles := func(userContext supertokens.UserContext) (userrolesmodels.GetAllRolesResponse, error) {
-		response, err := querier.SendGetRequest("/recipe/roles", map[string]string{})
+		response, err := querier.SendGetRequest("/recipe/roles", map[string]string{}, userContext)
 		if err != nil {
 			return userrolesmodels.GetAllRolesResponse{}, err
 		}
@@ -216,3 +219,118 @@ func makeRecipeImplementation(querier supertokens.Querier, config userrolesmodel
 		GetAllRoles:                   &getAllRoles,
 	}
 }
+
+// makeRecipeImplementationWithClaimRefresh changes the functions changing the roles of the users, or the permissions
+// of the roles, of originalImplementation so that they refresh the role and permission claims of all the sessions of
+// the users affected by the change. The claims are refreshed once the change is saved in the core, so a failed refresh
+// is only logged, and the sessions get the new values when the validators of the claims refetch them.
+func makeRecipeImplementationWithClaimRefresh(originalImplementation userrolesmodels.RecipeInterface, config userrolesmodels.TypeNormalisedInput) userrolesmodels.RecipeInterface {
+	refreshClaimsOfUsers := func(userIDs []string, refreshRoles bool, userContext supertokens.UserContext) {
+		claimsToRefresh := []*claims.TypeSessionClaim{}
+		if refreshRoles && !config.SkipAddingRolesToAccessToken {
+			claimsToRefresh = append(claimsToRefresh, userrolesclaims.UserRoleClaim)
+		}
+		if !config.SkipAddingPermissionsToAccessToken {
+			claimsToRefresh = append(claimsToRefresh, userrolesclaims.PermissionClaim)
+		}
+		for _, userID := range userIDs {
+			for _, claim := range claimsToRefresh {
+				_, err := session.RefreshClaimForAllSessionsOfUserWithContext(userID, claim, userContext)
+				if err != nil {
+					supertokens.LogWarnMessage("userroles: could not refresh the claim "+claim.Key+" of the sessions of the user", supertokens.RecipeIDLogField(RECIPE_ID), supertokens.UserIDLogField(userID), supertokens.ErrorLogField(err))
+				}
+			}
+		}
+	}
+	getUsersThatHaveRole := func(role string, userContext supertokens.UserContext) ([]string, error) {
+		response, err := (*originalImplementation.GetUsersThatHaveRole)(role, userContext)
+		if err != nil {
+			return nil, err
+		}
+		if response.OK == nil {
+			return []string{}, nil
+		}
+		return response.OK.Users, nil
+	}
+	refreshClaimsOfUsersWithRole := func(role string, userContext supertokens.UserContext) {
+		userIDs, err := getUsersThatHaveRole(role, userContext)
+		if err != nil {
+			supertokens.LogWarnMessage("userroles: could not get the users of the role "+role+" to refresh the claims of their sessions", supertokens.RecipeIDLogField(RECIPE_ID), supertokens.ErrorLogField(err))
+			return
+		}
+		refreshClaimsOfUsers(userIDs, false, userContext)
+	}
+
+	{
+		originalAddRoleToUser := *originalImplementation.AddRoleToUser
+
+		(*originalImplementation.AddRoleToUser) = func(userID string, role string, userContext supertokens.UserContext) (userrolesmodels.AddRoleToUserResponse, error) {
+			response, err := originalAddRoleToUser(userID, role, userContext)
+			if err != nil || response.OK == nil {
+				return response, err
+			}
+			// the claims are also refreshed if the user already had the role, in case a previous refresh failed
+			refreshClaimsOfUsers([]string{userID}, true, userContext)
+			return response, nil
+		}
+	}
+
+	{
+		originalRemoveUserRole := *originalImplementation.RemoveUserRole
+
+		(*originalImplementation.RemoveUserRole) = func(userID string, role string, userContext supertokens.UserContext) (userrolesmodels.RemoveUserRoleResponse, error) {
+			response, err := originalRemoveUserRole(userID, role, userContext)
+			if err != nil || response.OK == nil {
+				return response, err
+			}
+			refreshClaimsOfUsers([]string{userID}, true, userContext)
+			return response, nil
+		}
+	}
+
+	{
+		originalCreateNewRoleOrAddPermissions := *originalImplementation.CreateNewRoleOrAddPermissions
+
+		(*originalImplementation.CreateNewRoleOrAddPermissions) = func(role string, permissions []string, userContext supertokens.UserContext) (userrolesmodels.CreateNewRoleOrAddPermissionsResponse, error) {
+			response, err := originalCreateNewRoleOrAddPermissions(role, permissions, userContext)
+			if err != nil || response.OK == nil || response.OK.CreatedNewRole || len(permissions) == 0 {
+				return response, err
+			}
+			refreshClaimsOfUsersWithRole(role, userContext)
+			return response, nil
+		}
+	}
+
+	{
+		originalRemovePermissionsFromRole := *originalImplementation.RemovePermissionsFromRole
+
+		(*originalImplementation.RemovePermissionsFromRole) = func(role string, permissions []string, userContext supertokens.UserContext) (userrolesmodels.RemovePermissionsFromRoleResponse, error) {
+			response, err := originalRemovePermissionsFromRole(role, permissions, userContext)
+			if err != nil || response.OK == nil {
+				return response, err
+			}
+			refreshClaimsOfUsersWithRole(role, userContext)
+			return response, nil
+		}
+	}
+
+	{
+		originalDeleteRole := *originalImplementation.DeleteRole
+
+		(*originalImplementation.DeleteRole) = func(role string, userContext supertokens.UserContext) (userrolesmodels.DeleteRoleResponse, error) {
+			// the users are fetched first, since the role does not have any once it is deleted
+			userIDs, err := getUsersThatHaveRole(role, userContext)
+			if err != nil {
+				return userrolesmodels.DeleteRoleResponse{}, err
+			}
+			response, err := originalDeleteRole(role, userContext)
+			if err != nil || response.OK == nil || !response.OK.DidRoleExist {
+				return response, err
+			}
+			refreshClaimsOfUsers(userIDs, true, userContext)
+			return response, nil
+		}
+	}
+
+	return originalImplementation
+}
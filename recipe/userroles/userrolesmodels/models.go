@@ -18,6 +18,11 @@ package userrolesmodels
 type TypeInput struct {
 	SkipAddingRolesToAccessToken       bool
 	SkipAddingPermissionsToAccessToken bool
+	// RefreshSessionClaimsOnChange makes the functions changing the roles of a user refresh the role and permission
+	// claims of all their sessions, and the ones changing the permissions of a role refresh the permission claim of
+	// all the sessions of its users. This makes a few requests to the core for each session of the affected users.
+	// The claims are refreshed after the change is saved, so the failures to refresh them are only logged.
+	RefreshSessionClaimsOnChange bool
 
 	Override *OverrideStruct
 }
@@ -25,6 +30,7 @@ type TypeInput struct {
 type TypeNormalisedInput struct {
 	SkipAddingRolesToAccessToken       bool
 	SkipAddingPermissionsToAccessToken bool
+	RefreshSessionClaimsOnChange       bool
 
 	Override OverrideStruct
 }
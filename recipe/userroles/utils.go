@@ -27,6 +27,7 @@ func validateAndNormaliseUserInput(appInfo supertokens.NormalisedAppinfo, config
 	if config != nil {
 		typeNormalisedInput.SkipAddingRolesToAccessToken = config.SkipAddingRolesToAccessToken
 		typeNormalisedInput.SkipAddingPermissionsToAccessToken = config.SkipAddingPermissionsToAccessToken
+		typeNormalisedInput.RefreshSessionClaimsOnChange = config.RefreshSessionClaimsOnChange
 	}
 
 	if config != nil && config.Override != nil {
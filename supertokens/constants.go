@@ -15,6 +15,8 @@
 
 package supertokens
 
+import "time"
+
 const (
 	HeaderRID = "rid"
 	HeaderFDI = "fdi-version"
@@ -28,3 +30,13 @@ var (
 )
 
 const DashboardVersion = "0.6"
+
+// DefaultCoreRequestTimeout is used when ConnectionInfo.Timeout is not set
+const DefaultCoreRequestTimeout = 30 * time.Second
+
+// Connection pooling defaults for the transport used to query the core
+const (
+	defaultQuerierMaxIdleConns        = 100
+	defaultQuerierMaxIdleConnsPerHost = 20
+	defaultQuerierIdleConnTimeout     = 90 * time.Second
+)
@@ -15,6 +15,11 @@
 
 package supertokens
 
+import (
+	"fmt"
+	"net/http"
+)
+
 // BadInputError used for non specific exceptions
 type BadInputError struct {
 	Msg string
@@ -23,3 +28,46 @@ type BadInputError struct {
 func (err BadInputError) Error() string {
 	return err.Msg
 }
+
+// CoreError is returned when the SuperTokens core responds with a non 200 status code
+type CoreError struct {
+	StatusCode int
+	Path       string
+	Method     string
+	CDIVersion string
+	// Message is the raw body sent by the core
+	Message string
+	// Body is the parsed JSON body sent by the core, or nil if it was not JSON
+	Body map[string]interface{}
+}
+
+func (err CoreError) Error() string {
+	return fmt.Sprintf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", err.Path, err.StatusCode, err.Message)
+}
+
+// IsBadRequest returns true if the core rejected the request because of invalid input
+func (err CoreError) IsBadRequest() bool {
+	return err.StatusCode == http.StatusBadRequest
+}
+
+// IsUnauthorised returns true if the core rejected the API key
+func (err CoreError) IsUnauthorised() bool {
+	return err.StatusCode == http.StatusUnauthorized
+}
+
+// CoreUnavailableError is returned when none of the configured cores could be reached
+type CoreUnavailableError struct {
+	Msg string
+	Err error
+}
+
+func (err CoreUnavailableError) Error() string {
+	if err.Err == nil {
+		return err.Msg
+	}
+	return err.Msg + ": " + err.Err.Error()
+}
+
+func (err CoreUnavailableError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,134 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import "context"
+
+// Names of the spans started by the SDK
+const (
+	SpanNameMiddleware     = "supertokens.middleware"
+	SpanNameVerifySession  = "supertokens.session.verify"
+	SpanNameGetSession     = "supertokens.session.get"
+	SpanNameRefreshSession = "supertokens.session.refresh"
+	SpanNameCoreRequest    = "supertokens.core.request"
+)
+
+// Names of the counters incremented by the SDK
+const (
+	CounterNameSignIns     = "supertokens.sign_ins"
+	CounterNameSignUps     = "supertokens.sign_ups"
+	CounterNameRefreshes   = "supertokens.session.refreshes"
+	CounterNameTokenThefts = "supertokens.session.token_thefts"
+	CounterNameCoreErrors  = "supertokens.core.errors"
+)
+
+// Keys of the fields attached to the spans of requests sent to the core
+const (
+	LogKeyHTTPMethod = "httpMethod"
+	LogKeyCorePath   = "corePath"
+)
+
+func HTTPMethodLogField(method string) LogField {
+	return LogField{Key: LogKeyHTTPMethod, Value: method}
+}
+
+func CorePathLogField(path string) LogField {
+	return LogField{Key: LogKeyCorePath, Value: path}
+}
+
+// EndSpanFunc ends a span started by Instrumentation.StartSpan. err is the error
+// the traced operation failed with, or nil.
+type EndSpanFunc func(err error)
+
+// Instrumentation receives the SDK's spans and counter increments. Set it using
+// TypeInput.Instrumentation to export them to a tracing or metrics backend (the
+// contrib/supertokensotel module provides one for OpenTelemetry).
+type Instrumentation interface {
+	// StartSpan starts a span as a child of ctx, and returns the context that carries it.
+	// The returned function is called exactly once, when the traced operation finishes.
+	StartSpan(ctx context.Context, name string, fields []LogField) (context.Context, EndSpanFunc)
+	// IncrementCounter adds one to the named counter.
+	IncrementCounter(ctx context.Context, name string, fields []LogField)
+}
+
+var sdkInstrumentation Instrumentation = noopInstrumentation{}
+
+type noopInstrumentation struct{}
+
+func (noopInstrumentation) StartSpan(ctx context.Context, name string, fields []LogField) (context.Context, EndSpanFunc) {
+	return ctx, func(err error) {}
+}
+
+func (noopInstrumentation) IncrementCounter(ctx context.Context, name string, fields []LogField) {}
+
+func setInstrumentation(i Instrumentation) {
+	if i == nil {
+		i = noopInstrumentation{}
+	}
+	sdkInstrumentation = i
+}
+
+func isInstrumentationEnabled() bool {
+	_, isNoop := sdkInstrumentation.(noopInstrumentation)
+	return !isNoop
+}
+
+func StartSpan(ctx context.Context, name string, fields ...LogField) (context.Context, EndSpanFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return sdkInstrumentation.StartSpan(ctx, name, fields)
+}
+
+// StartSpanInUserContext starts a span as a child of the context carried by userContext,
+// and attaches the span's context to userContext so that requests sent to the core with
+// it are traced under the span. Ending the span restores the previous context.
+func StartSpanInUserContext(userContext UserContext, name string, fields ...LogField) EndSpanFunc {
+	if !isInstrumentationEnabled() || userContext == nil {
+		return func(err error) {}
+	}
+	var previousCtx interface{}
+	hadPreviousCtx := false
+	if defaultValues, ok := (*userContext)["_default"].(map[string]interface{}); ok {
+		previousCtx, hadPreviousCtx = defaultValues["context"]
+	}
+	ctx, endSpan := StartSpan(GetContextFromUserContext(userContext), name, fields...)
+	SetContextInUserContext(userContext, ctx)
+	return func(err error) {
+		endSpan(err)
+		defaultValues, ok := (*userContext)["_default"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		if hadPreviousCtx {
+			defaultValues["context"] = previousCtx
+		} else {
+			delete(defaultValues, "context")
+		}
+	}
+}
+
+func IncrementCounter(ctx context.Context, name string, fields ...LogField) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sdkInstrumentation.IncrementCounter(ctx, name, fields)
+}
+
+// IncrementCounterWithUserContext increments the named counter, using the context carried by userContext
+func IncrementCounterWithUserContext(userContext UserContext, name string, fields ...LogField) {
+	IncrementCounter(GetContextFromUserContext(userContext), name, fields...)
+}
@@ -0,0 +1,165 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKeyForTest struct{}
+
+type spanForTest struct {
+	name   string
+	fields []LogField
+	parent string
+	ended  bool
+	err    error
+}
+
+type counterEventForTest struct {
+	name   string
+	fields []LogField
+}
+
+type recordingInstrumentationForTest struct {
+	spans    []*spanForTest
+	counters []counterEventForTest
+}
+
+func (i *recordingInstrumentationForTest) StartSpan(ctx context.Context, name string, fields []LogField) (context.Context, EndSpanFunc) {
+	span := &spanForTest{name: name, fields: fields}
+	if parent, ok := ctx.Value(spanKeyForTest{}).(string); ok {
+		span.parent = parent
+	}
+	i.spans = append(i.spans, span)
+	return context.WithValue(ctx, spanKeyForTest{}, name), func(err error) {
+		span.ended = true
+		span.err = err
+	}
+}
+
+func (i *recordingInstrumentationForTest) IncrementCounter(ctx context.Context, name string, fields []LogField) {
+	i.counters = append(i.counters, counterEventForTest{name: name, fields: fields})
+}
+
+func (i *recordingInstrumentationForTest) getSpansNamed(name string) []*spanForTest {
+	result := []*spanForTest{}
+	for _, span := range i.spans {
+		if span.name == name {
+			result = append(result, span)
+		}
+	}
+	return result
+}
+
+func TestQuerierRecordsSpanAndCoreErrorCounter(t *testing.T) {
+	server, _ := startFlakyCoreForTest(1, http.StatusBadRequest)
+	defer server.Close()
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL})
+	defer ResetQuerierForTest()
+	instrumentation := &recordingInstrumentationForTest{}
+	setInstrumentation(instrumentation)
+	defer setInstrumentation(nil)
+
+	q, err := GetNewQuerierInstanceOrThrowError("emailpassword")
+	assert.NoError(t, err)
+	userContext := MakeDefaultUserContextFromContext(context.WithValue(context.Background(), spanKeyForTest{}, "parent"))
+	_, err = q.SendGetRequest("/recipe/user", nil, userContext)
+	assert.Error(t, err)
+	_, err = q.SendGetRequest("/recipe/user", nil, userContext)
+	assert.NoError(t, err)
+
+	spans := []*spanForTest{}
+	for _, span := range instrumentation.getSpansNamed(SpanNameCoreRequest) {
+		if span.fields[1].Value == "/recipe/user" {
+			spans = append(spans, span)
+		}
+	}
+	assert.Equal(t, 2, len(spans))
+	assert.Equal(t, []LogField{HTTPMethodLogField(http.MethodGet), CorePathLogField("/recipe/user"), RecipeIDLogField("emailpassword")}, spans[0].fields)
+	assert.Equal(t, "parent", spans[0].parent)
+	assert.True(t, spans[0].ended)
+	assert.True(t, errors.As(spans[0].err, &CoreError{}))
+	assert.NoError(t, spans[1].err)
+
+	assert.Equal(t, 1, len(instrumentation.counters))
+	assert.Equal(t, CounterNameCoreErrors, instrumentation.counters[0].name)
+}
+
+func TestStartSpanInUserContextRestoresPreviousContext(t *testing.T) {
+	instrumentation := &recordingInstrumentationForTest{}
+	setInstrumentation(instrumentation)
+	defer setInstrumentation(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	userContext := MakeDefaultUserContextFromAPI(req)
+	endSpan := StartSpanInUserContext(userContext, SpanNameGetSession)
+	assert.Equal(t, SpanNameGetSession, GetContextFromUserContext(userContext).Value(spanKeyForTest{}))
+	endSpan(nil)
+	assert.Equal(t, req.Context(), GetContextFromUserContext(userContext))
+	assert.True(t, instrumentation.spans[0].ended)
+}
+
+func TestMiddlewareRecordsSpanForHandledAPI(t *testing.T) {
+	instrumentation := &recordingInstrumentationForTest{}
+	var ctxInHandler context.Context
+	err := Init(TypeInput{
+		Supertokens: &ConnectionInfo{ConnectionURI: "http://localhost:8080"},
+		AppInfo: AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{
+			func(appInfo NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*RecipeModule, error) {
+				path, err := NewNormalisedURLPath("/test")
+				if err != nil {
+					return nil, err
+				}
+				module := MakeRecipeModule("test", appInfo, func(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path NormalisedURLPath, method string) error {
+					ctxInHandler = req.Context()
+					return errors.New("test error")
+				}, func() []string {
+					return []string{}
+				}, func() ([]APIHandled, error) {
+					return []APIHandled{{PathWithoutAPIBasePath: path, Method: http.MethodPost, ID: "testAPI"}}, nil
+				}, nil, func(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+					return true, nil
+				}, onSuperTokensAPIError)
+				return &module, nil
+			},
+		},
+		Instrumentation: instrumentation,
+	})
+	assert.NoError(t, err)
+	defer ResetForTest()
+
+	rec := httptest.NewRecorder()
+	Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {})).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/test", nil))
+
+	spans := instrumentation.getSpansNamed(SpanNameMiddleware)
+	assert.Equal(t, 1, len(spans))
+	assert.Equal(t, []LogField{RecipeIDLogField("test"), APIIDLogField("testAPI")}, spans[0].fields)
+	assert.True(t, spans[0].ended)
+	assert.EqualError(t, spans[0].err, "test error")
+	assert.Equal(t, SpanNameMiddleware, ctxInHandler.Value(spanKeyForTest{}))
+}
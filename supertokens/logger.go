@@ -5,29 +5,135 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 )
 
 const supertokens_namespace = "com.supertokens"
 
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(level))
+}
+
+// Keys of the fields attached to log events by the SDK
+const (
+	LogKeyRecipeID      = "recipeId"
+	LogKeyAPIID         = "apiId"
+	LogKeyUserID        = "userId"
+	LogKeySessionHandle = "sessionHandle"
+	LogKeyError         = "error"
+)
+
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+func RecipeIDLogField(recipeID string) LogField {
+	return LogField{Key: LogKeyRecipeID, Value: recipeID}
+}
+
+func APIIDLogField(apiID string) LogField {
+	return LogField{Key: LogKeyAPIID, Value: apiID}
+}
+
+func UserIDLogField(userID string) LogField {
+	return LogField{Key: LogKeyUserID, Value: userID}
+}
+
+func SessionHandleLogField(sessionHandle string) LogField {
+	return LogField{Key: LogKeySessionHandle, Value: sessionHandle}
+}
+
+func ErrorLogField(err error) LogField {
+	return LogField{Key: LogKeyError, Value: err}
+}
+
+// Logger receives every log event of the SDK. Set it using TypeInput.Logger
+// to route the SDK's logs to your own logging library.
+type Logger interface {
+	Log(level LogLevel, message string, fields []LogField)
+}
+
+// LoggerFunc allows using an ordinary function as a Logger
+type LoggerFunc func(level LogLevel, message string, fields []LogField)
+
+func (f LoggerFunc) Log(level LogLevel, message string, fields []LogField) {
+	f(level, message, fields)
+}
+
 /*
- The debug logger below can be used to log debug messages in the following format
-    com.supertokens {t: "2022-03-21T17:10:42+05:30", message: "Test Message", file: "/home/supertokens-golang/supertokens/supertokens.go:51" sdkVer: "0.5.2"}
+ The default logger below prints all log events, only if SUPERTOKENS_DEBUG is set, in the following format
+    com.supertokens {t: "2022-03-21T17:10:42+05:30", level: "debug", message: "Test Message", file: "/home/supertokens-golang/supertokens/supertokens.go:51" sdkVer: "0.5.2"}
 */
 
 var (
 	logger = log.New(os.Stdout, supertokens_namespace, 0)
+
+	sdkLogger Logger = defaultLogger{}
 )
 
-func formatMessage(message string) string {
-	_, file, line, _ := runtime.Caller(3)
-	return fmt.Sprintf(" {t: \"%s\", message: \"%s\", file: \"%s:%d\" sdkVer: \"%s\"}\n\n", time.Now().Format(time.RFC3339), message, file, line, VERSION)
-}
+type defaultLogger struct{}
 
-func LogDebugMessage(message string) {
+func (defaultLogger) Log(level LogLevel, message string, fields []LogField) {
 	_, exists := os.LookupEnv("SUPERTOKENS_DEBUG")
 	if exists {
-		logger.Printf(formatMessage(message))
+		logger.Print(formatMessage(level, message, fields))
+	}
+}
+
+func formatMessage(level LogLevel, message string, fields []LogField) string {
+	// skips this function, defaultLogger.Log, logMessage and the exported Log*Message function
+	_, file, line, _ := runtime.Caller(4)
+	formattedFields := ""
+	for _, field := range fields {
+		formattedFields += fmt.Sprintf(", %s: \"%v\"", field.Key, field.Value)
+	}
+	return fmt.Sprintf(" {t: \"%s\", level: \"%s\", message: \"%s\", file: \"%s:%d\" sdkVer: \"%s\"%s}\n\n", time.Now().Format(time.RFC3339), level, strings.ReplaceAll(message, "\"", "\\\""), file, line, VERSION, formattedFields)
+}
 
+func setLogger(l Logger) {
+	if l == nil {
+		l = defaultLogger{}
 	}
+	sdkLogger = l
+}
+
+func logMessage(level LogLevel, message string, fields []LogField) {
+	sdkLogger.Log(level, message, fields)
+}
+
+func LogDebugMessage(message string, fields ...LogField) {
+	logMessage(LogLevelDebug, message, fields)
+}
+
+func LogInfoMessage(message string, fields ...LogField) {
+	logMessage(LogLevelInfo, message, fields)
+}
+
+func LogWarnMessage(message string, fields ...LogField) {
+	logMessage(LogLevelWarn, message, fields)
+}
+
+func LogErrorMessage(message string, fields ...LogField) {
+	logMessage(LogLevelError, message, fields)
 }
@@ -0,0 +1,127 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logEventForTest struct {
+	level   LogLevel
+	message string
+	fields  []LogField
+}
+
+func captureLogsForTest() *[]logEventForTest {
+	events := []logEventForTest{}
+	setLogger(LoggerFunc(func(level LogLevel, message string, fields []LogField) {
+		events = append(events, logEventForTest{level: level, message: message, fields: fields})
+	}))
+	return &events
+}
+
+func TestCustomLoggerReceivesLeveledEventsWithFields(t *testing.T) {
+	events := captureLogsForTest()
+	defer setLogger(nil)
+
+	LogDebugMessage("debug message")
+	LogWarnMessage("warn message", RecipeIDLogField("session"), SessionHandleLogField("handle"))
+
+	assert.Equal(t, 2, len(*events))
+	assert.Equal(t, LogLevelDebug, (*events)[0].level)
+	assert.Empty(t, (*events)[0].fields)
+	assert.Equal(t, LogLevelWarn, (*events)[1].level)
+	assert.Equal(t, "warn message", (*events)[1].message)
+	assert.Equal(t, []LogField{{Key: LogKeyRecipeID, Value: "session"}, {Key: LogKeySessionHandle, Value: "handle"}}, (*events)[1].fields)
+}
+
+func TestDefaultLoggerOnlyPrintsInDebugMode(t *testing.T) {
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	defer logger.SetOutput(os.Stdout)
+
+	os.Unsetenv("SUPERTOKENS_DEBUG")
+	LogErrorMessage("not printed")
+	assert.Empty(t, buf.String())
+
+	os.Setenv("SUPERTOKENS_DEBUG", "1")
+	defer os.Unsetenv("SUPERTOKENS_DEBUG")
+	LogErrorMessage("printed", UserIDLogField("user"))
+	assert.True(t, strings.HasPrefix(buf.String(), "com.supertokens {t: "))
+	assert.Contains(t, buf.String(), `level: "error", message: "printed"`)
+	assert.Contains(t, buf.String(), `userId: "user"`)
+	assert.Contains(t, buf.String(), "logger_test.go")
+}
+
+func TestQuerierLogsWarningsAndErrorsForCoreFailures(t *testing.T) {
+	server, _ := startFlakyCoreForTest(10, http.StatusServiceUnavailable)
+	defer server.Close()
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL, RetryPolicy: &RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusServiceUnavailable}}})
+	defer ResetQuerierForTest()
+	events := captureLogsForTest()
+	defer setLogger(nil)
+
+	q, err := GetNewQuerierInstanceOrThrowError("emailpassword")
+	assert.NoError(t, err)
+	_, err = q.SendGetRequest("/recipe/user", nil, nil)
+	assert.Error(t, err)
+
+	levels := []LogLevel{}
+	for _, event := range *events {
+		if event.level == LogLevelDebug {
+			continue
+		}
+		levels = append(levels, event.level)
+		assert.Contains(t, event.fields, RecipeIDLogField("emailpassword"))
+	}
+	// one warning per failed attempt, and an error once all attempts have failed
+	assert.Equal(t, []LogLevel{LogLevelWarn, LogLevelWarn, LogLevelError}, levels)
+}
+
+func TestInitSetsLoggerAndResetRestoresDefault(t *testing.T) {
+	events := []string{}
+	err := Init(TypeInput{
+		Supertokens: &ConnectionInfo{ConnectionURI: "http://localhost:8080"},
+		AppInfo: AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{
+			func(appInfo NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*RecipeModule, error) {
+				module := MakeRecipeModule("test", appInfo, nil, nil, nil, nil, func(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+					return false, nil
+				}, onSuperTokensAPIError)
+				return &module, nil
+			},
+		},
+		Logger: LoggerFunc(func(level LogLevel, message string, fields []LogField) {
+			events = append(events, message)
+		}),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, events, "Started SuperTokens with debug logging (supertokens.Init called)")
+
+	ResetForTest()
+	_, isDefault := sdkLogger.(defaultLogger)
+	assert.True(t, isDefault)
+}
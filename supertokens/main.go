@@ -55,18 +55,34 @@ func GetAllCORSHeaders() []string {
 	return instance.getAllCORSHeaders()
 }
 
+func GetUserCountWithContext(includeRecipeIds *[]string, userContext UserContext) (float64, error) {
+	return getUserCount(includeRecipeIds, userContext)
+}
+
+func GetUsersOldestFirstWithContext(paginationToken *string, limit *int, includeRecipeIds *[]string, userContext UserContext) (UserPaginationResult, error) {
+	return GetUsersWithSearchParamsWithContext("ASC", paginationToken, limit, includeRecipeIds, nil, userContext)
+}
+
+func GetUsersNewestFirstWithContext(paginationToken *string, limit *int, includeRecipeIds *[]string, userContext UserContext) (UserPaginationResult, error) {
+	return GetUsersWithSearchParamsWithContext("DESC", paginationToken, limit, includeRecipeIds, nil, userContext)
+}
+
+func DeleteUserWithContext(userId string, userContext UserContext) error {
+	return deleteUser(userId, userContext)
+}
+
 func GetUserCount(includeRecipeIds *[]string) (float64, error) {
-	return getUserCount(includeRecipeIds)
+	return GetUserCountWithContext(includeRecipeIds, &map[string]interface{}{})
 }
 
 func GetUsersOldestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
-	return GetUsersWithSearchParams("ASC", paginationToken, limit, includeRecipeIds, nil)
+	return GetUsersOldestFirstWithContext(paginationToken, limit, includeRecipeIds, &map[string]interface{}{})
 }
 
 func GetUsersNewestFirst(paginationToken *string, limit *int, includeRecipeIds *[]string) (UserPaginationResult, error) {
-	return GetUsersWithSearchParams("DESC", paginationToken, limit, includeRecipeIds, nil)
+	return GetUsersNewestFirstWithContext(paginationToken, limit, includeRecipeIds, &map[string]interface{}{})
 }
 
 func DeleteUser(userId string) error {
-	return deleteUser(userId)
+	return DeleteUserWithContext(userId, &map[string]interface{}{})
 }
@@ -17,6 +17,7 @@ package supertokens
 
 import (
 	"net/http"
+	"time"
 )
 
 type NormalisedAppinfo struct {
@@ -47,11 +48,57 @@ type TypeInput struct {
 	RecipeList            []Recipe
 	Telemetry             *bool
 	OnSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)
+	// Logger receives the SDK's log events. If not set, all events are printed to
+	// stdout only when the SUPERTOKENS_DEBUG environment variable is set.
+	Logger Logger
+	// Instrumentation receives the SDK's spans and metrics. If not set, nothing is recorded.
+	Instrumentation Instrumentation
 }
 
 type ConnectionInfo struct {
 	ConnectionURI string
 	APIKey        string
+	// Timeout is the default deadline for every request sent to the core. If the context
+	// carried by the userContext already has an earlier deadline, that one is used instead.
+	// Defaults to DefaultCoreRequestTimeout; a negative value disables the timeout.
+	Timeout time.Duration
+	// HTTPClient, if set, is used for all requests to the core, including the ones to the
+	// JWKS endpoint used for networkless session verification. Use this to
+	// configure custom TLS (for example a core behind an internal CA or mTLS), proxies
+	// or instrumentation.
+	HTTPClient *http.Client
+	// Transport is used with a default client if HTTPClient is not set. If neither is
+	// set, a pooled transport shared across all requests to the core is used.
+	Transport http.RoundTripper
+	// RetryPolicy controls how failed requests to the core are retried and when a
+	// failing core host is temporarily skipped. Defaults to DefaultRetryPolicy().
+	RetryPolicy *RetryPolicy
+}
+
+// RetryPolicy is the retry policy of the requests to the core. Its zero (or nil) fields
+// default to the ones of DefaultRetryPolicy().
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a single request, across all
+	// hosts. It is always at least the number of hosts in the ConnectionURI.
+	MaxAttempts int
+	// InitialBackoff and MaxBackoff bound the exponential backoff (with full jitter)
+	// applied between attempts. A negative InitialBackoff disables the backoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// AttemptTimeout limits a single attempt. A timed out attempt can be retried while
+	// the overall deadline of the request has not been reached. Zero means no limit.
+	AttemptTimeout time.Duration
+	// RetryableStatusCodes are the core response codes after which a request is retried.
+	// An empty, non nil, slice disables the retries based on the response code.
+	RetryableStatusCodes []int
+	// Requests that failed before reaching the core are always retried. Otherwise, only
+	// idempotent requests (GET, PUT, DELETE) are retried unless this is set to true.
+	RetryNonIdempotentRequests bool
+	// A host that fails FailuresBeforeEjection times in a row is skipped for
+	// EjectionDuration, unless all hosts are ejected. A negative FailuresBeforeEjection
+	// disables the ejection of hosts.
+	FailuresBeforeEjection int
+	EjectionDuration       time.Duration
 }
 
 type APIHandled struct {
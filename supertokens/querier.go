@@ -17,13 +17,14 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
 	"sync"
+	"time"
 )
 
 type Querier struct {
@@ -41,27 +42,41 @@ var (
 	QuerierAPIKey         *string
 	querierAPIVersion     string
 	querierLastTriedIndex int
+	querierTimeout        time.Duration
+	querierHTTPClient     *http.Client
 	querierLock           sync.Mutex
 	querierHostLock       sync.Mutex
 )
 
 func (q *Querier) GetQuerierAPIVersion() (string, error) {
+	ctx, cancel := getContextForCoreRequest(nil)
+	defer cancel()
+	return q.getQuerierAPIVersion(ctx)
+}
+
+// GetHTTPClient returns the client used for the requests to the core, so that the
+// other requests related to the core (like the ones to its JWKS endpoint) use the same
+// configuration.
+func (q *Querier) GetHTTPClient() *http.Client {
+	return querierHTTPClient
+}
+
+func (q *Querier) getQuerierAPIVersion(ctx context.Context) (string, error) {
 	querierLock.Lock()
 	defer querierLock.Unlock()
 	if querierAPIVersion != "" {
 		return querierAPIVersion, nil
 	}
-	response, err := q.sendRequestHelper(NormalisedURLPath{value: "/apiversion"}, func(url string) (*http.Response, error) {
-		req, err := http.NewRequest("GET", url, nil)
+	response, err := q.sendRequestHelper(ctx, http.MethodGet, NormalisedURLPath{value: "/apiversion"}, func(ctx context.Context, url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
 		if QuerierAPIKey != nil {
 			req.Header.Set("api-key", *QuerierAPIKey)
 		}
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return querierHTTPClient.Do(req)
+	})
 
 	if err != nil {
 		return "", err
@@ -95,24 +110,34 @@ func GetNewQuerierInstanceOrThrowError(rIDToCore string) (*Querier, error) {
 	return &Querier{RIDToCore: rIDToCore}, nil
 }
 
-func initQuerier(hosts []QuerierHost, APIKey string) {
+func initQuerier(hosts []QuerierHost, connectionInfo ConnectionInfo) {
 	if !querierInitCalled {
 		querierInitCalled = true
 		QuerierHosts = hosts
-		if APIKey != "" {
+		if connectionInfo.APIKey != "" {
+			APIKey := connectionInfo.APIKey
 			QuerierAPIKey = &APIKey
 		}
+		querierTimeout = connectionInfo.Timeout
+		if querierTimeout == 0 {
+			querierTimeout = DefaultCoreRequestTimeout
+		}
+		querierHTTPClient = getHTTPClientForQuerier(connectionInfo)
+		querierRetryPolicy = normaliseRetryPolicy(connectionInfo.RetryPolicy, len(hosts))
+		querierHostsHealth = make([]querierHostHealth, len(hosts))
 		querierAPIVersion = ""
 		querierLastTriedIndex = 0
 	}
 }
 
-func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+func (q *Querier) SendPostRequest(path string, data map[string]interface{}, userContext UserContext) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	ctx, cancel := getContextForCoreRequest(userContext)
+	defer cancel()
+	return q.sendRequestHelper(ctx, http.MethodPost, nP, func(ctx context.Context, url string) (*http.Response, error) {
 		if data == nil {
 			data = map[string]interface{}{}
 		}
@@ -120,12 +145,12 @@ func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
@@ -139,22 +164,23 @@ func (q *Querier) SendPostRequest(path string, data map[string]interface{}) (map
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return querierHTTPClient.Do(req)
+	})
 }
 
-func (q *Querier) SendDeleteRequest(path string, data map[string]interface{}, params map[string]string) (map[string]interface{}, error) {
+func (q *Querier) SendDeleteRequest(path string, data map[string]interface{}, params map[string]string, userContext UserContext) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	ctx, cancel := getContextForCoreRequest(userContext)
+	defer cancel()
+	return q.sendRequestHelper(ctx, http.MethodDelete, nP, func(ctx context.Context, url string) (*http.Response, error) {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "DELETE", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
@@ -166,7 +192,7 @@ func (q *Querier) SendDeleteRequest(path string, data map[string]interface{}, pa
 		}
 		req.URL.RawQuery = query.Encode()
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
@@ -180,18 +206,19 @@ func (q *Querier) SendDeleteRequest(path string, data map[string]interface{}, pa
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return querierHTTPClient.Do(req)
+	})
 }
 
-func (q *Querier) SendGetRequest(path string, params map[string]string) (map[string]interface{}, error) {
+func (q *Querier) SendGetRequest(path string, params map[string]string, userContext UserContext) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
-		req, err := http.NewRequest("GET", url, nil)
+	ctx, cancel := getContextForCoreRequest(userContext)
+	defer cancel()
+	return q.sendRequestHelper(ctx, http.MethodGet, nP, func(ctx context.Context, url string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -203,7 +230,7 @@ func (q *Querier) SendGetRequest(path string, params map[string]string) (map[str
 		}
 		req.URL.RawQuery = query.Encode()
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
@@ -215,27 +242,28 @@ func (q *Querier) SendGetRequest(path string, params map[string]string) (map[str
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return querierHTTPClient.Do(req)
+	})
 }
 
-func (q *Querier) SendPutRequest(path string, data map[string]interface{}) (map[string]interface{}, error) {
+func (q *Querier) SendPutRequest(path string, data map[string]interface{}, userContext UserContext) (map[string]interface{}, error) {
 	nP, err := NewNormalisedURLPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return q.sendRequestHelper(nP, func(url string) (*http.Response, error) {
+	ctx, cancel := getContextForCoreRequest(userContext)
+	defer cancel()
+	return q.sendRequestHelper(ctx, http.MethodPut, nP, func(ctx context.Context, url string) (*http.Response, error) {
 		jsonData, err := json.Marshal(data)
 		if err != nil {
 			return nil, err
 		}
-		req, err := http.NewRequest("PUT", url, bytes.NewBuffer(jsonData))
+		req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
 		if err != nil {
 			return nil, err
 		}
 
-		apiVerion, querierAPIVersionError := q.GetQuerierAPIVersion()
+		apiVerion, querierAPIVersionError := q.getQuerierAPIVersion(ctx)
 		if querierAPIVersionError != nil {
 			return nil, querierAPIVersionError
 		}
@@ -249,44 +277,150 @@ func (q *Querier) SendPutRequest(path string, data map[string]interface{}) (map[
 			req.Header.Set("rid", q.RIDToCore)
 		}
 
-		client := &http.Client{}
-		return client.Do(req)
-	}, len(QuerierHosts))
+		return querierHTTPClient.Do(req)
+	})
 }
 
-type httpRequestFunction func(url string) (*http.Response, error)
-
-func (q *Querier) sendRequestHelper(path NormalisedURLPath, httpRequest httpRequestFunction, numberOfTries int) (map[string]interface{}, error) {
-	if numberOfTries == 0 {
-		return nil, errors.New("no SuperTokens core available to query")
+// getHTTPClientForQuerier returns the client that is shared by all requests to the core.
+// A client or transport passed in the ConnectionInfo takes precedence over the default
+// pooled transport.
+func getHTTPClientForQuerier(connectionInfo ConnectionInfo) *http.Client {
+	if connectionInfo.HTTPClient != nil {
+		return connectionInfo.HTTPClient
+	}
+	transport := connectionInfo.Transport
+	if transport == nil {
+		transport = makeDefaultQuerierTransport()
 	}
+	return &http.Client{Transport: transport}
+}
+
+func makeDefaultQuerierTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = defaultQuerierMaxIdleConns
+	transport.MaxIdleConnsPerHost = defaultQuerierMaxIdleConnsPerHost
+	transport.IdleConnTimeout = defaultQuerierIdleConnTimeout
+	return transport
+}
 
-	querierHostLock.Lock()
-	currentDomain := QuerierHosts[querierLastTriedIndex].Domain.GetAsStringDangerous()
-	currentBasePath := QuerierHosts[querierLastTriedIndex].BasePath.GetAsStringDangerous()
-	querierLastTriedIndex = (querierLastTriedIndex + 1) % len(QuerierHosts)
-	querierHostLock.Unlock()
+type httpRequestFunction func(ctx context.Context, url string) (*http.Response, error)
 
-	resp, err := httpRequest(currentDomain + currentBasePath + path.GetAsStringDangerous())
+// getContextForCoreRequest returns the context that a request to the core should be
+// bound to. The configured querier timeout is applied unless the context already
+// carries an earlier deadline.
+func getContextForCoreRequest(userContext UserContext) (context.Context, context.CancelFunc) {
+	ctx := GetContextFromUserContext(userContext)
+	if querierTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, querierTimeout)
+}
 
+func (q *Querier) sendRequestHelper(ctx context.Context, method string, path NormalisedURLPath, httpRequest httpRequestFunction) (map[string]interface{}, error) {
+	fields := []LogField{HTTPMethodLogField(method), CorePathLogField(path.GetAsStringDangerous()), RecipeIDLogField(q.RIDToCore)}
+	ctx, endSpan := StartSpan(ctx, SpanNameCoreRequest, fields...)
+	result, err := q.sendRequestWithRetries(ctx, method, path, httpRequest)
+	endSpan(err)
 	if err != nil {
-		if strings.Contains(err.Error(), "connection refused") {
-			return q.sendRequestHelper(path, httpRequest, numberOfTries-1)
+		IncrementCounter(ctx, CounterNameCoreErrors, fields...)
+	}
+	return result, err
+}
+
+func (q *Querier) sendRequestWithRetries(ctx context.Context, method string, path NormalisedURLPath, httpRequest httpRequestFunction) (map[string]interface{}, error) {
+	if len(QuerierHosts) == 0 {
+		return nil, CoreUnavailableError{Msg: "no SuperTokens core available to query"}
+	}
+
+	policy := querierRetryPolicy
+	var lastErr error
+	lastErrWasBeforeReachingCore := false
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			err := sleepWithContext(ctx, policy.getBackoff(attempt))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
+
+		hostIndex := getNextQuerierHostIndex()
+		currentDomain := QuerierHosts[hostIndex].Domain.GetAsStringDangerous()
+		currentBasePath := QuerierHosts[hostIndex].BasePath.GetAsStringDangerous()
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if policy.AttemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, policy.AttemptTimeout)
+		}
+		result, statusCode, err := sendRequestToHost(attemptCtx, path, httpRequest, currentDomain+currentBasePath+path.GetAsStringDangerous())
+		cancel()
+		if err == nil {
+			markQuerierHostHealthy(hostIndex)
+			return result, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+
+		lastErr = err
+		lastErrWasBeforeReachingCore = statusCode == 0 && isErrorBeforeReachingCore(err)
+		isHostFailure := lastErrWasBeforeReachingCore ||
+			(statusCode == 0 && isTimeoutError(err)) ||
+			(statusCode != 0 && policy.isRetryableStatusCode(statusCode))
+		if !isHostFailure {
+			if statusCode >= 500 {
+				LogErrorMessage("querier: core returned an error for "+method+" "+path.GetAsStringDangerous(), RecipeIDLogField(q.RIDToCore), ErrorLogField(err))
+			}
+			return nil, err
+		}
+		markQuerierHostUnhealthy(hostIndex)
+		if !lastErrWasBeforeReachingCore && !policy.canRetryMethod(method) {
+			LogErrorMessage("querier: request to core failed and cannot be retried: "+method+" "+path.GetAsStringDangerous(), RecipeIDLogField(q.RIDToCore), ErrorLogField(err))
+			return nil, err
+		}
+		LogWarnMessage(fmt.Sprintf("querier: attempt %d of %d to %s failed", attempt+1, policy.MaxAttempts, currentDomain), RecipeIDLogField(q.RIDToCore), ErrorLogField(err))
+	}
+
+	LogErrorMessage("querier: all attempts to query the core failed for "+method+" "+path.GetAsStringDangerous(), RecipeIDLogField(q.RIDToCore), ErrorLogField(lastErr))
+	if lastErrWasBeforeReachingCore {
+		return nil, CoreUnavailableError{Msg: "no SuperTokens core available to query", Err: lastErr}
+	}
+	return nil, lastErr
+}
+
+// sendRequestToHost returns the parsed response, and the status code of the response if one was received
+func sendRequestToHost(ctx context.Context, path NormalisedURLPath, httpRequest httpRequestFunction, url string) (map[string]interface{}, int, error) {
+	resp, err := httpRequest(ctx, url)
+	if err != nil {
 		if resp != nil {
 			resp.Body.Close()
 		}
-		return nil, err
+		return nil, 0, err
 	}
 
 	defer resp.Body.Close()
 
 	body, readErr := ioutil.ReadAll(resp.Body)
 	if readErr != nil {
-		return nil, readErr
+		return nil, 0, readErr
 	}
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("SuperTokens core threw an error for a request to path: '%s' with status code: %v and message: %s", path.GetAsStringDangerous(), resp.StatusCode, body)
+		coreErr := CoreError{
+			StatusCode: resp.StatusCode,
+			Path:       path.GetAsStringDangerous(),
+			Message:    string(body),
+		}
+		if resp.Request != nil {
+			coreErr.Method = resp.Request.Method
+			coreErr.CDIVersion = resp.Request.Header.Get("cdi-version")
+		}
+		parsedBody := map[string]interface{}{}
+		if json.Unmarshal(body, &parsedBody) == nil {
+			coreErr.Body = parsedBody
+		}
+		return nil, resp.StatusCode, coreErr
 	}
 
 	finalResult := make(map[string]interface{})
@@ -294,9 +428,9 @@ func (q *Querier) sendRequestHelper(path NormalisedURLPath, httpRequest httpRequ
 	if jsonError != nil {
 		return map[string]interface{}{
 			"result": string(body),
-		}, nil
+		}, resp.StatusCode, nil
 	}
-	return finalResult, nil
+	return finalResult, resp.StatusCode, nil
 }
 
 func ResetQuerierForTest() {
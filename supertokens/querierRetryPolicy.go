@@ -0,0 +1,204 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRetryPolicy returns the policy used when ConnectionInfo.RetryPolicy is not set, whose fields are
+// used for the ones left unset in ConnectionInfo.RetryPolicy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:            3,
+		InitialBackoff:         50 * time.Millisecond,
+		MaxBackoff:             time.Second,
+		RetryableStatusCodes:   []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout},
+		FailuresBeforeEjection: 3,
+		EjectionDuration:       30 * time.Second,
+	}
+}
+
+type querierHostHealth struct {
+	consecutiveFailures int
+	ejectedUntil        time.Time
+}
+
+var (
+	querierRetryPolicy RetryPolicy
+	querierHostsHealth []querierHostHealth
+)
+
+func normaliseRetryPolicy(policy *RetryPolicy, numberOfHosts int) RetryPolicy {
+	defaultPolicy := DefaultRetryPolicy()
+	result := defaultPolicy
+	if policy != nil {
+		result = *policy
+		if result.MaxAttempts == 0 {
+			result.MaxAttempts = defaultPolicy.MaxAttempts
+		}
+		if result.InitialBackoff == 0 {
+			result.InitialBackoff = defaultPolicy.InitialBackoff
+		}
+		if result.MaxBackoff == 0 {
+			result.MaxBackoff = defaultPolicy.MaxBackoff
+		}
+		if result.RetryableStatusCodes == nil {
+			result.RetryableStatusCodes = defaultPolicy.RetryableStatusCodes
+		}
+		if result.FailuresBeforeEjection == 0 {
+			result.FailuresBeforeEjection = defaultPolicy.FailuresBeforeEjection
+		}
+		if result.EjectionDuration == 0 {
+			result.EjectionDuration = defaultPolicy.EjectionDuration
+		}
+	}
+	// the status codes are copied, so that changing the slice of the config after init has no effect
+	result.RetryableStatusCodes = append([]int{}, result.RetryableStatusCodes...)
+	if result.MaxAttempts < numberOfHosts {
+		result.MaxAttempts = numberOfHosts
+	}
+	if result.MaxAttempts < 1 {
+		result.MaxAttempts = 1
+	}
+	if result.MaxBackoff < result.InitialBackoff {
+		result.MaxBackoff = result.InitialBackoff
+	}
+	return result
+}
+
+// getBackoff returns the time to wait before the given (1 based) retry. We use
+// exponential backoff with full jitter to avoid many SDK instances retrying in lockstep.
+func (policy RetryPolicy) getBackoff(retry int) time.Duration {
+	if policy.InitialBackoff <= 0 {
+		return 0
+	}
+	backoff := policy.InitialBackoff
+	for i := 1; i < retry && backoff < policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+func (policy RetryPolicy) isRetryableStatusCode(statusCode int) bool {
+	for _, code := range policy.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (policy RetryPolicy) canRetryMethod(method string) bool {
+	if policy.RetryNonIdempotentRequests {
+		return true
+	}
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+// isErrorBeforeReachingCore returns true if the request could not have been received by
+// the core, which makes it safe to retry for any method.
+func isErrorBeforeReachingCore(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection refused")
+}
+
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func sleepWithContext(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// getNextQuerierHostIndex picks the next host in round robin order, skipping ejected hosts.
+// If all hosts are ejected, the one whose ejection ends first is used.
+func getNextQuerierHostIndex() int {
+	querierHostLock.Lock()
+	defer querierHostLock.Unlock()
+
+	if len(querierHostsHealth) != len(QuerierHosts) {
+		querierHostsHealth = make([]querierHostHealth, len(QuerierHosts))
+	}
+
+	now := time.Now()
+	result := -1
+	for i := 0; i < len(QuerierHosts); i++ {
+		index := (querierLastTriedIndex + i) % len(QuerierHosts)
+		if !querierHostsHealth[index].ejectedUntil.After(now) {
+			result = index
+			break
+		}
+		if result == -1 || querierHostsHealth[index].ejectedUntil.Before(querierHostsHealth[result].ejectedUntil) {
+			result = index
+		}
+	}
+	querierLastTriedIndex = (result + 1) % len(QuerierHosts)
+	return result
+}
+
+func markQuerierHostHealthy(index int) {
+	querierHostLock.Lock()
+	defer querierHostLock.Unlock()
+	if index < len(querierHostsHealth) {
+		querierHostsHealth[index] = querierHostHealth{}
+	}
+}
+
+func markQuerierHostUnhealthy(index int) {
+	querierHostLock.Lock()
+	defer querierHostLock.Unlock()
+	if index >= len(querierHostsHealth) {
+		return
+	}
+	health := &querierHostsHealth[index]
+	health.consecutiveFailures++
+	if querierRetryPolicy.FailuresBeforeEjection > 0 && health.consecutiveFailures >= querierRetryPolicy.FailuresBeforeEjection {
+		LogWarnMessage("querier: ejecting core host " + QuerierHosts[index].Domain.GetAsStringDangerous() + " because of repeated failures")
+		health.ejectedUntil = time.Now().Add(querierRetryPolicy.EjectionDuration)
+		health.consecutiveFailures = 0
+	}
+}
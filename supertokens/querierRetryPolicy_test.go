@@ -0,0 +1,203 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startFlakyCoreForTest(failures int32, statusCode int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":["2.20"]}`))
+			return
+		}
+		if atomic.AddInt32(&calls, 1) <= failures {
+			rw.WriteHeader(statusCode)
+			return
+		}
+		rw.Write([]byte(`{"status":"OK"}`))
+	}))
+	return server, &calls
+}
+
+func initQuerierWithHostsForTest(t *testing.T, uris []string, connectionInfo ConnectionInfo) {
+	ResetQuerierForTest()
+	hosts := []QuerierHost{}
+	for _, uri := range uris {
+		domain, err := NewNormalisedURLDomain(uri)
+		assert.NoError(t, err)
+		basePath, err := NewNormalisedURLPath("")
+		assert.NoError(t, err)
+		hosts = append(hosts, QuerierHost{Domain: domain, BasePath: basePath})
+	}
+	initQuerier(hosts, connectionInfo)
+}
+
+func TestRetriesIdempotentRequestsOnRetryableStatusCode(t *testing.T) {
+	server, calls := startFlakyCoreForTest(2, http.StatusServiceUnavailable)
+	defer server.Close()
+	initQuerierWithHostsForTest(t, []string{server.URL}, ConnectionInfo{RetryPolicy: &RetryPolicy{
+		MaxAttempts:          3,
+		InitialBackoff:       time.Millisecond,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	response, err := q.SendGetRequest("/test", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response["status"])
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+}
+
+func TestDoesNotRetryNonIdempotentRequestsOnRetryableStatusCode(t *testing.T) {
+	server, calls := startFlakyCoreForTest(1, http.StatusServiceUnavailable)
+	defer server.Close()
+	initQuerierWithHostsForTest(t, []string{server.URL}, ConnectionInfo{RetryPolicy: &RetryPolicy{
+		MaxAttempts:          3,
+		RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+	}})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendPostRequest("/test", nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	coreErr := CoreError{}
+	assert.True(t, errors.As(err, &coreErr))
+	assert.Equal(t, http.StatusServiceUnavailable, coreErr.StatusCode)
+	assert.Equal(t, http.MethodPost, coreErr.Method)
+	assert.Equal(t, "/test", coreErr.Path)
+	assert.Equal(t, "2.20", coreErr.CDIVersion)
+}
+
+func TestDoesNotRetryOnNonRetryableStatusCode(t *testing.T) {
+	server, calls := startFlakyCoreForTest(1, http.StatusBadRequest)
+	defer server.Close()
+	initQuerierWithHostsForTest(t, []string{server.URL}, ConnectionInfo{})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendGetRequest("/test", nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+func TestFailsOverToNextHostWhenCoreIsUnreachable(t *testing.T) {
+	deadServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	deadServer.Close()
+	server, calls := startFlakyCoreForTest(0, http.StatusOK)
+	defer server.Close()
+	initQuerierWithHostsForTest(t, []string{deadServer.URL, server.URL}, ConnectionInfo{})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err = q.SendPostRequest("/test", nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+}
+
+func TestReturnsErrorWhenNoCoreIsReachable(t *testing.T) {
+	deadServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	deadServer.Close()
+	initQuerierWithHostsForTest(t, []string{deadServer.URL}, ConnectionInfo{RetryPolicy: &RetryPolicy{MaxAttempts: 2}})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.SendGetRequest("/test", nil, nil)
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "no SuperTokens core available to query"))
+	assert.True(t, errors.As(err, &CoreUnavailableError{}))
+}
+
+func TestFailingHostIsEjected(t *testing.T) {
+	failingServer, failingCalls := startFlakyCoreForTest(100, http.StatusBadGateway)
+	defer failingServer.Close()
+	server, calls := startFlakyCoreForTest(0, http.StatusOK)
+	defer server.Close()
+	initQuerierWithHostsForTest(t, []string{failingServer.URL, server.URL}, ConnectionInfo{RetryPolicy: &RetryPolicy{
+		MaxAttempts:            2,
+		RetryableStatusCodes:   []int{http.StatusBadGateway},
+		FailuresBeforeEjection: 2,
+		EjectionDuration:       time.Minute,
+	}})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	_, err = q.GetQuerierAPIVersion()
+	assert.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = q.SendGetRequest("/test", nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(failingCalls))
+	assert.Equal(t, int32(10), atomic.LoadInt32(calls))
+}
+
+func TestBackoffIsBounded(t *testing.T) {
+	policy := normaliseRetryPolicy(&RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 40 * time.Millisecond}, 1)
+	for retry := 1; retry < 10; retry++ {
+		backoff := policy.getBackoff(retry)
+		assert.True(t, backoff >= 0)
+		assert.True(t, backoff <= 40*time.Millisecond)
+	}
+	assert.Equal(t, DefaultRetryPolicy().MaxAttempts, policy.MaxAttempts)
+	assert.Equal(t, 3, normaliseRetryPolicy(&RetryPolicy{MaxAttempts: 1}, 3).MaxAttempts)
+}
+
+func TestUnsetRetryPolicyFieldsAreDefaulted(t *testing.T) {
+	policy := normaliseRetryPolicy(&RetryPolicy{MaxAttempts: 5, RetryNonIdempotentRequests: true}, 1)
+	expectedPolicy := DefaultRetryPolicy()
+	expectedPolicy.MaxAttempts = 5
+	expectedPolicy.RetryNonIdempotentRequests = true
+	assert.Equal(t, expectedPolicy, policy)
+
+	policy = normaliseRetryPolicy(&RetryPolicy{InitialBackoff: -1, RetryableStatusCodes: []int{}, FailuresBeforeEjection: -1}, 1)
+	assert.Equal(t, time.Duration(0), policy.getBackoff(1))
+	assert.False(t, policy.isRetryableStatusCode(http.StatusServiceUnavailable))
+	assert.Equal(t, -1, policy.FailuresBeforeEjection)
+	assert.Equal(t, DefaultRetryPolicy().MaxAttempts, policy.MaxAttempts)
+}
+
+func TestNormalisedRetryPolicyDoesNotShareStatusCodes(t *testing.T) {
+	policy := normaliseRetryPolicy(nil, 1)
+	policy.RetryableStatusCodes[0] = http.StatusInternalServerError
+	assert.Equal(t, DefaultRetryPolicy(), normaliseRetryPolicy(nil, 1))
+
+	retryableStatusCodes := []int{http.StatusServiceUnavailable}
+	policy = normaliseRetryPolicy(&RetryPolicy{RetryableStatusCodes: retryableStatusCodes}, 1)
+	retryableStatusCodes[0] = http.StatusInternalServerError
+	assert.Equal(t, []int{http.StatusServiceUnavailable}, policy.RetryableStatusCodes)
+}
@@ -0,0 +1,175 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startSlowCoreForTest(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":["2.20"]}`))
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		rw.Write([]byte(`{"status":"OK"}`))
+	}))
+}
+
+func initQuerierForTest(t *testing.T, connectionInfo ConnectionInfo) {
+	ResetQuerierForTest()
+	domain, err := NewNormalisedURLDomain(connectionInfo.ConnectionURI)
+	assert.NoError(t, err)
+	basePath, err := NewNormalisedURLPath("")
+	assert.NoError(t, err)
+	initQuerier([]QuerierHost{{Domain: domain, BasePath: basePath}}, connectionInfo)
+}
+
+func TestQuerierAppliesDefaultTimeout(t *testing.T) {
+	server := startSlowCoreForTest(time.Second)
+	defer server.Close()
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL, Timeout: 50 * time.Millisecond})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+
+	_, err = q.SendGetRequest("/test", nil, &map[string]interface{}{})
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestQuerierRespectsContextInUserContext(t *testing.T) {
+	server := startSlowCoreForTest(time.Second)
+	defer server.Close()
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL, Timeout: -1})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	_, err = q.SendPostRequest("/test", nil, MakeDefaultUserContextFromContext(ctx))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	response, err := q.SendPostRequest("/test", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", response["status"])
+}
+
+func TestGetContextFromUserContext(t *testing.T) {
+	assert.Equal(t, context.Background(), GetContextFromUserContext(nil))
+
+	type ctxKey string
+	reqCtx := context.WithValue(context.Background(), ctxKey("from"), "request")
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, "http://localhost", nil)
+	assert.NoError(t, err)
+	userContext := MakeDefaultUserContextFromAPI(req)
+	assert.Equal(t, "request", GetContextFromUserContext(userContext).Value(ctxKey("from")))
+
+	explicitCtx := context.WithValue(context.Background(), ctxKey("from"), "explicit")
+	userContext = SetContextInUserContext(userContext, explicitCtx)
+	assert.Equal(t, "explicit", GetContextFromUserContext(userContext).Value(ctxKey("from")))
+	assert.Equal(t, req, (*userContext)["_default"].(map[string]interface{})["request"])
+}
+
+type countingRoundTripper struct {
+	count int
+}
+
+func (c *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.count++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestQuerierUsesCustomTransport(t *testing.T) {
+	server := startSlowCoreForTest(0)
+	defer server.Close()
+	transport := &countingRoundTripper{}
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL, Transport: transport})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+
+	_, err = q.SendGetRequest("/test", nil, nil)
+	assert.NoError(t, err)
+	_, err = q.SendGetRequest("/test", nil, nil)
+	assert.NoError(t, err)
+
+	// one request for the api version and one for each call
+	assert.Equal(t, 3, transport.count)
+}
+
+func TestQuerierUsesCustomHTTPClient(t *testing.T) {
+	server := startSlowCoreForTest(0)
+	defer server.Close()
+	transport := &countingRoundTripper{}
+	httpClient := &http.Client{Transport: transport}
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL, HTTPClient: httpClient, Transport: http.DefaultTransport})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+	assert.Equal(t, httpClient, q.GetHTTPClient())
+
+	_, err = q.SendPutRequest("/test", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, transport.count)
+}
+
+func TestQuerierReturnsCoreErrorWithParsedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apiversion" {
+			rw.Write([]byte(`{"versions":["2.20"]}`))
+			return
+		}
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(`{"message":"invalid input"}`))
+	}))
+	defer server.Close()
+	initQuerierForTest(t, ConnectionInfo{ConnectionURI: server.URL})
+	defer ResetQuerierForTest()
+
+	q, err := GetNewQuerierInstanceOrThrowError("")
+	assert.NoError(t, err)
+
+	_, err = q.SendDeleteRequest("/test", nil, nil, nil)
+	coreErr := CoreError{}
+	assert.True(t, errors.As(err, &coreErr))
+	assert.True(t, coreErr.IsBadRequest())
+	assert.False(t, coreErr.IsUnauthorised())
+	assert.Equal(t, http.MethodDelete, coreErr.Method)
+	assert.Equal(t, "invalid input", coreErr.Body["message"])
+	assert.Equal(t, "SuperTokens core threw an error for a request to path: '/test' with status code: 400 and message: {\"message\":\"invalid input\"}", err.Error())
+}
@@ -0,0 +1,66 @@
+//go:build go1.21
+// +build go1.21
+
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that sends the SDK's log events to a log/slog logger.
+// The fields of each event are passed as attributes, along with the SDK version.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Log(level LogLevel, message string, fields []LogField) {
+	slogLevel := getSlogLevel(level)
+	if !l.logger.Enabled(context.Background(), slogLevel) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, len(fields)+1)
+	for _, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			attrs = append(attrs, slog.String(field.Key, err.Error()))
+		} else {
+			attrs = append(attrs, slog.Any(field.Key, field.Value))
+		}
+	}
+	attrs = append(attrs, slog.String("sdkVer", VERSION))
+	l.logger.LogAttrs(context.Background(), slogLevel, message, attrs...)
+}
+
+func getSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelInfo:
+		return slog.LevelInfo
+	case LogLevelWarn:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
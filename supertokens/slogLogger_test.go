@@ -0,0 +1,47 @@
+//go:build go1.21
+// +build go1.21
+
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package supertokens
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	setLogger(NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))))
+	defer setLogger(nil)
+
+	LogDebugMessage("filtered out by the handler")
+	assert.Empty(t, buf.String())
+
+	LogWarnMessage("token theft detected", SessionHandleLogField("handle"), ErrorLogField(errors.New("some error")))
+	record := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "token theft detected", record["msg"])
+	assert.Equal(t, "handle", record[LogKeySessionHandle])
+	assert.Equal(t, "some error", record[LogKeyError])
+	assert.Equal(t, VERSION, record["sdkVer"])
+}
@@ -16,6 +16,7 @@
 package supertokens
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -42,11 +43,21 @@ func supertokensInit(config TypeInput) error {
 		return nil
 	}
 
+	setLogger(config.Logger)
+	setInstrumentation(config.Instrumentation)
+
 	superTokens := &superTokens{}
 
-	superTokens.OnSuperTokensAPIError = defaultOnSuperTokensAPIError
+	onSuperTokensAPIError := defaultOnSuperTokensAPIError
 	if config.OnSuperTokensAPIError != nil {
-		superTokens.OnSuperTokensAPIError = config.OnSuperTokensAPIError
+		onSuperTokensAPIError = config.OnSuperTokensAPIError
+	}
+	superTokens.OnSuperTokensAPIError = func(err error, req *http.Request, res http.ResponseWriter) {
+		if onError := getAPIErrorHandlerFromContext(req.Context()); onError != nil {
+			onError(err, req, res)
+			return
+		}
+		onSuperTokensAPIError(err, req, res)
 	}
 
 	LogDebugMessage("Started SuperTokens with debug logging (supertokens.Init called)")
@@ -78,7 +89,7 @@ func supertokensInit(config TypeInput) error {
 					BasePath: basePath,
 				})
 			}
-			initQuerier(hosts, config.Supertokens.APIKey)
+			initQuerier(hosts, *config.Supertokens)
 			superTokens.SuperTokens = *config.Supertokens
 		} else {
 			return errors.New("please provide 'ConnectionURI' value. If you do not want to provide a connection URI, then set config.Supertokens to nil")
@@ -109,6 +120,20 @@ func defaultOnSuperTokensAPIError(err error, req *http.Request, res http.Respons
 	http.Error(res, err.Error(), 500)
 }
 
+type apiErrorHandlerContextKey struct{}
+
+// WithAPIErrorHandler returns a copy of ctx that makes the middleware and the recipes pass the
+// errors they cannot handle to onError instead of OnSuperTokensAPIError, for requests carrying it.
+// Framework adapters use it to send these errors through the framework's own error handling.
+func WithAPIErrorHandler(ctx context.Context, onError func(err error, req *http.Request, res http.ResponseWriter)) context.Context {
+	return context.WithValue(ctx, apiErrorHandlerContextKey{}, onError)
+}
+
+func getAPIErrorHandlerFromContext(ctx context.Context) func(err error, req *http.Request, res http.ResponseWriter) {
+	onError, _ := ctx.Value(apiErrorHandlerContextKey{}).(func(err error, req *http.Request, res http.ResponseWriter))
+	return onError
+}
+
 func GetInstanceOrThrowError() (*superTokens, error) {
 	if superTokensInstance != nil {
 		return superTokensInstance, nil
@@ -180,9 +205,14 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 
 			LogDebugMessage("middleware: Request being handled by recipe. ID is: " + *id)
 
-			apiErr := matchedRecipe.HandleAPIRequest(*id, r, dw, theirHandler.ServeHTTP, path, method)
+			ctx, endSpan := StartSpan(r.Context(), SpanNameMiddleware, RecipeIDLogField(matchedRecipe.GetRecipeID()), APIIDLogField(*id))
+			apiErr := matchedRecipe.HandleAPIRequest(*id, r.WithContext(ctx), dw, theirHandler.ServeHTTP, path, method)
+			endSpan(apiErr)
 			if apiErr != nil {
 				apiErr = s.errorHandler(apiErr, r, dw)
+				if apiErr != nil {
+					LogErrorMessage("middleware: API returned an unhandled error", RecipeIDLogField(matchedRecipe.GetRecipeID()), APIIDLogField(*id), ErrorLogField(apiErr))
+				}
 				if apiErr != nil && !dw.IsDone() {
 					s.OnSuperTokensAPIError(apiErr, r, dw)
 				}
@@ -203,9 +233,14 @@ func (s *superTokens) middleware(theirHandler http.Handler) http.Handler {
 
 				if id != nil {
 					LogDebugMessage("middleware: Request being handled by recipe. ID is: " + *id)
-					err := recipeModule.HandleAPIRequest(*id, r, dw, theirHandler.ServeHTTP, path, method)
+					ctx, endSpan := StartSpan(r.Context(), SpanNameMiddleware, RecipeIDLogField(recipeModule.GetRecipeID()), APIIDLogField(*id))
+					err := recipeModule.HandleAPIRequest(*id, r.WithContext(ctx), dw, theirHandler.ServeHTTP, path, method)
+					endSpan(err)
 					if err != nil {
 						err = s.errorHandler(err, r, dw)
+						if err != nil {
+							LogErrorMessage("middleware: API returned an unhandled error", RecipeIDLogField(recipeModule.GetRecipeID()), APIIDLogField(*id), ErrorLogField(err))
+						}
 						if err != nil && !dw.IsDone() {
 							s.OnSuperTokensAPIError(err, r, dw)
 						}
@@ -277,6 +312,10 @@ type UserPaginationResult struct {
 
 // TODO: Add tests
 func GetUsersWithSearchParams(timeJoinedOrder string, paginationToken *string, limit *int, includeRecipeIds *[]string, searchParams map[string]string) (UserPaginationResult, error) {
+	return GetUsersWithSearchParamsWithContext(timeJoinedOrder, paginationToken, limit, includeRecipeIds, searchParams, &map[string]interface{}{})
+}
+
+func GetUsersWithSearchParamsWithContext(timeJoinedOrder string, paginationToken *string, limit *int, includeRecipeIds *[]string, searchParams map[string]string, userContext UserContext) (UserPaginationResult, error) {
 
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
@@ -299,7 +338,7 @@ func GetUsersWithSearchParams(timeJoinedOrder string, paginationToken *string, l
 		requestBody["includeRecipeIds"] = strings.Join((*includeRecipeIds)[:], ",")
 	}
 
-	resp, err := querier.SendGetRequest("/users", requestBody)
+	resp, err := querier.SendGetRequest("/users", requestBody, userContext)
 
 	if err != nil {
 		return UserPaginationResult{}, err
@@ -322,7 +361,7 @@ func GetUsersWithSearchParams(timeJoinedOrder string, paginationToken *string, l
 }
 
 // TODO: Add tests
-func getUserCount(includeRecipeIds *[]string) (float64, error) {
+func getUserCount(includeRecipeIds *[]string, userContext UserContext) (float64, error) {
 
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
@@ -335,7 +374,7 @@ func getUserCount(includeRecipeIds *[]string) (float64, error) {
 		requestBody["includeRecipeIds"] = strings.Join((*includeRecipeIds)[:], ",")
 	}
 
-	resp, err := querier.SendGetRequest("/users/count", requestBody)
+	resp, err := querier.SendGetRequest("/users/count", requestBody, userContext)
 
 	if err != nil {
 		return -1, err
@@ -344,7 +383,7 @@ func getUserCount(includeRecipeIds *[]string) (float64, error) {
 	return resp["count"].(float64), nil
 }
 
-func deleteUser(userId string) error {
+func deleteUser(userId string, userContext UserContext) error {
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return err
@@ -358,7 +397,7 @@ func deleteUser(userId string) error {
 	if MaxVersion(cdiVersion, "2.10") == cdiVersion {
 		_, err = querier.SendPostRequest("/user/remove", map[string]interface{}{
 			"userId": userId,
-		})
+		}, userContext)
 
 		if err != nil {
 			return err
@@ -372,6 +411,8 @@ func deleteUser(userId string) error {
 
 func ResetForTest() {
 	ResetQuerierForTest()
+	setLogger(nil)
+	setInstrumentation(nil)
 	superTokensInstance = nil
 }
 
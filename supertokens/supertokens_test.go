@@ -16,6 +16,9 @@
 package supertokens
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -146,3 +149,57 @@ func TestOverride2(t *testing.T) {
 	assert.Equal(t, 2, m)
 
 }
+
+func TestAPIErrorHandlerInContextOverridesOnSuperTokensAPIError(t *testing.T) {
+	onSuperTokensAPIErrorCalls := 0
+	err := Init(TypeInput{
+		Supertokens: &ConnectionInfo{ConnectionURI: "http://localhost:8080"},
+		AppInfo: AppInfo{
+			APIDomain:     "api.supertokens.io",
+			AppName:       "SuperTokens",
+			WebsiteDomain: "supertokens.io",
+		},
+		RecipeList: []Recipe{
+			func(appInfo NormalisedAppinfo, onSuperTokensAPIError func(err error, req *http.Request, res http.ResponseWriter)) (*RecipeModule, error) {
+				path, err := NewNormalisedURLPath("/test")
+				if err != nil {
+					return nil, err
+				}
+				module := MakeRecipeModule("test", appInfo, func(id string, req *http.Request, res http.ResponseWriter, theirHandler http.HandlerFunc, path NormalisedURLPath, method string) error {
+					return errors.New("test error")
+				}, func() []string {
+					return []string{}
+				}, func() ([]APIHandled, error) {
+					return []APIHandled{{PathWithoutAPIBasePath: path, Method: http.MethodPost, ID: "testAPI"}}, nil
+				}, nil, func(err error, req *http.Request, res http.ResponseWriter) (bool, error) {
+					return false, nil
+				}, onSuperTokensAPIError)
+				return &module, nil
+			},
+		},
+		OnSuperTokensAPIError: func(err error, req *http.Request, res http.ResponseWriter) {
+			onSuperTokensAPIErrorCalls++
+			res.WriteHeader(http.StatusInternalServerError)
+		},
+	})
+	assert.NoError(t, err)
+	defer ResetForTest()
+
+	handler := Middleware(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/test", nil))
+	assert.Equal(t, 1, onSuperTokensAPIErrorCalls)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	var errorInHandler error
+	req := httptest.NewRequest(http.MethodPost, "/auth/test", nil)
+	req = req.WithContext(WithAPIErrorHandler(req.Context(), func(err error, req *http.Request, res http.ResponseWriter) {
+		errorInHandler = err
+		res.WriteHeader(http.StatusTeapot)
+	}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, 1, onSuperTokensAPIErrorCalls)
+	assert.EqualError(t, errorInHandler, "test error")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
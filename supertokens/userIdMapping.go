@@ -21,7 +21,7 @@ type CreateUserIdMappingResult struct {
 	}
 }
 
-func CreateUserIdMapping(supertokensUserId string, externalUserId string, externalUserIdInfo *string, force *bool) (CreateUserIdMappingResult, error) {
+func CreateUserIdMappingWithContext(supertokensUserId string, externalUserId string, externalUserIdInfo *string, force *bool, userContext UserContext) (CreateUserIdMappingResult, error) {
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return CreateUserIdMappingResult{}, err
@@ -44,7 +44,7 @@ func CreateUserIdMapping(supertokensUserId string, externalUserId string, extern
 	if externalUserIdInfo != nil {
 		data["externalUserIdInfo"] = *externalUserIdInfo
 	}
-	resp, err := querier.SendPostRequest("/recipe/userid/map", data)
+	resp, err := querier.SendPostRequest("/recipe/userid/map", data, userContext)
 	if err != nil {
 		return CreateUserIdMappingResult{}, err
 	}
@@ -78,7 +78,7 @@ type GetUserIdMappingResult struct {
 	UnknownMappingError *struct{}
 }
 
-func GetUserIdMapping(userId string, userIdType *UserIdType) (GetUserIdMappingResult, error) {
+func GetUserIdMappingWithContext(userId string, userIdType *UserIdType, userContext UserContext) (GetUserIdMappingResult, error) {
 
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
@@ -98,7 +98,7 @@ func GetUserIdMapping(userId string, userIdType *UserIdType) (GetUserIdMappingRe
 	if userIdType != nil {
 		data["userIdType"] = string(*userIdType)
 	}
-	resp, err := querier.SendGetRequest("/recipe/userid/map", data)
+	resp, err := querier.SendGetRequest("/recipe/userid/map", data, userContext)
 	if err != nil {
 		return GetUserIdMappingResult{}, err
 	}
@@ -131,7 +131,7 @@ type DeleteUserIdMappingResult struct {
 	}
 }
 
-func DeleteUserIdMapping(userId string, userIdType *UserIdType, force *bool) (DeleteUserIdMappingResult, error) {
+func DeleteUserIdMappingWithContext(userId string, userIdType *UserIdType, force *bool, userContext UserContext) (DeleteUserIdMappingResult, error) {
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return DeleteUserIdMappingResult{}, err
@@ -153,7 +153,7 @@ func DeleteUserIdMapping(userId string, userIdType *UserIdType, force *bool) (De
 	if force != nil {
 		data["force"] = *force
 	}
-	resp, err := querier.SendPostRequest("/recipe/userid/map/remove", data)
+	resp, err := querier.SendPostRequest("/recipe/userid/map/remove", data, userContext)
 	if err != nil {
 		return DeleteUserIdMappingResult{}, err
 	}
@@ -169,7 +169,7 @@ type UpdateOrDeleteUserIdMappingInfoResult struct {
 	UnknownMappingError *struct{}
 }
 
-func UpdateOrDeleteUserIdMappingInfo(userId string, userIdType *UserIdType, externalUserIdInfo *string) (UpdateOrDeleteUserIdMappingInfoResult, error) {
+func UpdateOrDeleteUserIdMappingInfoWithContext(userId string, userIdType *UserIdType, externalUserIdInfo *string, userContext UserContext) (UpdateOrDeleteUserIdMappingInfoResult, error) {
 	querier, err := GetNewQuerierInstanceOrThrowError("")
 	if err != nil {
 		return UpdateOrDeleteUserIdMappingInfoResult{}, err
@@ -190,7 +190,7 @@ func UpdateOrDeleteUserIdMappingInfo(userId string, userIdType *UserIdType, exte
 		data["userIdType"] = string(*userIdType)
 	}
 
-	resp, err := querier.SendPutRequest("/recipe/userid/external-user-id-info", data)
+	resp, err := querier.SendPutRequest("/recipe/userid/external-user-id-info", data, userContext)
 	if err != nil {
 		return UpdateOrDeleteUserIdMappingInfoResult{}, err
 	}
@@ -205,3 +205,19 @@ func UpdateOrDeleteUserIdMappingInfo(userId string, userIdType *UserIdType, exte
 		}, nil
 	}
 }
+
+func CreateUserIdMapping(supertokensUserId string, externalUserId string, externalUserIdInfo *string, force *bool) (CreateUserIdMappingResult, error) {
+	return CreateUserIdMappingWithContext(supertokensUserId, externalUserId, externalUserIdInfo, force, &map[string]interface{}{})
+}
+
+func GetUserIdMapping(userId string, userIdType *UserIdType) (GetUserIdMappingResult, error) {
+	return GetUserIdMappingWithContext(userId, userIdType, &map[string]interface{}{})
+}
+
+func DeleteUserIdMapping(userId string, userIdType *UserIdType, force *bool) (DeleteUserIdMappingResult, error) {
+	return DeleteUserIdMappingWithContext(userId, userIdType, force, &map[string]interface{}{})
+}
+
+func UpdateOrDeleteUserIdMappingInfo(userId string, userIdType *UserIdType, externalUserIdInfo *string) (UpdateOrDeleteUserIdMappingInfoResult, error) {
+	return UpdateOrDeleteUserIdMappingInfoWithContext(userId, userIdType, externalUserIdInfo, &map[string]interface{}{})
+}
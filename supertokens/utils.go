@@ -17,6 +17,7 @@ package supertokens
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -284,6 +285,47 @@ func MakeDefaultUserContextFromAPI(r *http.Request) UserContext {
 	}
 }
 
+// MakeDefaultUserContextFromContext creates a userContext that carries ctx, so that
+// any request to the core made with it respects ctx's deadline and cancellation.
+func MakeDefaultUserContextFromContext(ctx context.Context) UserContext {
+	return SetContextInUserContext(&map[string]interface{}{}, ctx)
+}
+
+// SetContextInUserContext attaches ctx to an existing userContext and returns it.
+// If userContext is nil, a new one is created.
+func SetContextInUserContext(userContext UserContext, ctx context.Context) UserContext {
+	if userContext == nil {
+		userContext = &map[string]interface{}{}
+	}
+	defaultValues, ok := (*userContext)["_default"].(map[string]interface{})
+	if !ok {
+		defaultValues = map[string]interface{}{}
+		(*userContext)["_default"] = defaultValues
+	}
+	defaultValues["context"] = ctx
+	return userContext
+}
+
+// GetContextFromUserContext returns the context attached to the userContext. If none was
+// attached explicitly, the context of the request (as added by MakeDefaultUserContextFromAPI)
+// is used, and context.Background() otherwise.
+func GetContextFromUserContext(userContext UserContext) context.Context {
+	if userContext == nil {
+		return context.Background()
+	}
+	defaultValues, ok := (*userContext)["_default"].(map[string]interface{})
+	if !ok {
+		return context.Background()
+	}
+	if ctx, ok := defaultValues["context"].(context.Context); ok && ctx != nil {
+		return ctx
+	}
+	if req, ok := defaultValues["request"].(*http.Request); ok && req != nil {
+		return req.Context()
+	}
+	return context.Background()
+}
+
 func GetTopLevelDomainForSameSiteResolution(URL string) (string, error) {
 	urlObj, err := url.Parse(URL)
 	if err != nil {
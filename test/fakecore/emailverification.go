@@ -0,0 +1,97 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+func getEmailVerificationInfo(req coreRequest) (emailVerificationToken, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return emailVerificationToken{}, err
+	}
+	email, err := req.getRequiredString("email")
+	if err != nil {
+		return emailVerificationToken{}, err
+	}
+	return emailVerificationToken{userID: userID, email: email}, nil
+}
+
+func (s *Server) createEmailVerificationToken(req coreRequest) (map[string]interface{}, error) {
+	info, err := getEmailVerificationInfo(req)
+	if err != nil {
+		return nil, err
+	}
+	if s.store.verifiedEmails[info] {
+		return statusResponse("EMAIL_ALREADY_VERIFIED_ERROR"), nil
+	}
+	token := generateRandomString(32)
+	s.store.emailVerificationTokens[token] = info
+	response := okResponse()
+	response["token"] = token
+	return response, nil
+}
+
+func (s *Server) verifyEmailUsingToken(req coreRequest) (map[string]interface{}, error) {
+	token, err := req.getRequiredString("token")
+	if err != nil {
+		return nil, err
+	}
+	info, ok := s.store.emailVerificationTokens[token]
+	if !ok {
+		return statusResponse("EMAIL_VERIFICATION_INVALID_TOKEN_ERROR"), nil
+	}
+	s.removeEmailVerificationTokens(info)
+	s.store.verifiedEmails[info] = true
+
+	response := okResponse()
+	response["userId"] = info.userID
+	response["email"] = info.email
+	return response, nil
+}
+
+func (s *Server) isEmailVerified(req coreRequest) (map[string]interface{}, error) {
+	info, err := getEmailVerificationInfo(req)
+	if err != nil {
+		return nil, err
+	}
+	response := okResponse()
+	response["isVerified"] = s.store.verifiedEmails[info]
+	return response, nil
+}
+
+func (s *Server) revokeEmailVerificationTokens(req coreRequest) (map[string]interface{}, error) {
+	info, err := getEmailVerificationInfo(req)
+	if err != nil {
+		return nil, err
+	}
+	s.removeEmailVerificationTokens(info)
+	return okResponse(), nil
+}
+
+func (s *Server) unverifyEmail(req coreRequest) (map[string]interface{}, error) {
+	info, err := getEmailVerificationInfo(req)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.store.verifiedEmails, info)
+	return okResponse(), nil
+}
+
+func (s *Server) removeEmailVerificationTokens(info emailVerificationToken) {
+	for token, tokenInfo := range s.store.emailVerificationTokens {
+		if tokenInfo == info {
+			delete(s.store.emailVerificationTokens, token)
+		}
+	}
+}
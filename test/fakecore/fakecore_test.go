@@ -0,0 +1,379 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supertokens/supertokens-golang/recipe/emailpassword"
+	"github.com/supertokens/supertokens-golang/recipe/emailverification"
+	"github.com/supertokens/supertokens-golang/recipe/emailverification/evmodels"
+	"github.com/supertokens/supertokens-golang/recipe/jwt"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless"
+	"github.com/supertokens/supertokens-golang/recipe/passwordless/plessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/session"
+	"github.com/supertokens/supertokens-golang/recipe/session/sessmodels"
+	"github.com/supertokens/supertokens-golang/recipe/usermetadata"
+	"github.com/supertokens/supertokens-golang/recipe/userroles"
+	"github.com/supertokens/supertokens-golang/supertokens"
+	"github.com/supertokens/supertokens-golang/test/unittesting"
+)
+
+func resetAll() {
+	supertokens.ResetForTest()
+	emailpassword.ResetForTest()
+	emailverification.ResetForTest()
+	jwt.ResetForTest()
+	passwordless.ResetForTest()
+	session.ResetForTest()
+	usermetadata.ResetForTest()
+	userroles.ResetForTest()
+}
+
+func supertokensInitForTest(t *testing.T, core *Server, recipes ...supertokens.Recipe) *httptest.Server {
+	return supertokensInitWithInputForTest(t, core, supertokens.TypeInput{RecipeList: recipes})
+}
+
+// supertokensInitWithInputForTest initialises the SDK with config, pointing it to core, and returns a server
+// handling the APIs of the SDK and /verify, which responds with the user id of the session of the request
+func supertokensInitWithInputForTest(t *testing.T, core *Server, config supertokens.TypeInput) *httptest.Server {
+	resetAll()
+	core.InitForTest(t, config)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", session.VerifySession(nil, func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(session.GetSessionFromRequestContext(r.Context()).GetUserID()))
+	}))
+	testServer := httptest.NewServer(supertokens.Middleware(mux))
+	t.Cleanup(testServer.Close)
+	return testServer
+}
+
+func cookieTransferMethod(req *http.Request, forCreateNewSession bool, userContext supertokens.UserContext) sessmodels.TokenTransferMethod {
+	return sessmodels.CookieTransferMethod
+}
+
+func verifyRequest(testServerURL string, accessToken string, antiCsrf string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, testServerURL+"/verify", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Cookie", "sAccessToken="+accessToken)
+	req.Header.Add("anti-csrf", antiCsrf)
+	return http.DefaultClient.Do(req)
+}
+
+func TestSessionFlowAgainstFakeCore(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	testServer := supertokensInitForTest(t, core,
+		emailpassword.Init(nil),
+		session.Init(&sessmodels.TypeInput{GetTokenTransferMethod: cookieTransferMethod}),
+	)
+
+	res, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	signUpInfo := unittesting.ExtractInfoFromResponse(res)
+	assert.NotEmpty(t, signUpInfo["sAccessToken"])
+	assert.NotEmpty(t, signUpInfo["sRefreshToken"])
+
+	user, err := emailpassword.GetUserByEmail("test@example.com")
+	assert.NoError(t, err)
+
+	res, err = verifyRequest(testServer.URL, signUpInfo["sAccessToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, string(body))
+
+	res, err = unittesting.SessionRefresh(testServer.URL, signUpInfo["sRefreshToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	refreshInfo := unittesting.ExtractInfoFromResponse(res)
+	assert.NotEqual(t, signUpInfo["sRefreshToken"], refreshInfo["sRefreshToken"])
+
+	res, err = verifyRequest(testServer.URL, refreshInfo["sAccessToken"], refreshInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// the refresh was committed by using the new access token, so the old refresh token is now a sign of theft
+	res, err = unittesting.SessionRefresh(testServer.URL, signUpInfo["sRefreshToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	sessionHandles, err := session.GetAllSessionHandlesForUser(user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, sessionHandles)
+}
+
+func TestRecipeFunctionsAgainstFakeCore(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	supertokensInitForTest(t, core,
+		emailpassword.Init(nil),
+		emailverification.Init(evmodels.TypeInput{Mode: evmodels.ModeOptional}),
+		session.Init(nil),
+		userroles.Init(nil),
+		usermetadata.Init(nil),
+	)
+
+	signUpResponse, err := emailpassword.SignUp("test@example.com", "validpass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.OK)
+	userID := signUpResponse.OK.User.ID
+
+	signUpResponse, err = emailpassword.SignUp("test@example.com", "validpass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signUpResponse.EmailAlreadyExistsError)
+
+	signInResponse, err := emailpassword.SignIn("test@example.com", "wrongpass123")
+	assert.NoError(t, err)
+	assert.NotNil(t, signInResponse.WrongCredentialsError)
+
+	tokenResponse, err := emailverification.CreateEmailVerificationToken(userID, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, tokenResponse.OK)
+	verifyResponse, err := emailverification.VerifyEmailUsingToken(tokenResponse.OK.Token)
+	assert.NoError(t, err)
+	assert.NotNil(t, verifyResponse.OK)
+	isVerified, err := emailverification.IsEmailVerified(userID, nil)
+	assert.NoError(t, err)
+	assert.True(t, isVerified)
+
+	_, err = userroles.CreateNewRoleOrAddPermissions("admin", []string{"write", "read"}, nil)
+	assert.NoError(t, err)
+	addRoleResponse, err := userroles.AddRoleToUser(userID, "admin", nil)
+	assert.NoError(t, err)
+	assert.False(t, addRoleResponse.OK.DidUserAlreadyHaveRole)
+	permissionsResponse, err := userroles.GetPermissionsForRole("admin", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"read", "write"}, permissionsResponse.OK.Permissions)
+	unknownRoleResponse, err := userroles.AddRoleToUser(userID, "unknown", nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, unknownRoleResponse.UnknownRoleError)
+
+	_, err = usermetadata.UpdateUserMetadata(userID, map[string]interface{}{"a": "b", "c": "d"})
+	assert.NoError(t, err)
+	metadata, err := usermetadata.UpdateUserMetadata(userID, map[string]interface{}{"a": nil})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"c": "d"}, metadata)
+
+	createMappingResponse, err := supertokens.CreateUserIdMapping(userID, "externalId", nil, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, createMappingResponse.OK)
+	user, err := emailpassword.GetUserByID("externalId")
+	assert.NoError(t, err)
+	assert.Equal(t, "externalId", user.ID)
+
+	err = supertokens.DeleteUser("externalId")
+	assert.NoError(t, err)
+	user, err = emailpassword.GetUserByID(userID)
+	assert.NoError(t, err)
+	assert.Nil(t, user)
+}
+
+func TestPasswordlessAgainstFakeCore(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	supertokensInitForTest(t, core,
+		passwordless.Init(plessmodels.TypeInput{
+			FlowType: "USER_INPUT_CODE",
+			ContactMethodEmail: plessmodels.ContactMethodEmailConfig{
+				Enabled: true,
+			},
+		}),
+		session.Init(nil),
+	)
+
+	codeResponse, err := passwordless.CreateCodeWithEmail("test@example.com", nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, codeResponse.OK)
+
+	consumeResponse, err := passwordless.ConsumeCodeWithUserInputCode(codeResponse.OK.DeviceID, "wrong", codeResponse.OK.PreAuthSessionID)
+	assert.NoError(t, err)
+	assert.NotNil(t, consumeResponse.IncorrectUserInputCodeError)
+	assert.Equal(t, 1, consumeResponse.IncorrectUserInputCodeError.FailedCodeInputAttemptCount)
+
+	consumeResponse, err = passwordless.ConsumeCodeWithUserInputCode(codeResponse.OK.DeviceID, codeResponse.OK.UserInputCode, codeResponse.OK.PreAuthSessionID)
+	assert.NoError(t, err)
+	assert.NotNil(t, consumeResponse.OK)
+	assert.True(t, consumeResponse.OK.CreatedNewUser)
+	assert.Equal(t, "test@example.com", *consumeResponse.OK.User.Email)
+
+	devices, err := passwordless.ListCodesByEmail("test@example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, devices)
+}
+
+func TestFakeCoreReset(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	supertokensInitForTest(t, core, emailpassword.Init(nil), session.Init(nil))
+
+	_, err := emailpassword.SignUp("test@example.com", "validpass123")
+	assert.NoError(t, err)
+	count, err := supertokens.GetUserCount(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), count)
+
+	core.Reset()
+	count, err = supertokens.GetUserCount(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), count)
+}
+
+type recordingInstrumentationForTest struct {
+	lock     sync.Mutex
+	spans    []string
+	counters []string
+}
+
+func (i *recordingInstrumentationForTest) StartSpan(ctx context.Context, name string, fields []supertokens.LogField) (context.Context, supertokens.EndSpanFunc) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.spans = append(i.spans, name)
+	return ctx, func(err error) {}
+}
+
+func (i *recordingInstrumentationForTest) IncrementCounter(ctx context.Context, name string, fields []supertokens.LogField) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	i.counters = append(i.counters, name)
+}
+
+func TestInstrumentationAgainstFakeCore(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	instrumentation := &recordingInstrumentationForTest{}
+	testServer := supertokensInitWithInputForTest(t, core, supertokens.TypeInput{
+		RecipeList: []supertokens.Recipe{
+			emailpassword.Init(nil),
+			session.Init(&sessmodels.TypeInput{GetTokenTransferMethod: cookieTransferMethod}),
+		},
+		Instrumentation: instrumentation,
+	})
+
+	res, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	signUpInfo := unittesting.ExtractInfoFromResponse(res)
+
+	res, err = verifyRequest(testServer.URL, signUpInfo["sAccessToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = unittesting.SessionRefresh(testServer.URL, signUpInfo["sRefreshToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	refreshInfo := unittesting.ExtractInfoFromResponse(res)
+
+	res, err = verifyRequest(testServer.URL, refreshInfo["sAccessToken"], refreshInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = unittesting.SessionRefresh(testServer.URL, signUpInfo["sRefreshToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	_, err = emailpassword.SignIn("test@example.com", "validpass123")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		supertokens.CounterNameSignUps,
+		supertokens.CounterNameRefreshes,
+		supertokens.CounterNameTokenThefts,
+		supertokens.CounterNameSignIns,
+	}, instrumentation.counters)
+	for _, name := range []string{supertokens.SpanNameMiddleware, supertokens.SpanNameVerifySession, supertokens.SpanNameGetSession, supertokens.SpanNameRefreshSession, supertokens.SpanNameCoreRequest} {
+		assert.Contains(t, instrumentation.spans, name)
+	}
+}
+
+func TestNetworklessVerificationAgainstFakeCore(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	testServer := supertokensInitForTest(t, core,
+		emailpassword.Init(nil),
+		session.Init(&sessmodels.TypeInput{
+			GetTokenTransferMethod:  cookieTransferMethod,
+			NetworklessVerification: &sessmodels.NetworklessVerificationConfig{},
+		}),
+	)
+
+	res, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	signUpInfo := unittesting.ExtractInfoFromResponse(res)
+
+	res, err = unittesting.SessionRefresh(testServer.URL, signUpInfo["sRefreshToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	refreshInfo := unittesting.ExtractInfoFromResponse(res)
+
+	// the signing keys are fetched when the first access token is verified
+	res, err = verifyRequest(testServer.URL, signUpInfo["sAccessToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	core.Close()
+
+	// the access token was created by a refresh, which would otherwise require querying the core
+	res, err = verifyRequest(testServer.URL, refreshInfo["sAccessToken"], refreshInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	status, err := session.GetSigningKeySetStatus()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.KeyCount)
+	assert.False(t, status.FetchedAt.IsZero())
+	assert.False(t, status.IsStale)
+	assert.NoError(t, status.LastRefreshError)
+}
+
+func TestNetworklessVerificationWithJWKSEndpoint(t *testing.T) {
+	core := StartForTest(t, resetAll)
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		publicKey := core.signingKey.PublicKey
+		rw.Write([]byte(`{"keys":[{"kty":"RSA","use":"sig","n":"` + base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()) + `","e":"` + base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()) + `"}]}`))
+	}))
+	defer jwksServer.Close()
+	jwksEndpoint := jwksServer.URL
+	testServer := supertokensInitForTest(t, core,
+		emailpassword.Init(nil),
+		session.Init(&sessmodels.TypeInput{
+			GetTokenTransferMethod: cookieTransferMethod,
+			NetworklessVerification: &sessmodels.NetworklessVerificationConfig{
+				JWKSEndpoint: &jwksEndpoint,
+			},
+		}),
+	)
+
+	res, err := unittesting.SignupRequest("test@example.com", "validpass123", testServer.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	signUpInfo := unittesting.ExtractInfoFromResponse(res)
+
+	core.Close()
+
+	// the keys are fetched from the JWKS endpoint, so the core is not needed even for the first verification
+	res, err = verifyRequest(testServer.URL, signUpInfo["sAccessToken"], signUpInfo["antiCsrf"])
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
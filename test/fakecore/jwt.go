@@ -0,0 +1,88 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"time"
+)
+
+// signingKeyID is the kid of the fake's only key in the JWKS
+const signingKeyID = "s-fakecore"
+
+func (s *Server) createJWT(req coreRequest) (map[string]interface{}, error) {
+	algorithm, _ := req.getString("algorithm")
+	if algorithm != "RS256" {
+		return statusResponse("UNSUPPORTED_ALGORITHM_ERROR"), nil
+	}
+	validity, ok := req.body["validity"].(float64)
+	if !ok {
+		return nil, badRequestError{msg: "Field name 'validity' is invalid in JSON input"}
+	}
+
+	payload := req.getObject("payload")
+	now := time.Now().Unix()
+	payload["iat"] = now
+	payload["exp"] = now + int64(validity)
+	if jwksDomain, ok := req.getString("jwksDomain"); ok {
+		payload["iss"] = jwksDomain
+	}
+
+	header, err := json.Marshal(map[string]interface{}{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": signingKeyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	unsignedToken := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payloadJSON)
+	digest := sha256.Sum256([]byte(unsignedToken))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.signingKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	response := okResponse()
+	response["jwt"] = unsignedToken + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return response, nil
+}
+
+func (s *Server) getJWKS(req coreRequest) (map[string]interface{}, error) {
+	publicKey := s.signingKey.PublicKey
+	response := okResponse()
+	response["keys"] = []interface{}{
+		map[string]interface{}{
+			"kty": "RSA",
+			"kid": signingKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			"alg": "RS256",
+			"use": "sig",
+		},
+	}
+	return response, nil
+}
@@ -0,0 +1,300 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+func getPreAuthSessionID(deviceID string) string {
+	hash := sha256.Sum256([]byte(deviceID))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func (s *Server) createCode(req coreRequest) (map[string]interface{}, error) {
+	var device *passwordlessDevice
+	if deviceID, ok := req.getString("deviceId"); ok {
+		device = s.store.devices[getPreAuthSessionID(deviceID)]
+		if device == nil || device.deviceID != deviceID {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+	} else {
+		deviceID := base64.StdEncoding.EncodeToString([]byte(generateRandomString(16)))
+		device = &passwordlessDevice{
+			deviceID:         deviceID,
+			preAuthSessionID: getPreAuthSessionID(deviceID),
+		}
+		if email, ok := req.getString("email"); ok {
+			device.email = &email
+		} else if phoneNumber, ok := req.getString("phoneNumber"); ok {
+			device.phoneNumber = &phoneNumber
+		} else {
+			return nil, badRequestError{msg: "Please provide exactly one of email or phoneNumber"}
+		}
+		s.store.devices[device.preAuthSessionID] = device
+	}
+
+	userInputCode, ok := req.getString("userInputCode")
+	if ok {
+		for _, code := range device.codes {
+			if code.userInputCode == userInputCode {
+				return statusResponse("USER_INPUT_CODE_ALREADY_USED_ERROR"), nil
+			}
+		}
+	} else {
+		userInputCode = generateRandomDigits(6)
+	}
+
+	code := &passwordlessCode{
+		codeID:        generateUUID(),
+		userInputCode: userInputCode,
+		linkCode:      generateRandomString(32),
+		timeCreated:   getCurrTimeInMS(),
+		codeLifetime:  uint64(s.config.PasswordlessCodeLifetime.Milliseconds()),
+	}
+	device.codes = append(device.codes, code)
+
+	response := okResponse()
+	response["preAuthSessionId"] = device.preAuthSessionID
+	response["codeId"] = code.codeID
+	response["deviceId"] = device.deviceID
+	response["userInputCode"] = code.userInputCode
+	response["linkCode"] = code.linkCode
+	response["codeLifetime"] = code.codeLifetime
+	response["timeCreated"] = code.timeCreated
+	return response, nil
+}
+
+func (s *Server) consumeCode(req coreRequest) (map[string]interface{}, error) {
+	preAuthSessionID, err := req.getRequiredString("preAuthSessionId")
+	if err != nil {
+		return nil, err
+	}
+	device := s.store.devices[preAuthSessionID]
+	if device == nil {
+		return statusResponse("RESTART_FLOW_ERROR"), nil
+	}
+
+	var matchingCode *passwordlessCode
+	if linkCode, ok := req.getString("linkCode"); ok {
+		for _, code := range device.codes {
+			if code.linkCode == linkCode {
+				matchingCode = code
+			}
+		}
+		if matchingCode == nil || matchingCode.timeCreated+matchingCode.codeLifetime < getCurrTimeInMS() {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+	} else {
+		deviceID, err := req.getRequiredString("deviceId")
+		if err != nil {
+			return nil, err
+		}
+		userInputCode, err := req.getRequiredString("userInputCode")
+		if err != nil {
+			return nil, err
+		}
+		if device.deviceID != deviceID {
+			return statusResponse("RESTART_FLOW_ERROR"), nil
+		}
+		for _, code := range device.codes {
+			if code.userInputCode == userInputCode {
+				matchingCode = code
+			}
+		}
+
+		status := ""
+		if matchingCode == nil {
+			status = "INCORRECT_USER_INPUT_CODE_ERROR"
+		} else if matchingCode.timeCreated+matchingCode.codeLifetime < getCurrTimeInMS() {
+			status = "EXPIRED_USER_INPUT_CODE_ERROR"
+		}
+		if status != "" {
+			device.failedCodeInputAttemptCount++
+			if device.failedCodeInputAttemptCount >= s.config.PasswordlessMaxCodeInputAttempts {
+				delete(s.store.devices, preAuthSessionID)
+				return statusResponse("RESTART_FLOW_ERROR"), nil
+			}
+			response := statusResponse(status)
+			response["failedCodeInputAttemptCount"] = device.failedCodeInputAttemptCount
+			response["maximumCodeInputAttempts"] = s.config.PasswordlessMaxCodeInputAttempts
+			return response, nil
+		}
+	}
+
+	// all devices for the same email or phone number are cleared on sign in
+	for id, d := range s.store.devices {
+		if (device.email != nil && d.email != nil && *d.email == *device.email) ||
+			(device.phoneNumber != nil && d.phoneNumber != nil && *d.phoneNumber == *device.phoneNumber) {
+			delete(s.store.devices, id)
+		}
+	}
+
+	var u *user
+	if device.email != nil {
+		u = s.getUserByEmail(passwordlessRecipeID, *device.email)
+	} else {
+		u = s.getUserByPhoneNumber(passwordlessRecipeID, *device.phoneNumber)
+	}
+	createdNewUser := false
+	if u == nil {
+		createdNewUser = true
+		u = &user{
+			recipeID:    passwordlessRecipeID,
+			email:       device.email,
+			phoneNumber: device.phoneNumber,
+		}
+		s.addUser(u)
+	}
+
+	response := userResponse(s.userToJSON(u))
+	response["createdNewUser"] = createdNewUser
+	return response, nil
+}
+
+func (s *Server) listCodes(req coreRequest) (map[string]interface{}, error) {
+	devices := []interface{}{}
+	for _, device := range s.store.devices {
+		matches := false
+		if deviceID, ok := req.getString("deviceId"); ok {
+			matches = device.deviceID == deviceID
+		} else if preAuthSessionID, ok := req.getString("preAuthSessionId"); ok {
+			matches = device.preAuthSessionID == preAuthSessionID
+		} else if email, ok := req.getString("email"); ok {
+			matches = device.email != nil && *device.email == email
+		} else if phoneNumber, ok := req.getString("phoneNumber"); ok {
+			matches = device.phoneNumber != nil && *device.phoneNumber == phoneNumber
+		}
+		if !matches {
+			continue
+		}
+
+		codes := []interface{}{}
+		for _, code := range device.codes {
+			codes = append(codes, map[string]interface{}{
+				"codeId":       code.codeID,
+				"timeCreated":  code.timeCreated,
+				"codeLifetime": code.codeLifetime,
+			})
+		}
+		deviceJSON := map[string]interface{}{
+			"preAuthSessionId":            device.preAuthSessionID,
+			"failedCodeInputAttemptCount": device.failedCodeInputAttemptCount,
+			"codes":                       codes,
+		}
+		if device.email != nil {
+			deviceJSON["email"] = *device.email
+		}
+		if device.phoneNumber != nil {
+			deviceJSON["phoneNumber"] = *device.phoneNumber
+		}
+		devices = append(devices, deviceJSON)
+	}
+	response := okResponse()
+	response["devices"] = devices
+	return response, nil
+}
+
+func (s *Server) revokeAllCodes(req coreRequest) (map[string]interface{}, error) {
+	email, hasEmail := req.getString("email")
+	phoneNumber, hasPhoneNumber := req.getString("phoneNumber")
+	for id, device := range s.store.devices {
+		if (hasEmail && device.email != nil && *device.email == email) ||
+			(hasPhoneNumber && device.phoneNumber != nil && *device.phoneNumber == phoneNumber) {
+			delete(s.store.devices, id)
+		}
+	}
+	return okResponse(), nil
+}
+
+func (s *Server) revokeCode(req coreRequest) (map[string]interface{}, error) {
+	codeID, err := req.getRequiredString("codeId")
+	if err != nil {
+		return nil, err
+	}
+	for id, device := range s.store.devices {
+		for i, code := range device.codes {
+			if code.codeID == codeID {
+				device.codes = append(device.codes[:i], device.codes[i+1:]...)
+				break
+			}
+		}
+		if len(device.codes) == 0 {
+			delete(s.store.devices, id)
+		}
+	}
+	return okResponse(), nil
+}
+
+func (s *Server) getPasswordlessUser(req coreRequest) (map[string]interface{}, error) {
+	var u *user
+	if userID, ok := req.getString("userId"); ok {
+		u = s.getUserByID(passwordlessRecipeID, userID)
+	} else if email, ok := req.getString("email"); ok {
+		u = s.getUserByEmail(passwordlessRecipeID, email)
+	} else if phoneNumber, ok := req.getString("phoneNumber"); ok {
+		u = s.getUserByPhoneNumber(passwordlessRecipeID, phoneNumber)
+	} else {
+		return nil, badRequestError{msg: "Please provide exactly one of userId, email or phoneNumber"}
+	}
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	return userResponse(s.userToJSON(u)), nil
+}
+
+// updatePasswordlessUser treats a field set to null as a request to remove it
+func (s *Server) updatePasswordlessUser(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	u := s.getUserByID(passwordlessRecipeID, userID)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+
+	email := u.email
+	if value, ok := req.body["email"]; ok {
+		email = nil
+		if str, ok := value.(string); ok {
+			existing := s.getUserByEmail(passwordlessRecipeID, str)
+			if existing != nil && existing != u {
+				return statusResponse("EMAIL_ALREADY_EXISTS_ERROR"), nil
+			}
+			email = &str
+		}
+	}
+	phoneNumber := u.phoneNumber
+	if value, ok := req.body["phoneNumber"]; ok {
+		phoneNumber = nil
+		if str, ok := value.(string); ok {
+			existing := s.getUserByPhoneNumber(passwordlessRecipeID, str)
+			if existing != nil && existing != u {
+				return statusResponse("PHONE_NUMBER_ALREADY_EXISTS_ERROR"), nil
+			}
+			phoneNumber = &str
+		}
+	}
+	if email == nil && phoneNumber == nil {
+		return nil, badRequestError{msg: "You cannot clear both email and phone number of a user"}
+	}
+
+	u.email = email
+	u.phoneNumber = phoneNumber
+	return okResponse(), nil
+}
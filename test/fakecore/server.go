@@ -0,0 +1,391 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+// Package fakecore provides an in-memory implementation of the SuperTokens core
+// driver interface, so that apps using this SDK can be tested without
+// downloading and starting the Java core.
+//
+//	core := fakecore.NewServer()
+//	defer core.Close()
+//
+//	supertokens.Init(supertokens.TypeInput{
+//		Supertokens: &supertokens.ConnectionInfo{
+//			ConnectionURI: core.URL,
+//		},
+//		...
+//	})
+//
+// In a test, StartForTest starts a core that is closed once the test is done.
+//
+// The fake keeps all its state in memory and is not meant to reproduce every
+// edge case of the real core. It covers the endpoints used by the session,
+// emailpassword, thirdparty, passwordless, emailverification, userroles,
+// usermetadata and jwt recipes, as well as user id mapping and user
+// pagination.
+package fakecore
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// SupportedCDIVersions is returned by the fake's /apiversion endpoint
+var SupportedCDIVersions = []string{"2.8", "2.9", "2.10", "2.11", "2.12", "2.13", "2.14", "2.15", "2.16", "2.17", "2.18", "2.19", "2.20"}
+
+type Config struct {
+	// AccessTokenValidity defaults to 1 hour
+	AccessTokenValidity time.Duration
+	// RefreshTokenValidity defaults to 100 days
+	RefreshTokenValidity time.Duration
+	// AccessTokenBlacklisting makes the SDK verify every access token with the
+	// fake instead of only checking its signature
+	AccessTokenBlacklisting bool
+	// PasswordlessCodeLifetime defaults to 15 minutes
+	PasswordlessCodeLifetime time.Duration
+	// PasswordlessMaxCodeInputAttempts defaults to 5
+	PasswordlessMaxCodeInputAttempts int
+}
+
+type Server struct {
+	*httptest.Server
+
+	config       Config
+	signingKey   *rsa.PrivateKey
+	publicKey    string
+	keyCreatedAt uint64
+
+	lock   sync.Mutex
+	routes map[string]map[string]handlerFunc
+	store  store
+}
+
+type coreRequest struct {
+	rid   string
+	query url.Values
+	body  map[string]interface{}
+}
+
+type handlerFunc func(req coreRequest) (map[string]interface{}, error)
+
+// badRequestError makes the fake reply with a 400, like the core does for invalid input
+type badRequestError struct {
+	msg string
+}
+
+func (err badRequestError) Error() string {
+	return err.msg
+}
+
+// NewServer starts a fake core with the default configuration
+func NewServer() *Server {
+	return NewServerWithConfig(Config{})
+}
+
+func NewServerWithConfig(config Config) *Server {
+	if config.AccessTokenValidity == 0 {
+		config.AccessTokenValidity = time.Hour
+	}
+	if config.RefreshTokenValidity == 0 {
+		config.RefreshTokenValidity = 100 * 24 * time.Hour
+	}
+	if config.PasswordlessCodeLifetime == 0 {
+		config.PasswordlessCodeLifetime = 15 * time.Minute
+	}
+	if config.PasswordlessMaxCodeInputAttempts == 0 {
+		config.PasswordlessMaxCodeInputAttempts = 5
+	}
+
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	publicKey, err := encodePublicKey(&signingKey.PublicKey)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		config:       config,
+		signingKey:   signingKey,
+		publicKey:    publicKey,
+		keyCreatedAt: getCurrTimeInMS(),
+		store:        newStore(),
+	}
+	s.routes = map[string]map[string]handlerFunc{}
+	s.addRoutes()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Reset removes all users, sessions and other data stored in the fake
+func (s *Server) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.store = newStore()
+}
+
+func (s *Server) addRoute(method string, path string, handler handlerFunc) {
+	if _, ok := s.routes[path]; !ok {
+		s.routes[path] = map[string]handlerFunc{}
+	}
+	s.routes[path][method] = handler
+}
+
+func (s *Server) addRoutes() {
+	s.addRoute(http.MethodGet, "/apiversion", s.apiVersion)
+	s.addRoute(http.MethodGet, "/telemetry", s.telemetry)
+	s.addRoute(http.MethodGet, "/users", s.getUsers)
+	s.addRoute(http.MethodGet, "/users/count", s.getUserCount)
+	s.addRoute(http.MethodPost, "/user/remove", s.deleteUser)
+	s.addRoute(http.MethodGet, "/user/search/tags", s.searchTags)
+
+	s.addRoute(http.MethodPost, "/recipe/userid/map", s.createUserIdMapping)
+	s.addRoute(http.MethodGet, "/recipe/userid/map", s.getUserIdMapping)
+	s.addRoute(http.MethodPost, "/recipe/userid/map/remove", s.deleteUserIdMapping)
+	s.addRoute(http.MethodPut, "/recipe/userid/external-user-id-info", s.updateExternalUserIdInfo)
+
+	s.addRoute(http.MethodGet, "/recipe/user", s.getRecipeUser)
+	s.addRoute(http.MethodPut, "/recipe/user", s.updateRecipeUser)
+
+	s.addRoute(http.MethodPost, "/recipe/signup", s.emailPasswordSignUp)
+	s.addRoute(http.MethodPost, "/recipe/signin", s.emailPasswordSignIn)
+	s.addRoute(http.MethodPost, "/recipe/user/password/reset/token", s.createResetPasswordToken)
+	s.addRoute(http.MethodPost, "/recipe/user/password/reset", s.resetPassword)
+
+	s.addRoute(http.MethodPost, "/recipe/signinup", s.thirdPartySignInUp)
+	s.addRoute(http.MethodGet, "/recipe/users/by-email", s.getThirdPartyUsersByEmail)
+
+	s.addRoute(http.MethodPost, "/recipe/signinup/code", s.createCode)
+	s.addRoute(http.MethodPost, "/recipe/signinup/code/consume", s.consumeCode)
+	s.addRoute(http.MethodGet, "/recipe/signinup/codes", s.listCodes)
+	s.addRoute(http.MethodPost, "/recipe/signinup/codes/remove", s.revokeAllCodes)
+	s.addRoute(http.MethodPost, "/recipe/signinup/code/remove", s.revokeCode)
+
+	s.addRoute(http.MethodGet, "/recipe/handshake", s.handshake)
+	s.addRoute(http.MethodPost, "/recipe/handshake", s.handshake)
+	s.addRoute(http.MethodPost, "/recipe/session", s.createNewSession)
+	s.addRoute(http.MethodGet, "/recipe/session", s.getSessionInformation)
+	s.addRoute(http.MethodPost, "/recipe/session/verify", s.verifySession)
+	s.addRoute(http.MethodPost, "/recipe/session/refresh", s.refreshSession)
+	s.addRoute(http.MethodPost, "/recipe/session/remove", s.revokeSessions)
+	s.addRoute(http.MethodGet, "/recipe/session/user", s.getAllSessionHandlesForUser)
+	s.addRoute(http.MethodPut, "/recipe/session/data", s.updateSessionData)
+	s.addRoute(http.MethodPut, "/recipe/jwt/data", s.updateAccessTokenPayload)
+	s.addRoute(http.MethodPost, "/recipe/session/regenerate", s.regenerateAccessToken)
+
+	s.addRoute(http.MethodPost, "/recipe/jwt", s.createJWT)
+	s.addRoute(http.MethodGet, "/recipe/jwt/jwks", s.getJWKS)
+
+	s.addRoute(http.MethodPost, "/recipe/user/email/verify/token", s.createEmailVerificationToken)
+	s.addRoute(http.MethodPost, "/recipe/user/email/verify", s.verifyEmailUsingToken)
+	s.addRoute(http.MethodGet, "/recipe/user/email/verify", s.isEmailVerified)
+	s.addRoute(http.MethodPost, "/recipe/user/email/verify/token/remove", s.revokeEmailVerificationTokens)
+	s.addRoute(http.MethodPost, "/recipe/user/email/verify/remove", s.unverifyEmail)
+
+	s.addRoute(http.MethodPut, "/recipe/user/role", s.addRoleToUser)
+	s.addRoute(http.MethodPost, "/recipe/user/role/remove", s.removeUserRole)
+	s.addRoute(http.MethodGet, "/recipe/user/roles", s.getRolesForUser)
+	s.addRoute(http.MethodGet, "/recipe/role/users", s.getUsersThatHaveRole)
+	s.addRoute(http.MethodPut, "/recipe/role", s.createNewRoleOrAddPermissions)
+	s.addRoute(http.MethodGet, "/recipe/role/permissions", s.getPermissionsForRole)
+	s.addRoute(http.MethodPost, "/recipe/role/permissions/remove", s.removePermissionsFromRole)
+	s.addRoute(http.MethodGet, "/recipe/permission/roles", s.getRolesThatHavePermission)
+	s.addRoute(http.MethodPost, "/recipe/role/remove", s.deleteRole)
+	s.addRoute(http.MethodGet, "/recipe/roles", s.getAllRoles)
+
+	s.addRoute(http.MethodGet, "/recipe/user/metadata", s.getUserMetadata)
+	s.addRoute(http.MethodPut, "/recipe/user/metadata", s.updateUserMetadata)
+	s.addRoute(http.MethodPost, "/recipe/user/metadata/remove", s.clearUserMetadata)
+}
+
+func (s *Server) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	handler, ok := s.routes[r.URL.Path][r.Method]
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		rw.Write([]byte(fmt.Sprintf("fake core does not implement %s %s", r.Method, r.URL.Path)))
+		return
+	}
+
+	req := coreRequest{
+		rid:   r.Header.Get("rid"),
+		query: r.URL.Query(),
+		body:  map[string]interface{}{},
+	}
+	if r.Method != http.MethodGet && r.ContentLength != 0 {
+		err := json.NewDecoder(r.Body).Decode(&req.body)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			rw.Write([]byte("Invalid Json Input"))
+			return
+		}
+		if req.body == nil {
+			req.body = map[string]interface{}{}
+		}
+	}
+
+	s.lock.Lock()
+	response, err := handler(req)
+	s.lock.Unlock()
+
+	if err != nil {
+		if _, ok := err.(badRequestError); ok {
+			rw.WriteHeader(http.StatusBadRequest)
+		} else {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+		rw.Write([]byte(err.Error()))
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(rw).Encode(response)
+}
+
+func (s *Server) apiVersion(req coreRequest) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"versions": SupportedCDIVersions,
+	}, nil
+}
+
+func (s *Server) telemetry(req coreRequest) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"exists": false,
+	}, nil
+}
+
+func (s *Server) searchTags(req coreRequest) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"status": "OK",
+		"tags":   []string{},
+	}, nil
+}
+
+func okResponse() map[string]interface{} {
+	return map[string]interface{}{
+		"status": "OK",
+	}
+}
+
+func statusResponse(status string) map[string]interface{} {
+	return map[string]interface{}{
+		"status": status,
+	}
+}
+
+func (req coreRequest) getString(key string) (string, bool) {
+	if value, ok := req.body[key].(string); ok {
+		return value, true
+	}
+	if req.query.Get(key) != "" {
+		return req.query.Get(key), true
+	}
+	return "", false
+}
+
+func (req coreRequest) getRequiredString(key string) (string, error) {
+	value, ok := req.getString(key)
+	if !ok {
+		return "", badRequestError{msg: fmt.Sprintf("Field name '%s' is invalid in JSON input", key)}
+	}
+	return value, nil
+}
+
+func (req coreRequest) getBool(key string) bool {
+	value, ok := req.body[key].(bool)
+	if ok {
+		return value
+	}
+	return req.query.Get(key) == "true"
+}
+
+func (req coreRequest) getObject(key string) map[string]interface{} {
+	value, ok := req.body[key].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return value
+}
+
+func (req coreRequest) getStringArray(key string) ([]string, bool) {
+	values, ok := req.body[key].([]interface{})
+	if !ok {
+		return nil, false
+	}
+	result := []string{}
+	for _, v := range values {
+		if str, ok := v.(string); ok {
+			result = append(result, str)
+		}
+	}
+	return result, true
+}
+
+func generateUUID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func generateRandomString(size int) string {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func generateRandomDigits(size int) string {
+	result := ""
+	for i := 0; i < size; i++ {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			panic(err)
+		}
+		result += digit.String()
+	}
+	return result
+}
+
+func hashString(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
+func getCurrTimeInMS() uint64 {
+	return uint64(time.Now().UnixNano() / 1000000)
+}
+
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
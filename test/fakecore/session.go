@@ -0,0 +1,434 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// accessTokenHeader is the base64 encoded form of {"alg":"RS256","typ":"JWT","version":"2"}
+const accessTokenHeader = "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCIsInZlcnNpb24iOiIyIn0="
+
+const signingKeyValidityInMS = uint64(7 * 24 * 60 * 60 * 1000)
+
+func encodePublicKey(publicKey *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(der), nil
+}
+
+func (s *Server) addSigningKeyInfo(response map[string]interface{}) map[string]interface{} {
+	expiryTime := getCurrTimeInMS() + signingKeyValidityInMS
+	response["jwtSigningPublicKey"] = s.publicKey
+	response["jwtSigningPublicKeyExpiryTime"] = expiryTime
+	response["jwtSigningPublicKeyList"] = []interface{}{
+		map[string]interface{}{
+			"publicKey":  s.publicKey,
+			"expiryTime": expiryTime,
+			"createdAt":  s.keyCreatedAt,
+		},
+	}
+	return response
+}
+
+func (s *Server) signAccessToken(payload map[string]interface{}) (string, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encodedPayload := base64.StdEncoding.EncodeToString(payloadJSON)
+	digest := sha256.Sum256([]byte(accessTokenHeader + "." + encodedPayload))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.signingKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return accessTokenHeader + "." + encodedPayload + "." + base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// parseAccessToken checks the signature of an access token created by this
+// fake and returns its payload
+func (s *Server) parseAccessToken(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != accessTokenHeader {
+		return nil, errors.New("invalid access token")
+	}
+	signature, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	err = rsa.VerifyPKCS1v15(&s.signingKey.PublicKey, crypto.SHA256, digest[:], signature)
+	if err != nil {
+		return nil, err
+	}
+	payloadJSON, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	payload := map[string]interface{}{}
+	err = json.Unmarshal(payloadJSON, &payload)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (s *Server) createAccessToken(sess *coreSession, parentRefreshTokenHash1 string) (map[string]interface{}, error) {
+	timeCreated := getCurrTimeInMS()
+	expiry := timeCreated + uint64(s.config.AccessTokenValidity.Milliseconds())
+	payload := map[string]interface{}{
+		"sessionHandle":     sess.handle,
+		"userId":            sess.userID,
+		"refreshTokenHash1": sess.refreshTokenHash1,
+		"userData":          sess.userDataInJWT,
+		"expiryTime":        expiry,
+		"timeCreated":       timeCreated,
+	}
+	if parentRefreshTokenHash1 != "" {
+		payload["parentRefreshTokenHash1"] = parentRefreshTokenHash1
+	}
+	if sess.antiCsrfToken != nil {
+		payload["antiCsrfToken"] = *sess.antiCsrfToken
+	}
+	token, err := s.signAccessToken(payload)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"token":       token,
+		"expiry":      expiry,
+		"createdTime": timeCreated,
+	}, nil
+}
+
+func (s *Server) createRefreshToken(sess *coreSession) map[string]interface{} {
+	token := generateRandomString(48)
+	sess.refreshTokenHash1 = hashString(token)
+	s.store.refreshTokens[sess.refreshTokenHash1] = sess.handle
+	sess.expiry = getCurrTimeInMS() + uint64(s.config.RefreshTokenValidity.Milliseconds())
+	return map[string]interface{}{
+		"token":       token,
+		"expiry":      sess.expiry,
+		"createdTime": getCurrTimeInMS(),
+	}
+}
+
+func sessionToJSON(sess *coreSession) map[string]interface{} {
+	return map[string]interface{}{
+		"handle":        sess.handle,
+		"userId":        sess.userID,
+		"userDataInJWT": sess.userDataInJWT,
+	}
+}
+
+// getSession returns nil if the session does not exist or has expired
+func (s *Server) getSession(handle string) *coreSession {
+	sess, ok := s.store.sessions[handle]
+	if !ok {
+		return nil
+	}
+	if sess.expiry < getCurrTimeInMS() {
+		delete(s.store.sessions, handle)
+		return nil
+	}
+	return sess
+}
+
+func unauthorisedResponse(message string) map[string]interface{} {
+	response := statusResponse("UNAUTHORISED")
+	response["message"] = message
+	return response
+}
+
+func (s *Server) handshake(req coreRequest) (map[string]interface{}, error) {
+	response := okResponse()
+	response["accessTokenBlacklistingEnabled"] = s.config.AccessTokenBlacklisting
+	response["accessTokenValidity"] = s.config.AccessTokenValidity.Milliseconds()
+	response["refreshTokenValidity"] = s.config.RefreshTokenValidity.Milliseconds()
+	return s.addSigningKeyInfo(response), nil
+}
+
+func (s *Server) createNewSession(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	sess := &coreSession{
+		handle:             generateUUID(),
+		userID:             userID,
+		userDataInJWT:      req.getObject("userDataInJWT"),
+		userDataInDatabase: req.getObject("userDataInDatabase"),
+		timeCreated:        getCurrTimeInMS(),
+	}
+	if req.getBool("enableAntiCsrf") {
+		antiCsrfToken := generateUUID()
+		sess.antiCsrfToken = &antiCsrfToken
+	}
+	refreshToken := s.createRefreshToken(sess)
+	accessToken, err := s.createAccessToken(sess, "")
+	if err != nil {
+		return nil, err
+	}
+	s.store.sessions[sess.handle] = sess
+
+	response := okResponse()
+	response["session"] = sessionToJSON(sess)
+	response["accessToken"] = accessToken
+	response["refreshToken"] = refreshToken
+	if sess.antiCsrfToken != nil {
+		response["antiCsrfToken"] = *sess.antiCsrfToken
+	}
+	return s.addSigningKeyInfo(response), nil
+}
+
+func (s *Server) verifySession(req coreRequest) (map[string]interface{}, error) {
+	accessToken, err := req.getRequiredString("accessToken")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.parseAccessToken(accessToken)
+	if err != nil {
+		response := statusResponse("TRY_REFRESH_TOKEN")
+		response["message"] = err.Error()
+		return s.addSigningKeyInfo(response), nil
+	}
+	if expiryTime, _ := payload["expiryTime"].(float64); uint64(expiryTime) < getCurrTimeInMS() {
+		response := statusResponse("TRY_REFRESH_TOKEN")
+		response["message"] = "Access token expired"
+		return s.addSigningKeyInfo(response), nil
+	}
+	if req.getBool("enableAntiCsrf") && req.getBool("doAntiCsrfCheck") {
+		antiCsrfToken, _ := req.getString("antiCsrfToken")
+		if payload["antiCsrfToken"] != antiCsrfToken {
+			response := statusResponse("TRY_REFRESH_TOKEN")
+			response["message"] = "anti-csrf check failed"
+			return s.addSigningKeyInfo(response), nil
+		}
+	}
+
+	handle, _ := payload["sessionHandle"].(string)
+	sess := s.getSession(handle)
+	if sess == nil {
+		return unauthorisedResponse("Either the session has ended or has been blacklisted"), nil
+	}
+
+	response := okResponse()
+	userData, _ := payload["userData"].(map[string]interface{})
+	response["session"] = map[string]interface{}{
+		"handle":        sess.handle,
+		"userId":        sess.userID,
+		"userDataInJWT": userData,
+	}
+
+	// the first use of an access token created by a refresh commits that refresh
+	if _, ok := payload["parentRefreshTokenHash1"]; ok {
+		if payload["refreshTokenHash1"] != sess.refreshTokenHash1 {
+			response := statusResponse("TRY_REFRESH_TOKEN")
+			response["message"] = "Access token has been replaced by a newer refresh"
+			return s.addSigningKeyInfo(response), nil
+		}
+		sess.parentRefreshTokenHash1 = ""
+		newAccessToken, err := s.createAccessToken(sess, "")
+		if err != nil {
+			return nil, err
+		}
+		response["accessToken"] = newAccessToken
+		response["session"] = sessionToJSON(sess)
+	}
+	return s.addSigningKeyInfo(response), nil
+}
+
+func (s *Server) refreshSession(req coreRequest) (map[string]interface{}, error) {
+	refreshToken, err := req.getRequiredString("refreshToken")
+	if err != nil {
+		return nil, err
+	}
+	hash := hashString(refreshToken)
+	handle, ok := s.store.refreshTokens[hash]
+	if !ok {
+		return unauthorisedResponse("Refresh token not found"), nil
+	}
+	sess := s.getSession(handle)
+	if sess == nil {
+		return unauthorisedResponse("Either the session has ended or has been blacklisted"), nil
+	}
+	if req.getBool("enableAntiCsrf") && sess.antiCsrfToken != nil {
+		antiCsrfToken, _ := req.getString("antiCsrfToken")
+		if antiCsrfToken != *sess.antiCsrfToken {
+			return unauthorisedResponse("Anti CSRF token missing, or not matching"), nil
+		}
+	}
+
+	if hash == sess.refreshTokenHash1 {
+		sess.parentRefreshTokenHash1 = hash
+	} else if hash != sess.parentRefreshTokenHash1 {
+		delete(s.store.sessions, sess.handle)
+		response := statusResponse("TOKEN_THEFT_DETECTED")
+		response["session"] = map[string]interface{}{
+			"handle": sess.handle,
+			"userId": sess.userID,
+		}
+		return response, nil
+	}
+
+	if req.getBool("enableAntiCsrf") {
+		antiCsrfToken := generateUUID()
+		sess.antiCsrfToken = &antiCsrfToken
+	} else {
+		sess.antiCsrfToken = nil
+	}
+	newRefreshToken := s.createRefreshToken(sess)
+	accessToken, err := s.createAccessToken(sess, sess.parentRefreshTokenHash1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := okResponse()
+	response["session"] = sessionToJSON(sess)
+	response["accessToken"] = accessToken
+	response["refreshToken"] = newRefreshToken
+	if sess.antiCsrfToken != nil {
+		response["antiCsrfToken"] = *sess.antiCsrfToken
+	}
+	return response, nil
+}
+
+func (s *Server) getSessionInformation(req coreRequest) (map[string]interface{}, error) {
+	handle, err := req.getRequiredString("sessionHandle")
+	if err != nil {
+		return nil, err
+	}
+	sess := s.getSession(handle)
+	if sess == nil {
+		return unauthorisedResponse("Session does not exist."), nil
+	}
+	response := okResponse()
+	response["sessionHandle"] = sess.handle
+	response["userId"] = sess.userID
+	response["userDataInDatabase"] = sess.userDataInDatabase
+	response["userDataInJWT"] = sess.userDataInJWT
+	response["expiry"] = sess.expiry
+	response["timeCreated"] = sess.timeCreated
+	return response, nil
+}
+
+func (s *Server) revokeSessions(req coreRequest) (map[string]interface{}, error) {
+	revoked := []string{}
+	if userID, ok := req.getString("userId"); ok {
+		for handle, sess := range s.store.sessions {
+			if sess.userID == userID {
+				delete(s.store.sessions, handle)
+				revoked = append(revoked, handle)
+			}
+		}
+	} else {
+		handles, _ := req.getStringArray("sessionHandles")
+		for _, handle := range handles {
+			if _, ok := s.store.sessions[handle]; ok {
+				delete(s.store.sessions, handle)
+				revoked = append(revoked, handle)
+			}
+		}
+	}
+	response := okResponse()
+	response["sessionHandlesRevoked"] = revoked
+	return response, nil
+}
+
+func (s *Server) getAllSessionHandlesForUser(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	handles := []string{}
+	for handle, sess := range s.store.sessions {
+		if sess.userID == userID && s.getSession(handle) != nil {
+			handles = append(handles, handle)
+		}
+	}
+	response := okResponse()
+	response["sessionHandles"] = handles
+	return response, nil
+}
+
+func (s *Server) updateSessionData(req coreRequest) (map[string]interface{}, error) {
+	handle, err := req.getRequiredString("sessionHandle")
+	if err != nil {
+		return nil, err
+	}
+	sess := s.getSession(handle)
+	if sess == nil {
+		return unauthorisedResponse("Session does not exist."), nil
+	}
+	sess.userDataInDatabase = req.getObject("userDataInDatabase")
+	return okResponse(), nil
+}
+
+func (s *Server) updateAccessTokenPayload(req coreRequest) (map[string]interface{}, error) {
+	handle, err := req.getRequiredString("sessionHandle")
+	if err != nil {
+		return nil, err
+	}
+	sess := s.getSession(handle)
+	if sess == nil {
+		return unauthorisedResponse("Session does not exist."), nil
+	}
+	sess.userDataInJWT = req.getObject("userDataInJWT")
+	return okResponse(), nil
+}
+
+func (s *Server) regenerateAccessToken(req coreRequest) (map[string]interface{}, error) {
+	accessToken, err := req.getRequiredString("accessToken")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.parseAccessToken(accessToken)
+	if err != nil {
+		return nil, badRequestError{msg: err.Error()}
+	}
+	handle, _ := payload["sessionHandle"].(string)
+	sess := s.getSession(handle)
+	if sess == nil {
+		return unauthorisedResponse("Session does not exist."), nil
+	}
+	sess.userDataInJWT = req.getObject("userDataInJWT")
+
+	newPayload := copyMap(payload)
+	newPayload["userData"] = sess.userDataInJWT
+	token, err := s.signAccessToken(newPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	response := okResponse()
+	response["session"] = sessionToJSON(sess)
+	response["accessToken"] = map[string]interface{}{
+		"token":       token,
+		"expiry":      payload["expiryTime"],
+		"createdTime": payload["timeCreated"],
+	}
+	return response, nil
+}
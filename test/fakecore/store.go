@@ -0,0 +1,115 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+const (
+	emailPasswordRecipeID = "emailpassword"
+	thirdPartyRecipeID    = "thirdparty"
+	passwordlessRecipeID  = "passwordless"
+)
+
+type user struct {
+	recipeID         string
+	id               string
+	timeJoined       uint64
+	email            *string
+	phoneNumber      *string
+	passwordHash     string
+	thirdPartyID     string
+	thirdPartyUserID string
+}
+
+type userIdMapping struct {
+	superTokensUserID  string
+	externalUserID     string
+	externalUserIDInfo *string
+}
+
+type coreSession struct {
+	handle             string
+	userID             string
+	userDataInJWT      map[string]interface{}
+	userDataInDatabase map[string]interface{}
+	timeCreated        uint64
+	expiry             uint64
+	antiCsrfToken      *string
+	// refreshTokenHash1 is the hash of the latest refresh token given out
+	refreshTokenHash1 string
+	// parentRefreshTokenHash1 is the hash of the refresh token used to create
+	// the latest one. It stays usable until an access token created by that
+	// refresh is verified, so that concurrent refreshes are not treated as theft.
+	parentRefreshTokenHash1 string
+}
+
+type passwordlessCode struct {
+	codeID        string
+	userInputCode string
+	linkCode      string
+	timeCreated   uint64
+	codeLifetime  uint64
+}
+
+type passwordlessDevice struct {
+	deviceID                    string
+	preAuthSessionID            string
+	email                       *string
+	phoneNumber                 *string
+	failedCodeInputAttemptCount int
+	codes                       []*passwordlessCode
+}
+
+type emailVerificationToken struct {
+	userID string
+	email  string
+}
+
+type store struct {
+	users     map[string]*user
+	userOrder []string
+	mappings  []*userIdMapping
+
+	resetPasswordTokens map[string]string
+
+	devices map[string]*passwordlessDevice
+
+	sessions      map[string]*coreSession
+	refreshTokens map[string]string
+
+	emailVerificationTokens map[string]emailVerificationToken
+	verifiedEmails          map[emailVerificationToken]bool
+
+	roles     map[string]map[string]bool
+	userRoles map[string]map[string]bool
+
+	metadata map[string]map[string]interface{}
+}
+
+func newStore() store {
+	return store{
+		users:                   map[string]*user{},
+		userOrder:               []string{},
+		mappings:                []*userIdMapping{},
+		resetPasswordTokens:     map[string]string{},
+		devices:                 map[string]*passwordlessDevice{},
+		sessions:                map[string]*coreSession{},
+		refreshTokens:           map[string]string{},
+		emailVerificationTokens: map[string]emailVerificationToken{},
+		verifiedEmails:          map[emailVerificationToken]bool{},
+		roles:                   map[string]map[string]bool{},
+		userRoles:               map[string]map[string]bool{},
+		metadata:                map[string]map[string]interface{}{},
+	}
+}
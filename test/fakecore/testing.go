@@ -0,0 +1,54 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"testing"
+
+	"github.com/supertokens/supertokens-golang/supertokens"
+)
+
+// StartForTest starts a fake core for a test. Once the test is done, the core is closed and
+// resetAll, which should reset the SDK and the recipes initialised by the test, is called.
+func StartForTest(t *testing.T, resetAll func()) *Server {
+	core := NewServer()
+	t.Cleanup(func() {
+		core.Close()
+		resetAll()
+	})
+	return core
+}
+
+// InitForTest initialises the SDK with config, pointing it to the fake core, and with the app info
+// used by the tests of this SDK (whose API domain is api.supertokens.io). The SDK and the recipes of
+// config must have been reset before.
+func (s *Server) InitForTest(t *testing.T, config supertokens.TypeInput) {
+	connectionInfo := supertokens.ConnectionInfo{}
+	if config.Supertokens != nil {
+		connectionInfo = *config.Supertokens
+	}
+	connectionInfo.ConnectionURI = s.URL
+	config.Supertokens = &connectionInfo
+	config.AppInfo = supertokens.AppInfo{
+		APIDomain:     "api.supertokens.io",
+		AppName:       "SuperTokens",
+		WebsiteDomain: "supertokens.io",
+	}
+	err := supertokens.Init(config)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+}
@@ -0,0 +1,64 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+func (s *Server) getUserMetadata(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := s.store.metadata[userID]
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	response := okResponse()
+	response["metadata"] = metadata
+	return response, nil
+}
+
+// updateUserMetadata does a shallow merge, where fields set to null are removed
+func (s *Server) updateUserMetadata(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := s.store.metadata[userID]
+	if !ok {
+		metadata = map[string]interface{}{}
+	}
+	metadata = copyMap(metadata)
+	for k, v := range req.getObject("metadataUpdate") {
+		if v == nil {
+			delete(metadata, k)
+		} else {
+			metadata[k] = v
+		}
+	}
+	s.store.metadata[userID] = metadata
+
+	response := okResponse()
+	response["metadata"] = metadata
+	return response, nil
+}
+
+func (s *Server) clearUserMetadata(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	delete(s.store.metadata, userID)
+	return okResponse(), nil
+}
@@ -0,0 +1,189 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import "sort"
+
+func sortedKeys(m map[string]bool) []string {
+	result := []string{}
+	for k := range m {
+		result = append(result, k)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (s *Server) createNewRoleOrAddPermissions(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	permissions, ok := s.store.roles[role]
+	if !ok {
+		permissions = map[string]bool{}
+		s.store.roles[role] = permissions
+	}
+	newPermissions, _ := req.getStringArray("permissions")
+	for _, permission := range newPermissions {
+		permissions[permission] = true
+	}
+	response := okResponse()
+	response["createdNewRole"] = !ok
+	return response, nil
+}
+
+func (s *Server) addRoleToUser(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.store.roles[role]; !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	if _, ok := s.store.userRoles[userID]; !ok {
+		s.store.userRoles[userID] = map[string]bool{}
+	}
+	response := okResponse()
+	response["didUserAlreadyHaveRole"] = s.store.userRoles[userID][role]
+	s.store.userRoles[userID][role] = true
+	return response, nil
+}
+
+func (s *Server) removeUserRole(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.store.roles[role]; !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	response := okResponse()
+	response["didUserHaveRole"] = s.store.userRoles[userID][role]
+	delete(s.store.userRoles[userID], role)
+	return response, nil
+}
+
+func (s *Server) getRolesForUser(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	response := okResponse()
+	response["roles"] = sortedKeys(s.store.userRoles[userID])
+	return response, nil
+}
+
+func (s *Server) getUsersThatHaveRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.store.roles[role]; !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	users := map[string]bool{}
+	for userID, roles := range s.store.userRoles {
+		if roles[role] {
+			users[userID] = true
+		}
+	}
+	response := okResponse()
+	response["users"] = sortedKeys(users)
+	return response, nil
+}
+
+func (s *Server) getPermissionsForRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	permissions, ok := s.store.roles[role]
+	if !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	response := okResponse()
+	response["permissions"] = sortedKeys(permissions)
+	return response, nil
+}
+
+func (s *Server) removePermissionsFromRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	permissions, ok := s.store.roles[role]
+	if !ok {
+		return statusResponse("UNKNOWN_ROLE_ERROR"), nil
+	}
+	toRemove, ok := req.getStringArray("permissions")
+	if !ok {
+		s.store.roles[role] = map[string]bool{}
+		return okResponse(), nil
+	}
+	for _, permission := range toRemove {
+		delete(permissions, permission)
+	}
+	return okResponse(), nil
+}
+
+func (s *Server) getRolesThatHavePermission(req coreRequest) (map[string]interface{}, error) {
+	permission, err := req.getRequiredString("permission")
+	if err != nil {
+		return nil, err
+	}
+	roles := map[string]bool{}
+	for role, permissions := range s.store.roles {
+		if permissions[permission] {
+			roles[role] = true
+		}
+	}
+	response := okResponse()
+	response["roles"] = sortedKeys(roles)
+	return response, nil
+}
+
+func (s *Server) deleteRole(req coreRequest) (map[string]interface{}, error) {
+	role, err := req.getRequiredString("role")
+	if err != nil {
+		return nil, err
+	}
+	_, ok := s.store.roles[role]
+	delete(s.store.roles, role)
+	for _, roles := range s.store.userRoles {
+		delete(roles, role)
+	}
+	response := okResponse()
+	response["didRoleExist"] = ok
+	return response, nil
+}
+
+func (s *Server) getAllRoles(req coreRequest) (map[string]interface{}, error) {
+	roles := map[string]bool{}
+	for role := range s.store.roles {
+		roles[role] = true
+	}
+	response := okResponse()
+	response["roles"] = sortedKeys(roles)
+	return response, nil
+}
@@ -0,0 +1,567 @@
+/* Copyright (c) 2022, VRAI Labs and/or its affiliates. All rights reserved.
+ *
+ * This software is licensed under the Apache License, Version 2.0 (the
+ * "License") as published by the Apache Software Foundation.
+ *
+ * You may not use this file except in compliance with the License. You may
+ * obtain a copy of the License at http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the
+ * License for the specific language governing permissions and limitations
+ * under the License.
+ */
+
+package fakecore
+
+import (
+	"strconv"
+	"strings"
+)
+
+func (s *Server) addUser(u *user) {
+	u.timeJoined = getCurrTimeInMS()
+	u.id = generateUUID()
+	s.store.users[u.id] = u
+	s.store.userOrder = append(s.store.userOrder, u.id)
+}
+
+// getUserByID accepts both a SuperTokens user id and an external user id
+func (s *Server) getUserByID(recipeID string, userID string) *user {
+	if mapping := s.getMapping(userID, "ANY"); mapping != nil {
+		userID = mapping.superTokensUserID
+	}
+	u, ok := s.store.users[userID]
+	if !ok || (recipeID != "" && u.recipeID != recipeID) {
+		return nil
+	}
+	return u
+}
+
+func (s *Server) findUser(recipeID string, matches func(u *user) bool) *user {
+	for _, id := range s.store.userOrder {
+		u := s.store.users[id]
+		if u.recipeID == recipeID && matches(u) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) getUserByEmail(recipeID string, email string) *user {
+	return s.findUser(recipeID, func(u *user) bool {
+		return u.email != nil && *u.email == email
+	})
+}
+
+func (s *Server) getUserByPhoneNumber(recipeID string, phoneNumber string) *user {
+	return s.findUser(recipeID, func(u *user) bool {
+		return u.phoneNumber != nil && *u.phoneNumber == phoneNumber
+	})
+}
+
+// userToJSON returns the user in the format of the recipe it belongs to, with
+// its external user id if a mapping exists
+func (s *Server) userToJSON(u *user) map[string]interface{} {
+	id := u.id
+	if mapping := s.getMapping(u.id, "SUPERTOKENS"); mapping != nil {
+		id = mapping.externalUserID
+	}
+	result := map[string]interface{}{
+		"id":         id,
+		"timeJoined": u.timeJoined,
+	}
+	if u.email != nil {
+		result["email"] = *u.email
+	}
+	if u.phoneNumber != nil {
+		result["phoneNumber"] = *u.phoneNumber
+	}
+	if u.recipeID == thirdPartyRecipeID {
+		result["thirdParty"] = map[string]interface{}{
+			"id":     u.thirdPartyID,
+			"userId": u.thirdPartyUserID,
+		}
+	}
+	return result
+}
+
+func (s *Server) getUsers(req coreRequest) (map[string]interface{}, error) {
+	users := s.getFilteredUsers(req)
+	if req.query.Get("timeJoinedOrder") == "DESC" {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	limit := 100
+	if req.query.Get("limit") != "" {
+		parsedLimit, err := strconv.Atoi(req.query.Get("limit"))
+		if err != nil || parsedLimit <= 0 {
+			return nil, badRequestError{msg: "Field name 'limit' is invalid"}
+		}
+		limit = parsedLimit
+	}
+
+	start := 0
+	if paginationToken := req.query.Get("paginationToken"); paginationToken != "" {
+		start = -1
+		for i, u := range users {
+			if u.id == paginationToken {
+				start = i
+				break
+			}
+		}
+		if start == -1 {
+			return nil, badRequestError{msg: "invalid pagination token"}
+		}
+	}
+
+	result := []interface{}{}
+	for i := start; i < len(users) && i < start+limit; i++ {
+		result = append(result, map[string]interface{}{
+			"recipeId": users[i].recipeID,
+			"user":     s.userToJSON(users[i]),
+		})
+	}
+	response := okResponse()
+	response["users"] = result
+	if start+limit < len(users) {
+		response["nextPaginationToken"] = users[start+limit].id
+	}
+	return response, nil
+}
+
+func (s *Server) getUserCount(req coreRequest) (map[string]interface{}, error) {
+	response := okResponse()
+	response["count"] = len(s.getFilteredUsers(req))
+	return response, nil
+}
+
+func (s *Server) getFilteredUsers(req coreRequest) []*user {
+	includeRecipeIds := map[string]bool{}
+	if req.query.Get("includeRecipeIds") != "" {
+		for _, recipeID := range strings.Split(req.query.Get("includeRecipeIds"), ",") {
+			includeRecipeIds[strings.TrimSpace(recipeID)] = true
+		}
+	}
+
+	users := []*user{}
+	for _, id := range s.store.userOrder {
+		u := s.store.users[id]
+		if len(includeRecipeIds) > 0 && !includeRecipeIds[u.recipeID] {
+			continue
+		}
+		if !matchesSearchParam(req.query.Get("email"), u.email) ||
+			!matchesSearchParam(req.query.Get("phone"), u.phoneNumber) {
+			continue
+		}
+		if req.query.Get("provider") != "" {
+			thirdPartyID := u.thirdPartyID
+			if u.recipeID != thirdPartyRecipeID || !matchesSearchParam(req.query.Get("provider"), &thirdPartyID) {
+				continue
+			}
+		}
+		users = append(users, u)
+	}
+	return users
+}
+
+// matchesSearchParam does a prefix match against any of the ; separated values in param
+func matchesSearchParam(param string, value *string) bool {
+	if param == "" {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	for _, prefix := range strings.Split(param, ";") {
+		if strings.HasPrefix(strings.ToLower(*value), strings.ToLower(strings.TrimSpace(prefix))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) deleteUser(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+
+	superTokensUserID := userID
+	externalUserID := userID
+	if mapping := s.getMapping(userID, "ANY"); mapping != nil {
+		superTokensUserID = mapping.superTokensUserID
+		externalUserID = mapping.externalUserID
+		s.removeMapping(mapping)
+	}
+
+	if _, ok := s.store.users[superTokensUserID]; ok {
+		delete(s.store.users, superTokensUserID)
+		for i, id := range s.store.userOrder {
+			if id == superTokensUserID {
+				s.store.userOrder = append(s.store.userOrder[:i], s.store.userOrder[i+1:]...)
+				break
+			}
+		}
+	}
+
+	for _, id := range []string{superTokensUserID, externalUserID} {
+		for handle, sess := range s.store.sessions {
+			if sess.userID == id {
+				delete(s.store.sessions, handle)
+			}
+		}
+		for token, resetUserID := range s.store.resetPasswordTokens {
+			if resetUserID == id {
+				delete(s.store.resetPasswordTokens, token)
+			}
+		}
+		for token, info := range s.store.emailVerificationTokens {
+			if info.userID == id {
+				delete(s.store.emailVerificationTokens, token)
+			}
+		}
+		for info := range s.store.verifiedEmails {
+			if info.userID == id {
+				delete(s.store.verifiedEmails, info)
+			}
+		}
+		delete(s.store.userRoles, id)
+		delete(s.store.metadata, id)
+	}
+	return okResponse(), nil
+}
+
+func (s *Server) getMapping(userID string, userIDType string) *userIdMapping {
+	for _, mapping := range s.store.mappings {
+		if (userIDType != "EXTERNAL" && mapping.superTokensUserID == userID) ||
+			(userIDType != "SUPERTOKENS" && mapping.externalUserID == userID) {
+			return mapping
+		}
+	}
+	return nil
+}
+
+func (s *Server) removeMapping(mapping *userIdMapping) {
+	for i, m := range s.store.mappings {
+		if m == mapping {
+			s.store.mappings = append(s.store.mappings[:i], s.store.mappings[i+1:]...)
+			return
+		}
+	}
+}
+
+func getUserIDType(req coreRequest) string {
+	userIDType, ok := req.getString("userIdType")
+	if !ok {
+		return "ANY"
+	}
+	return userIDType
+}
+
+func (s *Server) createUserIdMapping(req coreRequest) (map[string]interface{}, error) {
+	superTokensUserID, err := req.getRequiredString("superTokensUserId")
+	if err != nil {
+		return nil, err
+	}
+	externalUserID, err := req.getRequiredString("externalUserId")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.store.users[superTokensUserID]; !ok {
+		return statusResponse("UNKNOWN_SUPERTOKENS_USER_ID_ERROR"), nil
+	}
+
+	doesSuperTokensUserIdExist := s.getMapping(superTokensUserID, "SUPERTOKENS") != nil
+	doesExternalUserIdExist := s.getMapping(externalUserID, "EXTERNAL") != nil
+	if doesSuperTokensUserIdExist || doesExternalUserIdExist {
+		response := statusResponse("USER_ID_MAPPING_ALREADY_EXISTS_ERROR")
+		response["doesSuperTokensUserIdExist"] = doesSuperTokensUserIdExist
+		response["doesExternalUserIdExist"] = doesExternalUserIdExist
+		return response, nil
+	}
+
+	mapping := &userIdMapping{
+		superTokensUserID: superTokensUserID,
+		externalUserID:    externalUserID,
+	}
+	if externalUserIDInfo, ok := req.getString("externalUserIdInfo"); ok {
+		mapping.externalUserIDInfo = &externalUserIDInfo
+	}
+	s.store.mappings = append(s.store.mappings, mapping)
+	return okResponse(), nil
+}
+
+func (s *Server) getUserIdMapping(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	mapping := s.getMapping(userID, getUserIDType(req))
+	if mapping == nil {
+		return statusResponse("UNKNOWN_MAPPING_ERROR"), nil
+	}
+	response := okResponse()
+	response["superTokensUserId"] = mapping.superTokensUserID
+	response["externalUserId"] = mapping.externalUserID
+	if mapping.externalUserIDInfo != nil {
+		response["externalUserIdInfo"] = *mapping.externalUserIDInfo
+	}
+	return response, nil
+}
+
+func (s *Server) deleteUserIdMapping(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	mapping := s.getMapping(userID, getUserIDType(req))
+	if mapping != nil {
+		s.removeMapping(mapping)
+	}
+	response := okResponse()
+	response["didMappingExist"] = mapping != nil
+	return response, nil
+}
+
+func (s *Server) updateExternalUserIdInfo(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	mapping := s.getMapping(userID, getUserIDType(req))
+	if mapping == nil {
+		return statusResponse("UNKNOWN_MAPPING_ERROR"), nil
+	}
+	if externalUserIDInfo, ok := req.getString("externalUserIdInfo"); ok {
+		mapping.externalUserIDInfo = &externalUserIDInfo
+	} else {
+		mapping.externalUserIDInfo = nil
+	}
+	return okResponse(), nil
+}
+
+// getRecipeUser and updateRecipeUser serve a path shared by several recipes,
+// the rid header tells them apart
+func (s *Server) getRecipeUser(req coreRequest) (map[string]interface{}, error) {
+	switch req.rid {
+	case thirdPartyRecipeID:
+		return s.getThirdPartyUser(req)
+	case passwordlessRecipeID:
+		return s.getPasswordlessUser(req)
+	default:
+		return s.getEmailPasswordUser(req)
+	}
+}
+
+func (s *Server) updateRecipeUser(req coreRequest) (map[string]interface{}, error) {
+	if req.rid == passwordlessRecipeID {
+		return s.updatePasswordlessUser(req)
+	}
+	return s.updateEmailPasswordUser(req)
+}
+
+func userResponse(user map[string]interface{}) map[string]interface{} {
+	response := okResponse()
+	response["user"] = user
+	return response
+}
+
+func (s *Server) emailPasswordSignUp(req coreRequest) (map[string]interface{}, error) {
+	email, err := req.getRequiredString("email")
+	if err != nil {
+		return nil, err
+	}
+	password, err := req.getRequiredString("password")
+	if err != nil {
+		return nil, err
+	}
+	if s.getUserByEmail(emailPasswordRecipeID, email) != nil {
+		return statusResponse("EMAIL_ALREADY_EXISTS_ERROR"), nil
+	}
+	u := &user{
+		recipeID:     emailPasswordRecipeID,
+		email:        &email,
+		passwordHash: hashString(password),
+	}
+	s.addUser(u)
+	return userResponse(s.userToJSON(u)), nil
+}
+
+func (s *Server) emailPasswordSignIn(req coreRequest) (map[string]interface{}, error) {
+	email, err := req.getRequiredString("email")
+	if err != nil {
+		return nil, err
+	}
+	password, err := req.getRequiredString("password")
+	if err != nil {
+		return nil, err
+	}
+	u := s.getUserByEmail(emailPasswordRecipeID, email)
+	if u == nil || u.passwordHash != hashString(password) {
+		return statusResponse("WRONG_CREDENTIALS_ERROR"), nil
+	}
+	return userResponse(s.userToJSON(u)), nil
+}
+
+func (s *Server) getEmailPasswordUser(req coreRequest) (map[string]interface{}, error) {
+	if userID, ok := req.getString("userId"); ok {
+		u := s.getUserByID(emailPasswordRecipeID, userID)
+		if u == nil {
+			return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+		}
+		return userResponse(s.userToJSON(u)), nil
+	}
+	email, err := req.getRequiredString("email")
+	if err != nil {
+		return nil, err
+	}
+	u := s.getUserByEmail(emailPasswordRecipeID, email)
+	if u == nil {
+		return statusResponse("UNKNOWN_EMAIL_ERROR"), nil
+	}
+	return userResponse(s.userToJSON(u)), nil
+}
+
+func (s *Server) updateEmailPasswordUser(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	u := s.getUserByID(emailPasswordRecipeID, userID)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	if email, ok := req.getString("email"); ok {
+		existing := s.getUserByEmail(emailPasswordRecipeID, email)
+		if existing != nil && existing != u {
+			return statusResponse("EMAIL_ALREADY_EXISTS_ERROR"), nil
+		}
+		u.email = &email
+	}
+	if password, ok := req.getString("password"); ok {
+		u.passwordHash = hashString(password)
+	}
+	return okResponse(), nil
+}
+
+func (s *Server) createResetPasswordToken(req coreRequest) (map[string]interface{}, error) {
+	userID, err := req.getRequiredString("userId")
+	if err != nil {
+		return nil, err
+	}
+	u := s.getUserByID(emailPasswordRecipeID, userID)
+	if u == nil {
+		return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+	}
+	token := generateRandomString(32)
+	s.store.resetPasswordTokens[token] = u.id
+	response := okResponse()
+	response["token"] = token
+	return response, nil
+}
+
+func (s *Server) resetPassword(req coreRequest) (map[string]interface{}, error) {
+	token, err := req.getRequiredString("token")
+	if err != nil {
+		return nil, err
+	}
+	newPassword, err := req.getRequiredString("newPassword")
+	if err != nil {
+		return nil, err
+	}
+	userID, ok := s.store.resetPasswordTokens[token]
+	u := s.store.users[userID]
+	if !ok || u == nil {
+		return statusResponse("RESET_PASSWORD_INVALID_TOKEN_ERROR"), nil
+	}
+	for t, id := range s.store.resetPasswordTokens {
+		if id == userID {
+			delete(s.store.resetPasswordTokens, t)
+		}
+	}
+	u.passwordHash = hashString(newPassword)
+	response := okResponse()
+	response["userId"] = s.userToJSON(u)["id"]
+	return response, nil
+}
+
+func (s *Server) thirdPartySignInUp(req coreRequest) (map[string]interface{}, error) {
+	thirdPartyID, err := req.getRequiredString("thirdPartyId")
+	if err != nil {
+		return nil, err
+	}
+	thirdPartyUserID, err := req.getRequiredString("thirdPartyUserId")
+	if err != nil {
+		return nil, err
+	}
+	email, ok := req.getObject("email")["id"].(string)
+	if !ok {
+		return nil, badRequestError{msg: "Field name 'email' is invalid in JSON input"}
+	}
+
+	createdNewUser := false
+	u := s.findUser(thirdPartyRecipeID, func(u *user) bool {
+		return u.thirdPartyID == thirdPartyID && u.thirdPartyUserID == thirdPartyUserID
+	})
+	if u == nil {
+		createdNewUser = true
+		u = &user{
+			recipeID:         thirdPartyRecipeID,
+			thirdPartyID:     thirdPartyID,
+			thirdPartyUserID: thirdPartyUserID,
+		}
+		s.addUser(u)
+	}
+	u.email = &email
+
+	response := userResponse(s.userToJSON(u))
+	response["createdNewUser"] = createdNewUser
+	return response, nil
+}
+
+func (s *Server) getThirdPartyUser(req coreRequest) (map[string]interface{}, error) {
+	if userID, ok := req.getString("userId"); ok {
+		u := s.getUserByID(thirdPartyRecipeID, userID)
+		if u == nil {
+			return statusResponse("UNKNOWN_USER_ID_ERROR"), nil
+		}
+		return userResponse(s.userToJSON(u)), nil
+	}
+	thirdPartyID, err := req.getRequiredString("thirdPartyId")
+	if err != nil {
+		return nil, err
+	}
+	thirdPartyUserID, err := req.getRequiredString("thirdPartyUserId")
+	if err != nil {
+		return nil, err
+	}
+	u := s.findUser(thirdPartyRecipeID, func(u *user) bool {
+		return u.thirdPartyID == thirdPartyID && u.thirdPartyUserID == thirdPartyUserID
+	})
+	if u == nil {
+		return statusResponse("UNKNOWN_THIRD_PARTY_USER_ERROR"), nil
+	}
+	return userResponse(s.userToJSON(u)), nil
+}
+
+func (s *Server) getThirdPartyUsersByEmail(req coreRequest) (map[string]interface{}, error) {
+	email, err := req.getRequiredString("email")
+	if err != nil {
+		return nil, err
+	}
+	users := []interface{}{}
+	for _, id := range s.store.userOrder {
+		u := s.store.users[id]
+		if u.recipeID == thirdPartyRecipeID && u.email != nil && *u.email == email {
+			users = append(users, s.userToJSON(u))
+		}
+	}
+	response := okResponse()
+	response["users"] = users
+	return response, nil
+}